@@ -1,6 +1,8 @@
 package router
 
 import (
+	"time"
+
 	"github.com/gorilla/mux"
 	"github.com/temuka-api-service/internal/handler"
 	"github.com/temuka-api-service/internal/publisher"
@@ -10,48 +12,96 @@ import (
 	database "github.com/temuka-api-service/util/database"
 	fileStorage "github.com/temuka-api-service/util/file_storage"
 	keyValueStore "github.com/temuka-api-service/util/key_value_store"
+	linkPreview "github.com/temuka-api-service/util/link_preview"
 	"github.com/temuka-api-service/util/queue"
+	"github.com/temuka-api-service/util/worker"
 )
 
-func Routes(db database.PostgresWrapper, redis keyValueStore.RedisWrapper, storage fileStorage.S3Wrapper, rmq queue.RabbitMQChannel) *mux.Router {
+// Routes wires the API and returns its router together with the background jobs of its
+// services, which the caller runs for as long as it serves the router.
+func Routes(db database.PostgresWrapper, redis keyValueStore.RedisWrapper, storage fileStorage.S3Wrapper, rmq queue.RabbitMQChannel) (*mux.Router, []worker.Job) {
 	router := mux.NewRouter()
 
 	// Init repositories
 	userRepo := repository.NewUserRepository(db)
+	mentionRepo := repository.NewMentionRepository(db)
 	postRepo := repository.NewPostRepository(db)
 	notificationRepo := repository.NewNotificationRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
 	communityRepo := repository.NewCommunityRepository(db)
+	communityRuleRepo := repository.NewCommunityRuleRepository(db)
+	communityFlairRepo := repository.NewCommunityFlairRepository(db)
+	communityAnnouncementRepo := repository.NewCommunityAnnouncementRepository(db)
 	moderatorRepo := repository.NewModeratorRepository(db)
 	reportRepo := repository.NewReportRepository(db)
 	universityRepo := repository.NewUniversityRepository(db)
 	reviewRepo := repository.NewReviewRepository(db)
 	locationRepo := repository.NewLocationRepository(db)
 	conversationRepo := repository.NewConversationRepository(db)
+	pollRepo := repository.NewPollRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	linkPreviewRepo := repository.NewLinkPreviewRepository(db)
+	postStatsRepo := repository.NewPostStatsRepository(db)
+	slugRepo := repository.NewSlugRepository(db)
+	communityEventRepo := repository.NewCommunityEventRepository(db)
+	communityAnalyticsRepo := repository.NewCommunityAnalyticsRepository(db)
+	communityWikiRepo := repository.NewCommunityWikiRepository(db)
 
 	// Init publishers
 	searchIndexPublisher := publisher.NewSearchIndexPublisher(rmq)
+	analyticsPublisher := publisher.NewAnalyticsPublisher(rmq)
 
 	// Init services
+	mentionService := service.NewMentionService(mentionRepo, userRepo, notificationRepo)
+	slugService := service.NewSlugService(slugRepo)
+	linkPreviewService := service.NewLinkPreviewService(linkPreviewRepo, linkPreview.NewFetcher(linkPreview.Config{}), redis)
 	userService := service.NewUserService(userRepo)
 	authService := service.NewAuthService(userRepo)
-	postService := service.NewPostService(postRepo, userRepo, commentRepo, notificationRepo, communityRepo, redis, searchIndexPublisher)
+	postService := service.NewPostService(postRepo, userRepo, commentRepo, notificationRepo, communityRepo, tagRepo, bookmarkRepo, moderatorRepo, communityFlairRepo, communityAnnouncementRepo, mentionService, linkPreviewService, redis, searchIndexPublisher)
 	notificationService := service.NewNotificationService(notificationRepo)
-	commentService := service.NewCommentService(commentRepo, postRepo, notificationRepo, reportRepo)
-	communityService := service.NewCommunityService(communityRepo)
+	commentService := service.NewCommentService(commentRepo, postRepo, notificationRepo, reportRepo, bookmarkRepo, moderatorRepo, userRepo, communityRepo, communityRuleRepo, mentionService, redis)
+	communityService := service.NewCommunityService(communityRepo, moderatorRepo, userRepo, universityRepo, notificationRepo, communityWikiRepo, slugService, redis, searchIndexPublisher)
+	communityRuleService := service.NewCommunityRuleService(communityRuleRepo, moderatorRepo, userRepo)
+	communityFlairService := service.NewCommunityFlairService(communityFlairRepo, moderatorRepo, userRepo)
+	communityEventService := service.NewCommunityEventService(communityEventRepo, communityRepo, moderatorRepo, userRepo, notificationRepo, redis)
+	communityAnalyticsService := service.NewCommunityAnalyticsService(communityAnalyticsRepo, communityRepo, moderatorRepo, userRepo, redis)
+	communityWikiService := service.NewCommunityWikiService(communityWikiRepo, communityRepo, moderatorRepo, userRepo, searchIndexPublisher)
 	moderatorService := service.NewModeratorService(moderatorRepo, notificationRepo)
-	reportService := service.NewReportService(reportRepo)
-	universityService := service.NewUniversityService(universityRepo, reviewRepo)
+	reportService := service.NewReportService(reportRepo, postRepo, commentRepo, communityRuleRepo)
+	universityService := service.NewUniversityService(universityRepo, reviewRepo, communityRepo, userRepo, slugService)
 	locationService := service.NewLocationService(locationRepo)
-	conversationService := service.NewConversationService(conversationRepo, userRepo)
+	conversationService := service.NewConversationService(conversationRepo, userRepo, mentionService)
 	fileService := service.NewFileService(storage)
+	pollService := service.NewPollService(pollRepo, postRepo)
+	tagService := service.NewTagService(tagRepo, redis)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo, postRepo, commentRepo, universityRepo)
+	postStatsService := service.NewPostStatsService(postStatsRepo, postRepo, moderatorRepo, userRepo, redis, analyticsPublisher)
+
+	// Init background workers
+	jobs := []worker.Job{
+		{Name: "scheduled_posts_publisher", Interval: time.Minute, Run: postService.PublishDuePosts},
+		{Name: "link_preview_fetcher", Interval: 30 * time.Second, Run: linkPreviewService.ProcessPendingPreviews},
+		{Name: "post_stats_flusher", Interval: time.Minute, Run: postStatsService.FlushStats},
+		{Name: "community_counts_reconciler", Interval: time.Hour, Run: communityService.ReconcileCounts},
+		{Name: "community_sanctions_expirer", Interval: time.Minute, Run: communityService.ExpireSanctions},
+		{Name: "community_announcements_deliverer", Interval: 10 * time.Second, Run: postService.DeliverAnnouncements},
+		{Name: "community_deletion_purger", Interval: time.Hour, Run: communityService.PurgeDeletedCommunities},
+		{Name: "community_event_reminders", Interval: time.Minute, Run: communityEventService.SendEventReminders},
+		{Name: "community_analytics_rollup", Interval: time.Hour, Run: communityAnalyticsService.RollupStats},
+	}
 
 	// Init controllers
 	authHandler := handler.NewAuthHandler(authService)
-	userHandler := handler.NewUserHandler(userService)
-	postHandler := handler.NewPostHandler(postService)
-	communityHandler := handler.NewCommunityHandler(communityService)
-	commentHandler := handler.NewCommentHandler(commentService)
+	userHandler := handler.NewUserHandler(userService, mentionService)
+	postHandler := handler.NewPostHandler(postService, postStatsService)
+	communityHandler := handler.NewCommunityHandler(communityService, postStatsService)
+	communityRuleHandler := handler.NewCommunityRuleHandler(communityRuleService)
+	communityFlairHandler := handler.NewCommunityFlairHandler(communityFlairService)
+	communityEventHandler := handler.NewCommunityEventHandler(communityEventService)
+	communityAnalyticsHandler := handler.NewCommunityAnalyticsHandler(communityAnalyticsService)
+	communityWikiHandler := handler.NewCommunityWikiHandler(communityWikiService)
+	commentHandler := handler.NewCommentHandler(commentService, postStatsService)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 	moderatorHandler := handler.NewModeratorHandler(moderatorService)
 	reportHandler := handler.NewReportHandler(reportService)
@@ -59,6 +109,9 @@ func Routes(db database.PostgresWrapper, redis keyValueStore.RedisWrapper, stora
 	locationHandler := handler.NewLocationHandler(locationService)
 	conversationHandler := handler.NewConversationHandler(conversationService)
 	fileUploadHandler := handler.NewFileHandler(fileService)
+	pollHandler := handler.NewPollHandler(pollService)
+	tagHandler := handler.NewTagHandler(tagService)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkService)
 
 	// Init routers
 	authRouter := router.PathPrefix("/api/auth").Subrouter()
@@ -73,6 +126,9 @@ func Routes(db database.PostgresWrapper, redis keyValueStore.RedisWrapper, stora
 	userRouter.HandleFunc("/search", userHandler.SearchUsers).Methods("GET")
 	userRouter.HandleFunc("/follow", userHandler.FollowUser).Methods("POST")
 	userRouter.HandleFunc("/followers", userHandler.GetFollowers).Methods("GET")
+	userRouter.HandleFunc("/block", userHandler.BlockUser).Methods("POST")
+	userRouter.HandleFunc("/block", userHandler.UnblockUser).Methods("DELETE")
+	userRouter.HandleFunc("/mentions/autocomplete", userHandler.AutocompleteMentions).Methods("GET")
 	userRouter.HandleFunc("/{id}", userHandler.GetUserDetail).Methods("GET")
 
 	postRouter := router.PathPrefix("/api/post").Subrouter()
@@ -82,27 +138,113 @@ func Routes(db database.PostgresWrapper, redis keyValueStore.RedisWrapper, stora
 	postRouter.HandleFunc("/timeline/{user_id}", postHandler.GetTimelinePosts).Methods("GET")
 	postRouter.HandleFunc("/user/{user_id}", postHandler.GetUserPosts).Methods("GET")
 	postRouter.HandleFunc("/like/{id}", postHandler.LikePost).Methods("PUT")
+	postRouter.HandleFunc("/unpublished/{user_id}", postHandler.GetUnpublishedPosts).Methods("GET")
+	postRouter.HandleFunc("/publish/{id}", postHandler.PublishPost).Methods("PUT")
+	postRouter.HandleFunc("/repost/{id}", postHandler.RepostPost).Methods("POST")
+	postRouter.HandleFunc("/{id}/stats", postHandler.GetPostStats).Methods("GET")
+	postRouter.HandleFunc("/{id}/lock", postHandler.LockPost).Methods("PUT")
+	postRouter.HandleFunc("/{id}/lock", postHandler.UnlockPost).Methods("DELETE")
+	postRouter.HandleFunc("/{id}/comment-settings", postHandler.UpdateCommentSettings).Methods("PUT")
+	postRouter.HandleFunc("/{id}/flair", postHandler.SetPostFlair).Methods("PUT")
+	postRouter.HandleFunc("/{id}/pin", postHandler.PinPost).Methods("PUT")
+	postRouter.HandleFunc("/{id}/pin", postHandler.UnpinPost).Methods("DELETE")
+	postRouter.HandleFunc("/{id}/moderation-log", postHandler.GetPostModerationLogs).Methods("GET")
 	postRouter.HandleFunc("/{id}", postHandler.DeletePost).Methods("DELETE")
 	postRouter.HandleFunc("/{id}", postHandler.UpdatePost).Methods("PUT")
 
+	pollRouter := router.PathPrefix("/api/poll").Subrouter()
+	pollRouter.Use(middleware.CheckAuth)
+	pollRouter.HandleFunc("/post/{post_id}", pollHandler.GetPostPoll).Methods("GET")
+	pollRouter.HandleFunc("/{id}/vote", pollHandler.Vote).Methods("POST")
+	pollRouter.HandleFunc("/{id}/voters", pollHandler.GetPollVoters).Methods("GET")
+
+	tagRouter := router.PathPrefix("/api/tag").Subrouter()
+	tagRouter.Use(middleware.CheckAuth)
+	tagRouter.HandleFunc("/trending", tagHandler.GetTrendingTags).Methods("GET")
+	tagRouter.HandleFunc("/{name}/posts", tagHandler.GetTagPosts).Methods("GET")
+
 	commentRouter := router.PathPrefix("/api/comment").Subrouter()
 	commentRouter.Use(middleware.CheckAuth)
 	commentRouter.HandleFunc("", commentHandler.AddComment).Methods("POST")
 	commentRouter.HandleFunc("/replies", commentHandler.ShowReplies).Methods("GET")
+	commentRouter.HandleFunc("/{commentId}", commentHandler.EditComment).Methods("PUT")
 	commentRouter.HandleFunc("/{commentId}", commentHandler.DeleteComment).Methods("DELETE")
+	commentRouter.HandleFunc("/{commentId}/history", commentHandler.GetCommentHistory).Methods("GET")
+	commentRouter.HandleFunc("/{commentId}/vote", commentHandler.VoteComment).Methods("POST")
 	commentRouter.HandleFunc("/show", commentHandler.ShowCommentsByPost).Methods("GET")
 
 	communityRouter := router.PathPrefix("/api/community").Subrouter()
 	communityRouter.Use(middleware.CheckAuth)
 	communityRouter.HandleFunc("", communityHandler.CreateCommunity).Methods("POST")
 	communityRouter.HandleFunc("", communityHandler.GetCommunities).Methods("GET")
+	communityRouter.HandleFunc("/trending", communityHandler.GetTrendingCommunities).Methods("GET")
+	communityRouter.HandleFunc("/categories", communityHandler.GetCommunityCategories).Methods("GET")
 	communityRouter.HandleFunc("/join/{community_id}", communityHandler.JoinCommunity).Methods("POST")
+	communityRouter.HandleFunc("/leave/{community_id}", communityHandler.LeaveCommunity).Methods("POST")
+	communityRouter.HandleFunc("/{id}/members", communityHandler.GetCommunityMembers).Methods("GET")
+	communityRouter.HandleFunc("/{id}/similar", communityHandler.GetSimilarCommunities).Methods("GET")
+	communityRouter.HandleFunc("/{id}/join-requests", communityHandler.GetJoinRequests).Methods("GET")
+	communityRouter.HandleFunc("/{id}/join-requests", communityHandler.CancelJoinRequest).Methods("DELETE")
+	communityRouter.HandleFunc("/{id}/join-requests/{request_id}/approve", communityHandler.ApproveJoinRequest).Methods("POST")
+	communityRouter.HandleFunc("/{id}/join-requests/{request_id}/reject", communityHandler.RejectJoinRequest).Methods("POST")
+	communityRouter.HandleFunc("/{id}/invites", communityHandler.CreateInvite).Methods("POST")
+	communityRouter.HandleFunc("/{id}/invites", communityHandler.GetInvites).Methods("GET")
+	communityRouter.HandleFunc("/{id}/invites/{invite_id}", communityHandler.RevokeInvite).Methods("DELETE")
+	communityRouter.HandleFunc("/{id}/invite-user", communityHandler.InviteUser).Methods("POST")
+	communityRouter.HandleFunc("/{id}/bans", communityHandler.BanMember).Methods("POST")
+	communityRouter.HandleFunc("/{id}/bans", communityHandler.UnbanMember).Methods("DELETE")
+	communityRouter.HandleFunc("/{id}/mutes", communityHandler.MuteMember).Methods("POST")
+	communityRouter.HandleFunc("/{id}/mutes", communityHandler.UnmuteMember).Methods("DELETE")
+	communityRouter.HandleFunc("/{id}/sanctions", communityHandler.GetCommunitySanctions).Methods("GET")
+	communityRouter.HandleFunc("/{id}/ownership-transfer", communityHandler.TransferOwnership).Methods("POST")
+	communityRouter.HandleFunc("/{id}/ownership-transfer", communityHandler.CancelOwnershipTransfer).Methods("DELETE")
+	communityRouter.HandleFunc("/{id}/ownership-transfer/accept", communityHandler.AcceptOwnershipTransfer).Methods("POST")
+	communityRouter.HandleFunc("/{id}/ownership-transfer/decline", communityHandler.DeclineOwnershipTransfer).Methods("POST")
+	communityRouter.HandleFunc("/{id}/archive", communityHandler.ArchiveCommunity).Methods("POST")
+	communityRouter.HandleFunc("/{id}/restore", communityHandler.RestoreCommunity).Methods("POST")
+	communityRouter.HandleFunc("/{id}/rules", communityRuleHandler.GetRules).Methods("GET")
+	communityRouter.HandleFunc("/{id}/rules", communityRuleHandler.CreateRule).Methods("POST")
+	communityRouter.HandleFunc("/{id}/rules/order", communityRuleHandler.ReorderRules).Methods("PUT")
+	communityRouter.HandleFunc("/{id}/rules/{rule_id}", communityRuleHandler.UpdateRule).Methods("PUT")
+	communityRouter.HandleFunc("/{id}/rules/{rule_id}", communityRuleHandler.DeleteRule).Methods("DELETE")
+	communityRouter.HandleFunc("/{id}/pins/order", postHandler.ReorderPinnedPosts).Methods("PUT")
+	communityRouter.HandleFunc("/{id}/announcements", postHandler.CreateAnnouncement).Methods("POST")
+	communityRouter.HandleFunc("/{id}/flairs", communityFlairHandler.GetFlairs).Methods("GET")
+	communityRouter.HandleFunc("/{id}/flairs", communityFlairHandler.CreateFlair).Methods("POST")
+	communityRouter.HandleFunc("/{id}/flairs/{flair_id}", communityFlairHandler.UpdateFlair).Methods("PUT")
+	communityRouter.HandleFunc("/{id}/flairs/{flair_id}", communityFlairHandler.DeleteFlair).Methods("DELETE")
+	communityRouter.HandleFunc("/{id}/events", communityEventHandler.GetCommunityEvents).Methods("GET")
+	communityRouter.HandleFunc("/{id}/events", communityEventHandler.CreateEvent).Methods("POST")
+	communityRouter.HandleFunc("/{id}/analytics", communityAnalyticsHandler.GetCommunityAnalytics).Methods("GET")
+	communityRouter.HandleFunc("/{id}/analytics/export", communityAnalyticsHandler.ExportCommunityAnalytics).Methods("GET")
+	communityRouter.HandleFunc("/{id}/wiki", communityWikiHandler.GetPages).Methods("GET")
+	communityRouter.HandleFunc("/{id}/wiki", communityWikiHandler.CreatePage).Methods("POST")
+	communityRouter.HandleFunc("/{id}/wiki/page", communityWikiHandler.GetPageByPath).Methods("GET")
+	communityRouter.HandleFunc("/wiki/{page_id}", communityWikiHandler.GetPage).Methods("GET")
+	communityRouter.HandleFunc("/wiki/{page_id}", communityWikiHandler.UpdatePage).Methods("PUT")
+	communityRouter.HandleFunc("/wiki/{page_id}", communityWikiHandler.DeletePage).Methods("DELETE")
+	communityRouter.HandleFunc("/wiki/{page_id}/permission", communityWikiHandler.SetEditPermission).Methods("PUT")
+	communityRouter.HandleFunc("/wiki/{page_id}/revert", communityWikiHandler.RevertPage).Methods("POST")
+	communityRouter.HandleFunc("/wiki/{page_id}/revisions", communityWikiHandler.GetRevisions).Methods("GET")
+	communityRouter.HandleFunc("/wiki/{page_id}/revisions/{revision}", communityWikiHandler.GetRevision).Methods("GET")
+	communityRouter.HandleFunc("/wiki/{page_id}/diff", communityWikiHandler.DiffRevisions).Methods("GET")
+	communityRouter.HandleFunc("/events/calendar-token", communityEventHandler.CreateCalendarToken).Methods("POST")
+	communityRouter.HandleFunc("/events/{event_id}", communityEventHandler.GetEvent).Methods("GET")
+	communityRouter.HandleFunc("/events/{event_id}", communityEventHandler.UpdateEvent).Methods("PUT")
+	communityRouter.HandleFunc("/events/{event_id}", communityEventHandler.CancelEvent).Methods("DELETE")
+	communityRouter.HandleFunc("/events/{event_id}/rsvp", communityEventHandler.RespondEvent).Methods("POST")
+	communityRouter.HandleFunc("/events/{event_id}/attendees", communityEventHandler.GetEventAttendees).Methods("GET")
 	communityRouter.HandleFunc("/post/{id}", communityHandler.GetCommunityPosts).Methods("GET")
 	communityRouter.HandleFunc("/user", communityHandler.GetUserJoinedCommunities).Methods("POST")
 	communityRouter.HandleFunc("/{slug}", communityHandler.GetCommunityDetail).Methods("GET")
 	communityRouter.HandleFunc("/{id}", communityHandler.DeleteCommunity).Methods("DELETE")
 	communityRouter.HandleFunc("/{id}", communityHandler.UpdateCommunity).Methods("PUT")
 
+	// Calendar apps cannot log in, so feeds are authorised by the calendar token instead.
+	calendarRouter := router.PathPrefix("/api/calendar").Subrouter()
+	calendarRouter.HandleFunc("/community/{id:[0-9]+}.ics", communityEventHandler.GetCommunityCalendar).Methods("GET")
+	calendarRouter.HandleFunc("/user/{token:[0-9a-f]+}.ics", communityEventHandler.GetUserCalendar).Methods("GET")
+
 	fileRouter := router.PathPrefix("/api/file").Subrouter()
 	fileRouter.Use(middleware.CheckAuth)
 	fileRouter.HandleFunc("", fileUploadHandler.Upload).Methods("POST")
@@ -124,11 +266,22 @@ func Routes(db database.PostgresWrapper, redis keyValueStore.RedisWrapper, stora
 	universityRouter.Use(middleware.CheckAuth)
 	universityRouter.HandleFunc("", universityHandler.AddUniversity).Methods("POST")
 	universityRouter.HandleFunc("/{id}", universityHandler.UpdateUniversity).Methods("PUT")
+	universityRouter.HandleFunc("/{id}/verified-users", universityHandler.VerifyUniversityMember).Methods("POST")
+	universityRouter.HandleFunc("/{id}/verified-users/{user_id}", universityHandler.RevokeUniversityVerification).Methods("DELETE")
 	universityRouter.HandleFunc("/{slug}", universityHandler.GetUniversityDetail).Methods("GET")
 	universityRouter.HandleFunc("", universityHandler.GetUniversities).Methods("GET")
 	universityRouter.HandleFunc("/review", universityHandler.AddReview).Methods("POST")
 	universityRouter.HandleFunc("/review/university_id", universityHandler.GetUniversityReviews).Methods("GET")
 
+	bookmarkRouter := router.PathPrefix("/api/bookmark").Subrouter()
+	bookmarkRouter.Use(middleware.CheckAuth)
+	bookmarkRouter.HandleFunc("/collection", bookmarkHandler.CreateCollection).Methods("POST")
+	bookmarkRouter.HandleFunc("/collection", bookmarkHandler.GetCollections).Methods("GET")
+	bookmarkRouter.HandleFunc("/collection/{id}", bookmarkHandler.DeleteCollection).Methods("DELETE")
+	bookmarkRouter.HandleFunc("/collection/{id}/item", bookmarkHandler.AddBookmark).Methods("POST")
+	bookmarkRouter.HandleFunc("/collection/{id}/item", bookmarkHandler.RemoveBookmark).Methods("DELETE")
+	bookmarkRouter.HandleFunc("/collection/{id}/item", bookmarkHandler.GetCollectionBookmarks).Methods("GET")
+
 	locationRouter := router.PathPrefix("/api/location").Subrouter()
 	locationRouter.Use(middleware.CheckAuth)
 	locationRouter.HandleFunc("", locationHandler.AddLocation).Methods("POST")
@@ -145,5 +298,5 @@ func Routes(db database.PostgresWrapper, redis keyValueStore.RedisWrapper, stora
 	conversationRouter.HandleFunc("/message/{conversation_id}", conversationHandler.RetrieveMessages).Methods("GET")
 	conversationRouter.HandleFunc("/all/{user_id}", conversationHandler.GetConversationsByUserID).Methods("GET")
 
-	return router
+	return router, jobs
 }
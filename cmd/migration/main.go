@@ -5,8 +5,11 @@ import (
 	"os"
 
 	"github.com/joho/godotenv"
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/model"
 	"github.com/temuka-api-service/util/database"
+	"github.com/temuka-api-service/util/markdown"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -26,13 +29,40 @@ func main() {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
 
+	if err := dedupeCommunityMembers(postgres.DB); err != nil {
+		log.Fatalf("Failed to dedupe community members: %v", err)
+	}
+
+	if err := dedupeSlugs(postgres.DB); err != nil {
+		log.Fatalf("Failed to dedupe slugs: %v", err)
+	}
+
 	if err := postgres.DB.AutoMigrate(
 		&model.User{},
 		&model.Community{},
 		&model.Post{},
+		&model.PostModerationLog{},
 		&model.Conversation{},
 		&model.Comment{},
+		&model.CommentVote{},
+		&model.CommentEdit{},
 		&model.CommunityMember{},
+		&model.CommunitySanction{},
+		&model.CommunityJoinRequest{},
+		&model.CommunityInvite{},
+		&model.CommunityRule{},
+		&model.CommunityFlair{},
+		&model.CommunityAnnouncement{},
+		&model.CommunityOwnershipTransfer{},
+		&model.CommunityTag{},
+		&model.SlugHistory{},
+		&model.CommunityEvent{},
+		&model.CommunityEventRSVP{},
+		&model.CalendarToken{},
+		&model.CommunityMembershipLog{},
+		&model.CommunityDailyStat{},
+		&model.CommunityWikiPage{},
+		&model.CommunityWikiRevision{},
 		&model.CommunityPost{},
 		&model.Moderator{},
 		&model.Participant{},
@@ -44,9 +74,228 @@ func main() {
 		&model.Review{},
 		&model.Major{},
 		&model.MajorReview{},
+		&model.Poll{},
+		&model.PollOption{},
+		&model.PollBallot{},
+		&model.PollVote{},
+		&model.Tag{},
+		&model.PostTag{},
+		&model.BookmarkCollection{},
+		&model.Bookmark{},
+		&model.Mention{},
+		&model.UserBlock{},
+		&model.LinkPreview{},
+		&model.PostDailyStat{},
 	); err != nil {
 		log.Fatalf("Failed to auto-migrate database: %v", err)
 	}
 
+	if err := renderMarkdownBodies(postgres.DB); err != nil {
+		log.Fatalf("Failed to render markdown bodies: %v", err)
+	}
+
+	if err := migrateCommunityRules(postgres.DB); err != nil {
+		log.Fatalf("Failed to migrate community rules: %v", err)
+	}
+
+	if err := backfillCommunityOwners(postgres.DB); err != nil {
+		log.Fatalf("Failed to backfill community owners: %v", err)
+	}
+
+	if err := backfillPostCommunities(postgres.DB); err != nil {
+		log.Fatalf("Failed to backfill post communities: %v", err)
+	}
+
+	if err := createCommunitySearchIndex(postgres.DB); err != nil {
+		log.Fatalf("Failed to create community search index: %v", err)
+	}
+
+	if err := createPlainRepostIndex(postgres.DB); err != nil {
+		log.Fatalf("Failed to create plain repost index: %v", err)
+	}
+
+	if err := backfillMembershipLogs(postgres.DB); err != nil {
+		log.Fatalf("Failed to backfill community membership logs: %v", err)
+	}
+
 	log.Println("Database migration completed successfully.")
 }
+
+// dedupeCommunityMembers keeps the oldest membership of every user in a community, so the
+// unique index on community_members can be created over rows written before it existed.
+func dedupeCommunityMembers(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.CommunityMember{}) {
+		return nil
+	}
+	return db.Exec(`
+		DELETE FROM community_members a
+		USING community_members b
+		WHERE a.community_id = b.community_id AND a.user_id = b.user_id AND a.id > b.id
+	`).Error
+}
+
+// dedupeSlugs gives every community and university that shares its slug with an older one,
+// or has none, a slug of its own, so the unique slug indexes can be created. The oldest
+// entity keeps a contested slug.
+func dedupeSlugs(db *gorm.DB) error {
+	tables := map[string]string{
+		"communities":  constant.SlugEntityCommunity,
+		"universities": constant.SlugEntityUniversity,
+	}
+	for table, entityType := range tables {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+		if err := db.Exec(`
+			UPDATE ` + table + ` t
+			SET slug = CASE
+				WHEN TRIM(COALESCE(t.slug, '')) = '' THEN '` + entityType + `-' || t.id
+				ELSE t.slug || '-' || t.id
+			END
+			WHERE TRIM(COALESCE(t.slug, '')) = ''
+			OR EXISTS (SELECT 1 FROM ` + table + ` o WHERE o.slug = t.slug AND o.id < t.id)
+		`).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCommunityRules turns the free-text rules of communities into a single structured
+// rule each. Moderators can split it up afterwards. Nothing reads communities.rules any more,
+// but the column is kept so a bad copy can be redone; drop it in a later migration once the
+// copied rules have been checked. Soft-deleted rules still count as copied, so rules that
+// moderators removed do not come back on the next run.
+func migrateCommunityRules(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&model.Community{}, "rules") {
+		return nil
+	}
+
+	return db.Exec(`
+		INSERT INTO community_rules (community_id, position, title, description, applies_to, created_at, updated_at)
+		SELECT c.id, 1, 'Community rules', c.rules, 'both', NOW(), NOW()
+		FROM communities c
+		WHERE TRIM(COALESCE(c.rules, '')) <> ''
+		AND NOT EXISTS (SELECT 1 FROM community_rules cr WHERE cr.community_id = c.id)
+	`).Error
+}
+
+// backfillCommunityOwners makes the longest-serving moderator the owner of every community
+// created before owners were recorded. Communities without moderators stay ownerless and
+// can only be managed by admins.
+func backfillCommunityOwners(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE communities c
+		SET owner_id = (
+			SELECT cm.user_id
+			FROM moderators m
+			JOIN community_members cm ON cm.id = m.communitymember_id
+			WHERE m.community_id = c.id
+			ORDER BY m.id
+			LIMIT 1
+		)
+		WHERE c.owner_id IS NULL
+	`).Error
+}
+
+// backfillPostCommunities copies the community of posts filed through community_posts before
+// posts carried it themselves. Counts, feeds and the plain repost index all read
+// posts.community_id, so this has to run before them. A post filed in several communities
+// keeps the first one.
+func backfillPostCommunities(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE posts p
+		SET community_id = cp.community_id
+		FROM (
+			SELECT DISTINCT ON (post_id) post_id::int AS post_id, community_id::int AS community_id
+			FROM community_posts
+			WHERE deleted_at IS NULL AND post_id ~ '^[0-9]+$' AND community_id ~ '^[0-9]+$'
+			ORDER BY post_id, id
+		) cp
+		WHERE p.id = cp.post_id AND COALESCE(p.community_id, 0) = 0
+	`).Error
+}
+
+// backfillMembershipLogs records a join for every membership that existed before joins were
+// logged, so analytics count those members on the day they joined. Leaves from that time
+// are lost. It only runs while the log is empty.
+func backfillMembershipLogs(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO community_membership_logs (community_id, user_id, action, created_at)
+		SELECT cm.community_id, cm.user_id, ?, cm.created_at
+		FROM community_members cm
+		WHERE cm.ban_only = FALSE AND cm.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM community_membership_logs)
+	`, constant.CommunityMembershipJoined).Error
+}
+
+// createCommunitySearchIndex indexes the full-text document community search matches
+// against. The expression has to stay in sync with communitySearchDocument.
+func createCommunitySearchIndex(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_communities_search
+		ON communities USING GIN (to_tsvector('simple', name || ' ' || COALESCE("desc", '')))
+	`).Error
+}
+
+// renderMarkdownBodies fills in the rendered HTML of posts and comments written before
+// bodies were stored as markdown. Rows that already have HTML are left alone.
+func renderMarkdownBodies(db *gorm.DB) error {
+	var posts []model.Post
+	err := db.Where(`"desc" <> '' AND (desc_html IS NULL OR desc_html = '')`).
+		FindInBatches(&posts, 500, func(tx *gorm.DB, batch int) error {
+			for _, p := range posts {
+				if err := tx.Model(&model.Post{}).Where("id = ?", p.ID).
+					Update("desc_html", markdown.Render(p.Description)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	var comments []model.Comment
+	return db.Where("content <> '' AND (content_html IS NULL OR content_html = '')").
+		FindInBatches(&comments, 500, func(tx *gorm.DB, batch int) error {
+			for _, c := range comments {
+				if err := tx.Model(&model.Comment{}).Where("id = ?", c.ID).
+					Update("content_html", markdown.Render(c.Content)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// createPlainRepostIndex allows one plain repost, one without commentary, per user, original
+// post and community, so concurrent reposts cannot both get in. Duplicates made before the
+// index existed are removed first, keeping the oldest, and the shares counts are corrected.
+func createPlainRepostIndex(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			WITH duplicates AS (
+				UPDATE posts p
+				SET deleted_at = NOW()
+				FROM posts o
+				WHERE p.repost_of_id IS NOT NULL AND p."desc" = '' AND p.deleted_at IS NULL
+				AND o.user_id = p.user_id AND o.repost_of_id = p.repost_of_id AND o.community_id = p.community_id
+				AND o."desc" = '' AND o.deleted_at IS NULL AND o.id < p.id
+				RETURNING p.repost_of_id
+			)
+			UPDATE posts
+			SET shares_count = GREATEST(shares_count - r.count, 0)
+			FROM (SELECT repost_of_id, COUNT(*) AS count FROM duplicates GROUP BY repost_of_id) r
+			WHERE posts.id = r.repost_of_id
+		`).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_plain_repost
+			ON posts (user_id, repost_of_id, community_id)
+			WHERE repost_of_id IS NOT NULL AND "desc" = '' AND deleted_at IS NULL
+		`).Error
+	})
+}
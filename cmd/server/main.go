@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	router "github.com/temuka-api-service/api"
@@ -12,8 +18,12 @@ import (
 	"github.com/temuka-api-service/util/file_storage"
 	"github.com/temuka-api-service/util/key_value_store"
 	"github.com/temuka-api-service/util/queue"
+	"github.com/temuka-api-service/util/worker"
 )
 
+// shutdownTimeout is how long in-flight requests get to finish once the server is asked to stop.
+const shutdownTimeout = 30 * time.Second
+
 func EnableCors(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:4000")
@@ -28,6 +38,9 @@ func EnableCors(next http.Handler) http.Handler {
 }
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	if err := godotenv.Load(".env"); err != nil {
 		log.Fatalf("Error loading .env file: %v", err)
 		os.Exit(1)
@@ -75,10 +88,35 @@ func main() {
 		log.Fatalf("Error creating message queue channel: %v", err)
 	}
 
-	router := router.Routes(*postgres, *redis, *storage, *mqChannel)
+	router, jobs := router.Routes(*postgres, *redis, *storage, *mqChannel)
 	protectedRoutes := EnableCors(router)
 
+	// Workers stop when ctx is cancelled; shutdown waits for the run in progress to return.
+	var workers sync.WaitGroup
+	for _, job := range jobs {
+		workers.Add(1)
+		go func(job worker.Job) {
+			defer workers.Done()
+			worker.RunPeriodically(ctx, job.Name, job.Interval, job.Run)
+		}(job)
+	}
+
 	http.Handle("/", protectedRoutes)
-	log.Println("Server is listening on port 3200")
-	log.Fatal(http.ListenAndServe("0.0.0.0:3200", nil))
+	server := &http.Server{Addr: "0.0.0.0:3200"}
+	go func() {
+		log.Println("Server is listening on port 3200")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error serving HTTP: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Server is shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+	workers.Wait()
 }
@@ -0,0 +1,7 @@
+package constant
+
+const (
+	BookmarkTargetPost       = "post"
+	BookmarkTargetComment    = "comment"
+	BookmarkTargetUniversity = "university"
+)
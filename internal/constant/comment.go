@@ -0,0 +1,39 @@
+package constant
+
+const (
+	CommentSortBest          = "best"
+	CommentSortTop           = "top"
+	CommentSortNew           = "new"
+	CommentSortControversial = "controversial"
+
+	CommentVoteUp   = 1
+	CommentVoteNone = 0
+	CommentVoteDown = -1
+
+	// Deleted comments are tombstoned by their author, removed ones by a moderator or
+	// an admin. Both keep their place in the thread so replies stay visible.
+	CommentStatusActive  = "active"
+	CommentStatusDeleted = "deleted"
+	CommentStatusRemoved = "removed"
+
+	CommentDeletedContent = "[deleted]"
+	CommentRemovedContent = "[removed]"
+
+	CommentEditActionEdit = "edit"
+)
+
+// Error codes returned by AddComment when a post does not accept the comment.
+const (
+	CommentErrorPostLocked    = "post_locked"
+	CommentErrorMembersOnly   = "comments_members_only"
+	CommentErrorFollowersOnly = "comments_followers_only"
+	CommentErrorSlowMode      = "slow_mode"
+)
+
+const (
+	CommentTreeDefaultDepth = 3
+	CommentTreeMaxDepth     = 10
+	// CommentTreeMaxNodes caps a single tree response; replies beyond it are reached
+	// through continuation cursors.
+	CommentTreeMaxNodes = 500
+)
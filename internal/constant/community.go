@@ -0,0 +1,123 @@
+package constant
+
+import "time"
+
+const (
+	// Anyone can read and join a public community. Restricted communities can be read by
+	// anyone, but joining needs an approved request or an invite and only members may post
+	// or comment. Private communities work the same way and hide their content from
+	// non-members.
+	CommunityVisibilityPublic     = "public"
+	CommunityVisibilityRestricted = "restricted"
+	CommunityVisibilityPrivate    = "private"
+
+	// An archived community stays readable but is read-only and hidden from discovery.
+	CommunityStatusActive   = "active"
+	CommunityStatusArchived = "archived"
+
+	CommunityMaxTags = 10
+
+	// A community's trending score counts every recent join CommunityTrendingJoinWeight
+	// times and every recent post once.
+	CommunityTrendingJoinWeight    = 2
+	DefaultCommunityTrendingWindow = "7d"
+
+	// CommunityDeletionRecoveryWindow is how long a deleted community can be restored before
+	// it is purged along with its members, moderators and posts.
+	CommunityDeletionRecoveryWindow = 30 * 24 * time.Hour
+
+	CommunityOwnershipTransferPending   = "pending"
+	CommunityOwnershipTransferAccepted  = "accepted"
+	CommunityOwnershipTransferDeclined  = "declined"
+	CommunityOwnershipTransferCancelled = "cancelled"
+	CommunityOwnershipTransferExpired   = "expired"
+	CommunityOwnershipTransferTTL       = 7 * 24 * time.Hour
+
+	NotificationTypeCommunityOwnershipOffer    = "community_ownership_offer"
+	NotificationTypeCommunityOwnershipAccepted = "community_ownership_accepted"
+	NotificationTypeCommunityOwnershipDeclined = "community_ownership_declined"
+
+	CommunityJoinRequestPending   = "pending"
+	CommunityJoinRequestApproved  = "approved"
+	CommunityJoinRequestRejected  = "rejected"
+	CommunityJoinRequestCancelled = "cancelled"
+
+	CommunityJoinStatusJoined    = "joined"
+	CommunityJoinStatusRequested = "requested"
+
+	CommunityInviteCodeLength = 10
+	// CommunityDirectInviteTTL is how long a direct invite sent as a notification stays valid.
+	CommunityDirectInviteTTL = 7 * 24 * time.Hour
+
+	NotificationTypeCommunityInvite       = "community_invite"
+	NotificationTypeCommunityJoinApproved = "community_join_approved"
+	NotificationTypeCommunityJoinRejected = "community_join_rejected"
+
+	// A community rule applies to posts, comments or both.
+	CommunityRuleAppliesToPosts    = "posts"
+	CommunityRuleAppliesToComments = "comments"
+	CommunityRuleAppliesToBoth     = "both"
+
+	CommunityMaxRules                 = 15
+	CommunityRuleTitleMaxLength       = 100
+	CommunityRuleDescriptionMaxLength = 1000
+	CommunityMaxFlairs                = 50
+	CommunityFlairNameMaxLength       = 64
+	CommunityFlairDefaultBackground   = "#edeff1"
+	CommunityFlairDefaultTextColor    = "#1a1a1b"
+
+	// CommunityMaxPinnedPosts is how many posts a community can pin to the top of its feed.
+	CommunityMaxPinnedPosts = 3
+
+	CommunityAnnouncementStatusPending   = "pending"
+	CommunityAnnouncementStatusCompleted = "completed"
+
+	NotificationTypeCommunityAnnouncement = "community_announcement"
+
+	CommunityRoleMember    = "member"
+	CommunityRoleModerator = "moderator"
+
+	CommunitySanctionBan    = "ban"
+	CommunitySanctionUnban  = "unban"
+	CommunitySanctionMute   = "mute"
+	CommunitySanctionUnmute = "unmute"
+
+	// CommunitySanctionExpiredReason is recorded when a timed ban or mute runs out.
+	CommunitySanctionExpiredReason = "expired"
+
+	NotificationTypeCommunityBan    = "community_ban"
+	NotificationTypeCommunityUnban  = "community_unban"
+	NotificationTypeCommunityMute   = "community_mute"
+	NotificationTypeCommunityUnmute = "community_unmute"
+)
+
+// Error codes returned when a banned or muted member tries to take part in a community.
+const (
+	CommunityErrorBanned = "community_banned"
+	CommunityErrorMuted  = "community_muted"
+	// CommunityErrorMembersOnly is returned when a non-member posts or comments in a
+	// restricted or private community.
+	CommunityErrorMembersOnly = "community_members_only"
+	// CommunityErrorArchived is returned when someone posts or comments in an archived community.
+	CommunityErrorArchived = "community_archived"
+)
+
+// CommunityCategories is the fixed set of categories a community can be filed under.
+var CommunityCategories = []string{
+	"academics",
+	"campus_life",
+	"careers",
+	"clubs",
+	"housing",
+	"sports",
+	"arts",
+	"technology",
+	"other",
+}
+
+// CommunityTrendingWindows are the sliding windows trending communities can be ranked over.
+var CommunityTrendingWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
@@ -0,0 +1,15 @@
+package constant
+
+const (
+	CommunityMembershipJoined = "joined"
+	CommunityMembershipLeft   = "left"
+
+	CommunityAnalyticsDefaultRangeDay = 30
+	CommunityAnalyticsMaxRangeDay     = 366
+
+	// CommunityAnalyticsBackfillDay is how far back the first rollup goes.
+	CommunityAnalyticsBackfillDay = 90
+	// CommunityAnalyticsRecomputeDay is how many finished days every rollup recomputes on top
+	// of the current one, so late writes such as buffered likes are picked up.
+	CommunityAnalyticsRecomputeDay = 1
+)
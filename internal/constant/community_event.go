@@ -0,0 +1,30 @@
+package constant
+
+import "time"
+
+const (
+	CommunityEventStatusScheduled = "scheduled"
+	CommunityEventStatusCancelled = "cancelled"
+
+	// Members answer an event with going, maybe or not going. Going to an event that is full
+	// puts them on its waitlist instead; they are moved up as places free.
+	CommunityEventRSVPGoing      = "going"
+	CommunityEventRSVPMaybe      = "maybe"
+	CommunityEventRSVPNotGoing   = "not_going"
+	CommunityEventRSVPWaitlisted = "waitlisted"
+
+	CommunityEventTitleMaxLength       = 200
+	CommunityEventDescriptionMaxLength = 5000
+	CommunityEventLocationMaxLength    = 300
+	CommunityEventMaxDuration          = 7 * 24 * time.Hour
+
+	// CommunityEventReminderLead is how long before an event starts its attendees are reminded.
+	CommunityEventReminderLead = time.Hour
+
+	// CommunityCalendarTokenLength is the length of the secret in a personal calendar feed URL.
+	CommunityCalendarTokenLength = 32
+
+	NotificationTypeCommunityEventReminder  = "community_event_reminder"
+	NotificationTypeCommunityEventCancelled = "community_event_cancelled"
+	NotificationTypeCommunityEventPromoted  = "community_event_promoted"
+)
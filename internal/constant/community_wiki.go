@@ -0,0 +1,20 @@
+package constant
+
+const (
+	// Wiki pages are edited either by moderators only or by every member of the community.
+	// Moderators can always edit and are the only ones who change the setting.
+	CommunityWikiEditModerators = "moderators"
+	CommunityWikiEditMembers    = "members"
+
+	CommunityWikiRevisionCreate = "create"
+	CommunityWikiRevisionEdit   = "edit"
+	CommunityWikiRevisionRevert = "revert"
+
+	// Pages live at slash separated paths such as "courses/cs101/notes". A nested page needs
+	// its parent page to exist.
+	CommunityWikiMaxPathDepth         = 5
+	CommunityWikiMaxPathLength        = 200
+	CommunityWikiTitleMaxLength       = 200
+	CommunityWikiContentMaxLength     = 100000
+	CommunityWikiEditSummaryMaxLength = 300
+)
@@ -17,4 +17,6 @@ const (
 	EnvRedisHost = "REDIS_HOST"
 	EnvRedisUser = "REDIS_USER"
 	EnvRedisPass = "REDIS_PASSWORD"
+
+	EnvTrustedProxies = "TRUSTED_PROXIES"
 )
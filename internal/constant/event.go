@@ -18,4 +18,5 @@ const (
 	EventEntityTypeCommunity  = "COMMUNITY"
 	EventEntityTypeUniversity = "UNIVERSITY"
 	EventEntityTypeMajor      = "MAJOR"
+	EventEntityTypeWikiPage   = "WIKI_PAGE"
 )
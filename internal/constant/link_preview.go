@@ -0,0 +1,12 @@
+package constant
+
+import "time"
+
+const (
+	LinkPreviewStatusPending = "pending"
+	LinkPreviewStatusReady   = "ready"
+	LinkPreviewStatusFailed  = "failed"
+
+	LinkPreviewMaxAttempts = 3
+	LinkPreviewCacheTTL    = 24 * time.Hour
+)
@@ -0,0 +1,9 @@
+package constant
+
+const (
+	MentionSourcePost    = "post"
+	MentionSourceComment = "comment"
+	MentionSourceMessage = "message"
+
+	NotificationTypeMention = "mention"
+)
@@ -0,0 +1,4 @@
+package constant
+
+// NotificationExcerptLength caps the plain-text excerpt of user content quoted in a notification.
+const NotificationExcerptLength = 100
@@ -0,0 +1,10 @@
+package constant
+
+const (
+	PollResultsVisibilityAlways     = "always"
+	PollResultsVisibilityAfterVote  = "after_vote"
+	PollResultsVisibilityAfterClose = "after_close"
+
+	PollMinOptions = 2
+	PollMaxOptions = 10
+)
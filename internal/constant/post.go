@@ -0,0 +1,34 @@
+package constant
+
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+
+	// Announcements are posts moderators send to every member of a community.
+	PostTypeRegular      = "post"
+	PostTypeAnnouncement = "announcement"
+
+	PostVisibilityPublic    = "public"
+	PostVisibilityFollowers = "followers"
+	PostVisibilityCommunity = "community"
+	// PostVisibilityUniversityVerified posts are only visible to users whose university
+	// membership an admin has verified.
+	PostVisibilityUniversityVerified = "university_verified"
+
+	NotificationTypeRepost = "repost"
+
+	// PostCommentRestriction decides who may comment on a post. The author, moderators of its
+	// community and admins may always comment unless the post is locked.
+	PostCommentRestrictionEveryone  = "everyone"
+	PostCommentRestrictionMembers   = "members"
+	PostCommentRestrictionFollowers = "followers"
+
+	PostModerationActionLock            = "lock"
+	PostModerationActionUnlock          = "unlock"
+	PostModerationActionCommentSettings = "comment_settings"
+	PostModerationActionPin             = "pin"
+	PostModerationActionUnpin           = "unpin"
+
+	PostSlowModeMaxSeconds = 24 * 60 * 60
+)
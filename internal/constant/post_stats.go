@@ -0,0 +1,23 @@
+package constant
+
+import "time"
+
+const (
+	PostStatViews       = "views"
+	PostStatImpressions = "impressions"
+	PostStatLikes       = "likes"
+	PostStatComments    = "comments"
+
+	PostStatsDateLayout  = "2006-01-02"
+	PostStatsMaxRangeDay = 365
+	PostStatsDailyTTL    = 48 * time.Hour
+
+	AnalyticsEventPostStats = "POST_STATS"
+)
+
+// Error codes returned by GetPostStats.
+const (
+	PostStatsErrorPostNotFound = "post_not_found"
+	PostStatsErrorForbidden    = "post_stats_forbidden"
+	PostStatsErrorInvalidRange = "post_stats_invalid_range"
+)
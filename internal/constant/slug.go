@@ -0,0 +1,30 @@
+package constant
+
+// Slugs are shared between the entity types below; each type has its own namespace.
+const (
+	SlugEntityCommunity  = "community"
+	SlugEntityUniversity = "university"
+
+	// SlugMaxSuffix is the highest numeric suffix tried before falling back to a random one.
+	SlugMaxSuffix = 50
+)
+
+// ReservedSlugs cannot be taken by any entity because they collide with fixed routes or
+// would be confusing as a name.
+var ReservedSlugs = map[string]bool{
+	"admin":      true,
+	"api":        true,
+	"categories": true,
+	"community":  true,
+	"edit":       true,
+	"join":       true,
+	"leave":      true,
+	"new":        true,
+	"post":       true,
+	"review":     true,
+	"search":     true,
+	"settings":   true,
+	"trending":   true,
+	"university": true,
+	"user":       true,
+}
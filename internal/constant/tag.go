@@ -0,0 +1,12 @@
+package constant
+
+import "time"
+
+// TrendingTagWindows are the sliding windows trending tags can be computed over.
+var TrendingTagWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+const DefaultTrendingTagWindow = "24h"
@@ -0,0 +1,9 @@
+package constant
+
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
+// UserErrorAdminOnly is returned when someone other than an admin attempts an admin action.
+const UserErrorAdminOnly = "admin_only"
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type CreateBookmarkCollectionRequest struct {
+	UserID int    `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+type DeleteBookmarkCollectionRequest struct {
+	UserID int `json:"user_id"`
+}
+
+type BookmarkItemRequest struct {
+	UserID     int    `json:"user_id"`
+	TargetType string `json:"target_type"`
+	TargetID   int    `json:"target_id"`
+}
+
+type BookmarkItemResponse struct {
+	ID         int         `json:"id"`
+	TargetType string      `json:"target_type"`
+	TargetID   int         `json:"target_id"`
+	Deleted    bool        `json:"deleted"`
+	Target     interface{} `json:"target,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
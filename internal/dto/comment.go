@@ -1,16 +1,88 @@
 package dto
 
+import "time"
+
 type AddCommentRequest struct {
 	PostID   int    `json:"post_id"`
-	UserID   int    `json:"user_id"`
+	UserID   int    `json:"-"`
 	ParentID *int   `json:"parent_id"`
 	Content  string `json:"content"`
 }
 
 type ShowCommentsRequest struct {
-	PostID int `json:"post_id"`
+	PostID int    `json:"post_id"`
+	UserID int    `json:"user_id"`
+	Sort   string `json:"sort"`
+	Page   int    `json:"page"`
+	Limit  int    `json:"limit"`
+	Depth  int    `json:"depth"`
 }
 
+// ShowRepliesRequest loads the replies of ParentID, or continues a listing from the
+// more_replies / next_cursor value of an earlier response when Cursor is set.
 type ShowRepliesRequest struct {
-	ParentID int `json:"parent_id"`
+	ParentID int    `json:"parent_id"`
+	UserID   int    `json:"user_id"`
+	Sort     string `json:"sort"`
+	Cursor   string `json:"cursor"`
+	Limit    int    `json:"limit"`
+	Depth    int    `json:"depth"`
+}
+
+type EditCommentRequest struct {
+	UserID  int    `json:"-"`
+	Content string `json:"content"`
+}
+
+// DeleteCommentRequest deletes a comment as its author, or removes it as a moderator or
+// admin, in which case Reason and the community rule RuleID cites are recorded with the removal.
+// UserID is the authenticated caller.
+type DeleteCommentRequest struct {
+	UserID int    `json:"-"`
+	Reason string `json:"reason"`
+	RuleID *int   `json:"rule_id"`
+}
+
+// VoteCommentRequest sets the vote of the authenticated UserID on a comment: 1 up, -1 down,
+// 0 retracts.
+type VoteCommentRequest struct {
+	UserID int `json:"-"`
+	Value  int `json:"value"`
+}
+
+type CommentVoteResponse struct {
+	CommentID int `json:"comment_id"`
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+	Score     int `json:"score"`
+	MyVote    int `json:"my_vote"`
+}
+
+// CommentResponse is a node of a comment tree. MoreReplies is set when not all of the
+// ReplyCount direct replies were loaded and is the cursor to fetch the rest. Deleted and
+// removed comments keep their place as tombstones without content or author.
+type CommentResponse struct {
+	ID          int               `json:"id"`
+	PostID      int               `json:"post_id"`
+	UserID      int               `json:"user_id"`
+	ParentID    *int              `json:"parent_id"`
+	Content     string            `json:"content"`
+	ContentHTML string            `json:"content_html"`
+	Status      string            `json:"status"`
+	EditedAt    *time.Time        `json:"edited_at"`
+	Upvotes     int               `json:"upvotes"`
+	Downvotes   int               `json:"downvotes"`
+	Score       int               `json:"score"`
+	MyVote      int               `json:"my_vote"`
+	ReplyCount  int               `json:"reply_count"`
+	Replies     []CommentResponse `json:"replies"`
+	MoreReplies string            `json:"more_replies,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+type CommentRepliesResponse struct {
+	Replies    []CommentResponse `json:"replies"`
+	Total      int64             `json:"total"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
@@ -1,24 +1,139 @@
 package dto
 
+import "time"
+
+// CreateCommunityRequest creates a community owned by UserID, optionally filed under a
+// Category, tagged and linked to a university.
 type CreateCommunityRequest struct {
-	Name         string `json:"name"`
-	Description  string `json:"description"`
-	LogoPicture  string `json:"logo_picture"`
-	CoverPicture string `json:"cover_picture"`
+	UserID       int      `json:"user_id"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	LogoPicture  string   `json:"logo_picture"`
+	CoverPicture string   `json:"cover_picture"`
+	Visibility   string   `json:"visibility"`
+	Category     string   `json:"category"`
+	Tags         []string `json:"tags"`
+	UniversityID *int     `json:"university_id"`
 }
 
+// UpdateCommunityRequest changes a community on behalf of UserID, who must moderate it to
+// change its Visibility, discovery settings or whether posts need a flair. Nil Tags and
+// UniversityID are left alone; an UniversityID of 0 unlinks the university. The slug always
+// follows the name and cannot be set directly.
 type UpdateCommunityRequest struct {
-	Name         string `json:"name"`
-	Slug         string `json:"slug"`
-	Description  string `json:"description"`
-	LogoPicture  string `json:"logo_picture"`
-	CoverPicture string `json:"cover_picture"`
+	UserID        int       `json:"user_id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	LogoPicture   string    `json:"logo_picture"`
+	CoverPicture  string    `json:"cover_picture"`
+	Visibility    string    `json:"visibility"`
+	FlairRequired *bool     `json:"flair_required"`
+	Category      string    `json:"category"`
+	Tags          *[]string `json:"tags"`
+	UniversityID  *int      `json:"university_id"`
+}
+
+// SearchCommunitiesRequest filters the community directory; empty fields are ignored.
+type SearchCommunitiesRequest struct {
+	Query        string `json:"q"`
+	Category     string `json:"category"`
+	Tag          string `json:"tag"`
+	UniversityID int    `json:"university_id"`
 }
 
+// JoinCommunityRequest joins the authenticated UserID to a community, either directly, with
+// an InviteCode, or by filing a join request carrying Message for restricted and private
+// communities.
 type JoinCommunityRequest struct {
-	UserID int `json:"user_id"`
+	UserID     int    `json:"-"`
+	Message    string `json:"message"`
+	InviteCode string `json:"invite_code"`
+}
+
+// ReviewJoinRequest approves or rejects a join request on behalf of the moderator UserID,
+// the authenticated caller.
+type ReviewJoinRequest struct {
+	UserID int    `json:"-"`
+	Reason string `json:"reason"`
+}
+
+type CommunityJoinRequestResponse struct {
+	ID             int       `json:"id"`
+	UserID         int       `json:"user_id"`
+	Username       string    `json:"username"`
+	Displayname    string    `json:"displayname"`
+	ProfilePicture string    `json:"profile_picture"`
+	Message        string    `json:"message"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CreateCommunityInviteRequest creates an invite link on behalf of the authenticated UserID.
+// A MaxUses of 0 allows unlimited uses and a nil ExpiresAt never expires.
+type CreateCommunityInviteRequest struct {
+	UserID    int        `json:"-"`
+	MaxUses   int        `json:"max_uses"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type InviteUserRequest struct {
+	UserID    int `json:"-"`
+	InviteeID int `json:"invitee_id"`
+}
+
+// TransferOwnershipRequest offers the ownership of a community to the member NewOwnerID on
+// behalf of its owner UserID, the authenticated caller.
+type TransferOwnershipRequest struct {
+	UserID     int `json:"-"`
+	NewOwnerID int `json:"new_owner_id"`
+}
+
+type DeleteCommunityResponse struct {
+	RecoverableUntil time.Time `json:"recoverable_until"`
+}
+
+type CommunityMemberResponse struct {
+	UserID         int       `json:"user_id"`
+	Username       string    `json:"username"`
+	Displayname    string    `json:"displayname"`
+	ProfilePicture string    `json:"profile_picture"`
+	Role           string    `json:"role"`
+	JoinedAt       time.Time `json:"joined_at"`
 }
 
 type GetUserJoinedCommunitiesRequest struct {
 	UserID int `json:"user_id"`
 }
+
+// CommunitySanctionRequest bans, mutes, unbans or unmutes UserID on behalf of ModeratorID,
+// the authenticated caller. A nil ExpiresAt makes a ban or mute permanent.
+type CommunitySanctionRequest struct {
+	ModeratorID int        `json:"-"`
+	UserID      int        `json:"user_id"`
+	Reason      string     `json:"reason"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// CommunityRuleRequest creates or changes a rule on behalf of the moderator UserID, the
+// authenticated caller. When changing a rule, fields left empty keep their current value.
+type CommunityRuleRequest struct {
+	UserID      int    `json:"-"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	AppliesTo   string `json:"applies_to"`
+}
+
+// ReorderCommunityRulesRequest lists every rule ID of a community in its new order.
+type ReorderCommunityRulesRequest struct {
+	UserID  int   `json:"-"`
+	RuleIDs []int `json:"rule_ids"`
+}
+
+// CommunityFlairRequest creates or changes a post flair on behalf of the moderator UserID,
+// the authenticated caller. Colours are hex strings such as "#1d9bf0"; empty fields keep
+// their current value.
+type CommunityFlairRequest struct {
+	UserID          int    `json:"-"`
+	Name            string `json:"name"`
+	BackgroundColor string `json:"background_color"`
+	TextColor       string `json:"text_color"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+// CommunityEventRequest creates or changes an event on behalf of UserID, the authenticated
+// caller. StartsAt and EndsAt are instants; TimeZone is the IANA zone the event is planned in
+// and defaults to UTC. Either OnlineURL or Location tells attendees where to go. A Capacity
+// of 0 means no limit.
+type CommunityEventRequest struct {
+	UserID      int       `json:"-"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	TimeZone    string    `json:"time_zone"`
+	OnlineURL   string    `json:"online_url"`
+	Location    string    `json:"location"`
+	Capacity    int       `json:"capacity"`
+}
+
+// CommunityEventRSVPRequest answers an event with going, maybe or not_going on behalf of
+// the authenticated UserID.
+type CommunityEventRSVPRequest struct {
+	UserID int    `json:"-"`
+	Status string `json:"status"`
+}
+
+type CommunityEventAttendeeResponse struct {
+	UserID         int       `json:"user_id"`
+	Username       string    `json:"username"`
+	Displayname    string    `json:"displayname"`
+	ProfilePicture string    `json:"profile_picture"`
+	Status         string    `json:"status"`
+	RespondedAt    time.Time `json:"responded_at"`
+}
+
+// CalendarTokenResponse carries the secret of the personal calendar feed of a user. Anyone
+// holding it can read the feed, so creating a new one revokes the old.
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+}
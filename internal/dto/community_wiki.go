@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/temuka-api-service/util/text"
+)
+
+// CreateWikiPageRequest creates a page at Path on behalf of UserID, the authenticated caller.
+// EditPermission is "members" by default; only moderators may set "moderators".
+type CreateWikiPageRequest struct {
+	UserID         int    `json:"-"`
+	Path           string `json:"path"`
+	Title          string `json:"title"`
+	Content        string `json:"content"`
+	Summary        string `json:"summary"`
+	EditPermission string `json:"edit_permission"`
+}
+
+// UpdateWikiPageRequest saves a new version of a page. BaseRevision is the revision the
+// edit started from; the save is refused when someone else saved in between.
+type UpdateWikiPageRequest struct {
+	UserID       int    `json:"-"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	Summary      string `json:"summary"`
+	BaseRevision int    `json:"base_revision"`
+}
+
+// RevertWikiPageRequest restores the content of Revision as a new revision.
+type RevertWikiPageRequest struct {
+	UserID   int    `json:"-"`
+	Revision int    `json:"revision"`
+	Summary  string `json:"summary"`
+}
+
+type WikiPagePermissionRequest struct {
+	UserID         int    `json:"-"`
+	EditPermission string `json:"edit_permission"`
+}
+
+type WikiRevisionResponse struct {
+	Revision       int       `json:"revision"`
+	Action         string    `json:"action"`
+	Title          string    `json:"title"`
+	Summary        string    `json:"summary"`
+	RevertedFrom   *int      `json:"reverted_from"`
+	EditorID       int       `json:"editor_id"`
+	Username       string    `json:"username"`
+	Displayname    string    `json:"displayname"`
+	ProfilePicture string    `json:"profile_picture"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// WikiDiffResponse is the line diff between two revisions of a page.
+type WikiDiffResponse struct {
+	From      int             `json:"from"`
+	To        int             `json:"to"`
+	FromTitle string          `json:"from_title"`
+	ToTitle   string          `json:"to_title"`
+	Added     int             `json:"added"`
+	Removed   int             `json:"removed"`
+	Lines     []text.DiffLine `json:"lines"`
+}
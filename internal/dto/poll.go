@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type CreatePollRequest struct {
+	Options           []string   `json:"options"`
+	MultipleChoice    bool       `json:"multiple_choice"`
+	Anonymous         bool       `json:"anonymous"`
+	ResultsVisibility string     `json:"results_visibility"`
+	ClosesAt          *time.Time `json:"closes_at"`
+}
+
+// VotePollRequest casts the vote of UserID, the authenticated caller, for OptionIDs.
+type VotePollRequest struct {
+	UserID    int   `json:"-"`
+	OptionIDs []int `json:"option_ids"`
+}
+
+type PollOptionResponse struct {
+	ID         int    `json:"id"`
+	Text       string `json:"text"`
+	Position   int    `json:"position"`
+	VotesCount *int   `json:"votes_count,omitempty"`
+}
+
+type PollResponse struct {
+	ID                int                  `json:"id"`
+	PostID            int                  `json:"post_id"`
+	MultipleChoice    bool                 `json:"multiple_choice"`
+	Anonymous         bool                 `json:"anonymous"`
+	ResultsVisibility string               `json:"results_visibility"`
+	ClosesAt          *time.Time           `json:"closes_at"`
+	Closed            bool                 `json:"closed"`
+	ResultsHidden     bool                 `json:"results_hidden"`
+	VotersCount       *int                 `json:"voters_count,omitempty"`
+	MyOptionIDs       []int                `json:"my_option_ids"`
+	Options           []PollOptionResponse `json:"options"`
+}
+
+type PollVotersResponse struct {
+	OptionID int   `json:"option_id"`
+	UserIDs  []int `json:"user_ids"`
+}
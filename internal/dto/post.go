@@ -1,20 +1,34 @@
 package dto
 
+import "time"
+
 type CreatePostRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	UserID      int    `json:"user_id"`
-	CommunityID int    `json:"community_id"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	UserID      int                `json:"user_id"`
+	CommunityID int                `json:"community_id"`
+	FlairID     *int               `json:"flair_id"`
+	Status      string             `json:"status"`
+	Visibility  string             `json:"visibility"`
+	ScheduledAt *time.Time         `json:"scheduled_at"`
+	Poll        *CreatePollRequest `json:"poll"`
 }
 
+// UpdatePostRequest changes a post on behalf of UserID, the authenticated caller, who must
+// be its author or moderate its community.
 type UpdatePostRequest struct {
-	UserID      int    `json:"user_id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	UserID      int        `json:"-"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Visibility  string     `json:"visibility"`
+	ScheduledAt *time.Time `json:"scheduled_at"`
 }
 
-type LikePostRequest struct {
-	UserID int `json:"user_id"`
+type RepostRequest struct {
+	UserID       int    `json:"user_id"`
+	Commentary   string `json:"commentary"`
+	CommunityIDs []int  `json:"community_ids"`
 }
 
 type PostCreatedEventData struct {
@@ -31,3 +45,50 @@ type PostLikedEventData struct {
 	LikedByUserID int `json:"liked_by_user_id"`
 	CommunityID   int `json:"community_id"`
 }
+
+// SetPostFlairRequest sets the flair of a community post as the authenticated UserID; a nil
+// FlairID clears it.
+type SetPostFlairRequest struct {
+	UserID  int  `json:"-"`
+	FlairID *int `json:"flair_id"`
+}
+
+// LockPostRequest locks or unlocks a post as the authenticated UserID; Reason is kept in the
+// post's moderation log.
+type LockPostRequest struct {
+	UserID int    `json:"-"`
+	Reason string `json:"reason"`
+}
+
+// CommentSettingsRequest changes who may comment on a post and its slow mode interval on
+// behalf of the authenticated UserID. Fields left empty keep their current value; a slow
+// mode of 0 turns it off.
+type CommentSettingsRequest struct {
+	UserID             int    `json:"-"`
+	CommentRestriction string `json:"comment_restriction"`
+	SlowModeSeconds    *int   `json:"slow_mode_seconds"`
+	Reason             string `json:"reason"`
+}
+
+// PinPostRequest pins or unpins a community post as the authenticated UserID; Reason is kept
+// in the post's moderation log.
+type PinPostRequest struct {
+	UserID int    `json:"-"`
+	Reason string `json:"reason"`
+}
+
+// ReorderPinnedPostsRequest lists every pinned post ID of a community in its new order.
+type ReorderPinnedPostsRequest struct {
+	UserID  int   `json:"-"`
+	PostIDs []int `json:"post_ids"`
+}
+
+// CreateAnnouncementRequest posts an announcement by the authenticated UserID that notifies
+// every member of the community. A ScheduledAt in the future publishes and delivers it then.
+type CreateAnnouncementRequest struct {
+	UserID      int        `json:"-"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	FlairID     *int       `json:"flair_id"`
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
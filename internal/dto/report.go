@@ -4,4 +4,5 @@ type CreateReportRequest struct {
 	CommentID int    `json:"comment_id"`
 	PostID    int    `json:"post_id"`
 	Reason    string `json:"reason"`
+	RuleID    *int   `json:"rule_id"`
 }
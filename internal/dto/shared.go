@@ -4,3 +4,11 @@ type MessageResponse struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
+
+type PaginatedResponse struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+	Page    int         `json:"page"`
+	Limit   int         `json:"limit"`
+	Total   int64       `json:"total"`
+}
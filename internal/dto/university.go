@@ -1,5 +1,21 @@
 package dto
 
+import "github.com/temuka-api-service/internal/model"
+
+// UniversityDetailResponse is a university together with one page of the communities
+// linked to it.
+type UniversityDetailResponse struct {
+	*model.University
+	Communities CommunityPage `json:"communities"`
+}
+
+type CommunityPage struct {
+	Data  []model.Community `json:"data"`
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+	Total int64             `json:"total"`
+}
+
 type AddUniversityRequest struct {
 	Name          string `json:"name"`
 	Summary       string `json:"summary"`
@@ -28,6 +44,10 @@ type UpdateUniversityRequest struct {
 	Accreditation string `json:"accreditation"`
 }
 
+type VerifyUniversityMemberRequest struct {
+	UserID int `json:"user_id"`
+}
+
 type AddReviewRequest struct {
 	UserID       int    `json:"user_id"`
 	UniversityID int    `json:"university_id"`
@@ -30,3 +30,8 @@ type FollowUserDTO struct {
 type GetFollowersDTO struct {
 	UserID int `json:"user_id"`
 }
+
+type BlockUserDTO struct {
+	TargetID      int `json:"target_id"`
+	CurrentUserID int `json:"currentuser_id"`
+}
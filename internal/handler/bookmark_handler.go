@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/service"
+	rest "github.com/temuka-api-service/util/rest"
+)
+
+type BookmarkHandler interface {
+	CreateCollection(w http.ResponseWriter, r *http.Request)
+	GetCollections(w http.ResponseWriter, r *http.Request)
+	DeleteCollection(w http.ResponseWriter, r *http.Request)
+	AddBookmark(w http.ResponseWriter, r *http.Request)
+	RemoveBookmark(w http.ResponseWriter, r *http.Request)
+	GetCollectionBookmarks(w http.ResponseWriter, r *http.Request)
+}
+
+type BookmarkHandlerImpl struct {
+	BookmarkService service.BookmarkService
+}
+
+func NewBookmarkHandler(bookmarkService service.BookmarkService) BookmarkHandler {
+	return &BookmarkHandlerImpl{
+		BookmarkService: bookmarkService,
+	}
+}
+
+func (h *BookmarkHandlerImpl) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateBookmarkCollectionRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	collection, err := h.BookmarkService.CreateCollection(r.Context(), req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Bookmark collection has been created",
+		"data":    collection,
+	})
+}
+
+func (h *BookmarkHandlerImpl) GetCollections(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	collections, err := h.BookmarkService.GetCollections(r.Context(), userID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Bookmark collections have been retrieved",
+		"data":    collections,
+	})
+}
+
+func (h *BookmarkHandlerImpl) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid collection ID"})
+		return
+	}
+
+	var req dto.DeleteBookmarkCollectionRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.BookmarkService.DeleteCollection(r.Context(), id, req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Bookmark collection has been deleted"})
+}
+
+func (h *BookmarkHandlerImpl) AddBookmark(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid collection ID"})
+		return
+	}
+
+	var req dto.BookmarkItemRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	bookmark, err := h.BookmarkService.AddBookmark(r.Context(), id, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Bookmark has been added",
+		"data":    bookmark,
+	})
+}
+
+func (h *BookmarkHandlerImpl) RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid collection ID"})
+		return
+	}
+
+	var req dto.BookmarkItemRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.BookmarkService.RemoveBookmark(r.Context(), id, req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Bookmark has been removed"})
+}
+
+func (h *BookmarkHandlerImpl) GetCollectionBookmarks(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid collection ID"})
+		return
+	}
+
+	userID := requestUserID(r)
+
+	page, limit := parsePagination(r)
+
+	items, total, err := h.BookmarkService.GetCollectionBookmarks(r.Context(), id, userID, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Bookmarks have been retrieved",
+		Data:    items,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
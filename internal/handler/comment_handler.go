@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/dto"
 	"github.com/temuka-api-service/internal/service"
 	rest "github.com/temuka-api-service/util/rest"
@@ -13,17 +14,22 @@ import (
 type CommentHandler interface {
 	AddComment(w http.ResponseWriter, r *http.Request)
 	ShowCommentsByPost(w http.ResponseWriter, r *http.Request)
+	EditComment(w http.ResponseWriter, r *http.Request)
 	DeleteComment(w http.ResponseWriter, r *http.Request)
+	GetCommentHistory(w http.ResponseWriter, r *http.Request)
 	ShowReplies(w http.ResponseWriter, r *http.Request)
+	VoteComment(w http.ResponseWriter, r *http.Request)
 }
 
 type CommentHandlerImpl struct {
-	CommentService service.CommentService
+	CommentService   service.CommentService
+	PostStatsService service.PostStatsService
 }
 
-func NewCommentHandler(commentService service.CommentService) CommentHandler {
+func NewCommentHandler(commentService service.CommentService, postStatsService service.PostStatsService) CommentHandler {
 	return &CommentHandlerImpl{
-		CommentService: commentService,
+		CommentService:   commentService,
+		PostStatsService: postStatsService,
 	}
 }
 
@@ -34,12 +40,15 @@ func (h *CommentHandlerImpl) AddComment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	req.UserID = requestUserID(r)
 	comment, err := h.CommentService.AddComment(r.Context(), req)
 	if err != nil {
-		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		writeError(w, err, http.StatusInternalServerError)
 		return
 	}
 
+	h.PostStatsService.RecordEngagement(r.Context(), comment.PostID, constant.PostStatComments)
+
 	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
 		"message": "Comment has been added",
 		"data":    comment,
@@ -47,21 +56,63 @@ func (h *CommentHandlerImpl) AddComment(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *CommentHandlerImpl) ShowCommentsByPost(w http.ResponseWriter, r *http.Request) {
-	var req dto.ShowCommentsRequest
+	query := r.URL.Query()
+
+	postID, err := strconv.Atoi(query.Get("post_id"))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+
+	page, limit := parsePagination(r)
+	userID := requestUserID(r)
+	depth, _ := strconv.Atoi(query.Get("depth"))
+
+	comments, total, err := h.CommentService.ShowCommentsByPost(r.Context(), dto.ShowCommentsRequest{
+		PostID: postID,
+		UserID: userID,
+		Sort:   query.Get("sort"),
+		Page:   page,
+		Limit:  limit,
+		Depth:  depth,
+	})
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Comments have been retrieved",
+		Data:    comments,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommentHandlerImpl) EditComment(w http.ResponseWriter, r *http.Request) {
+	commentID, err := strconv.Atoi(mux.Vars(r)["commentId"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	var req dto.EditCommentRequest
 	if err := rest.ReadRequest(r, &req); err != nil {
 		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 		return
 	}
 
-	comments, err := h.CommentService.ShowCommentsByPost(r.Context(), req)
+	req.UserID = requestUserID(r)
+	comment, err := h.CommentService.EditComment(r.Context(), commentID, req)
 	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
 
 	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
-		"message": "Comments have been retrieved",
-		"data":    comments,
+		"message": "Comment has been edited",
+		"data":    comment,
 	})
 }
 
@@ -75,7 +126,14 @@ func (h *CommentHandlerImpl) DeleteComment(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := h.CommentService.DeleteComment(r.Context(), commentID); err != nil {
+	var req dto.DeleteCommentRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	if err := h.CommentService.DeleteComment(r.Context(), commentID, req); err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
@@ -83,14 +141,48 @@ func (h *CommentHandlerImpl) DeleteComment(w http.ResponseWriter, r *http.Reques
 	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Comment has been deleted"})
 }
 
+func (h *CommentHandlerImpl) GetCommentHistory(w http.ResponseWriter, r *http.Request) {
+	commentID, err := strconv.Atoi(mux.Vars(r)["commentId"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+	viewerID := requestUserID(r)
+
+	edits, err := h.CommentService.GetCommentHistory(r.Context(), commentID, viewerID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Comment history has been retrieved",
+		"data":    edits,
+	})
+}
+
 func (h *CommentHandlerImpl) ShowReplies(w http.ResponseWriter, r *http.Request) {
-	var req dto.ShowRepliesRequest
-	if err := rest.ReadRequest(r, &req); err != nil {
-		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	query := r.URL.Query()
+
+	parentID, _ := strconv.Atoi(query.Get("parent_id"))
+	cursor := query.Get("cursor")
+	if parentID == 0 && cursor == "" {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "parent_id or cursor is required"})
 		return
 	}
 
-	replies, err := h.CommentService.ShowReplies(r.Context(), req)
+	_, limit := parsePagination(r)
+	userID := requestUserID(r)
+	depth, _ := strconv.Atoi(query.Get("depth"))
+
+	replies, err := h.CommentService.ShowReplies(r.Context(), dto.ShowRepliesRequest{
+		ParentID: parentID,
+		UserID:   userID,
+		Sort:     query.Get("sort"),
+		Cursor:   cursor,
+		Limit:    limit,
+		Depth:    depth,
+	})
 	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
@@ -101,3 +193,29 @@ func (h *CommentHandlerImpl) ShowReplies(w http.ResponseWriter, r *http.Request)
 		"data":    replies,
 	})
 }
+
+func (h *CommentHandlerImpl) VoteComment(w http.ResponseWriter, r *http.Request) {
+	commentID, err := strconv.Atoi(mux.Vars(r)["commentId"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	var req dto.VoteCommentRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	comment, err := h.CommentService.VoteComment(r.Context(), commentID, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Vote has been recorded",
+		"data":    comment,
+	})
+}
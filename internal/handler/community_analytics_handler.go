@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/service"
+	rest "github.com/temuka-api-service/util/rest"
+)
+
+type CommunityAnalyticsHandler interface {
+	GetCommunityAnalytics(w http.ResponseWriter, r *http.Request)
+	ExportCommunityAnalytics(w http.ResponseWriter, r *http.Request)
+}
+
+type CommunityAnalyticsHandlerImpl struct {
+	CommunityAnalyticsService service.CommunityAnalyticsService
+}
+
+func NewCommunityAnalyticsHandler(analyticsService service.CommunityAnalyticsService) CommunityAnalyticsHandler {
+	return &CommunityAnalyticsHandlerImpl{
+		CommunityAnalyticsService: analyticsService,
+	}
+}
+
+func (h *CommunityAnalyticsHandlerImpl) GetCommunityAnalytics(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+	query := r.URL.Query()
+
+	userID := requestUserID(r)
+
+	analytics, err := h.CommunityAnalyticsService.GetCommunityAnalytics(r.Context(), id, userID, query.Get("from"), query.Get("to"))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community analytics have been retrieved",
+		Data:    analytics,
+	})
+}
+
+// ExportCommunityAnalytics serves the daily series of GetCommunityAnalytics as a CSV download.
+func (h *CommunityAnalyticsHandlerImpl) ExportCommunityAnalytics(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+	query := r.URL.Query()
+
+	userID := requestUserID(r)
+
+	export, err := h.CommunityAnalyticsService.ExportCommunityAnalytics(r.Context(), id, userID, query.Get("from"), query.Get("to"))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="community-%d-analytics.csv"`, id))
+	w.WriteHeader(http.StatusOK)
+	w.Write(export)
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/service"
+	"github.com/temuka-api-service/util/ical"
+	rest "github.com/temuka-api-service/util/rest"
+)
+
+type CommunityEventHandler interface {
+	CreateEvent(w http.ResponseWriter, r *http.Request)
+	GetCommunityEvents(w http.ResponseWriter, r *http.Request)
+	GetEvent(w http.ResponseWriter, r *http.Request)
+	UpdateEvent(w http.ResponseWriter, r *http.Request)
+	CancelEvent(w http.ResponseWriter, r *http.Request)
+	RespondEvent(w http.ResponseWriter, r *http.Request)
+	GetEventAttendees(w http.ResponseWriter, r *http.Request)
+	CreateCalendarToken(w http.ResponseWriter, r *http.Request)
+	GetCommunityCalendar(w http.ResponseWriter, r *http.Request)
+	GetUserCalendar(w http.ResponseWriter, r *http.Request)
+}
+
+type CommunityEventHandlerImpl struct {
+	CommunityEventService service.CommunityEventService
+}
+
+func NewCommunityEventHandler(eventService service.CommunityEventService) CommunityEventHandler {
+	return &CommunityEventHandlerImpl{
+		CommunityEventService: eventService,
+	}
+}
+
+func (h *CommunityEventHandlerImpl) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	var req dto.CommunityEventRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	event, err := h.CommunityEventService.CreateEvent(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusCreated, dto.MessageResponse{
+		Message: "Event has been created",
+		Data:    event,
+	})
+}
+
+func (h *CommunityEventHandlerImpl) GetCommunityEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	from, ok := parseTimeQuery(r, "from")
+	if !ok {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid from, expected RFC 3339"})
+		return
+	}
+	to, ok := parseTimeQuery(r, "to")
+	if !ok {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid to, expected RFC 3339"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+	page, limit := parsePagination(r)
+
+	events, total, err := h.CommunityEventService.GetCommunityEvents(r.Context(), id, viewerID, from, to, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Events have been retrieved",
+		Data:    events,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommunityEventHandlerImpl) GetEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["event_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+
+	event, err := h.CommunityEventService.GetEvent(r.Context(), id, viewerID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Event has been retrieved",
+		Data:    event,
+	})
+}
+
+func (h *CommunityEventHandlerImpl) UpdateEvent(w http.ResponseWriter, r *http.Request) {
+	var req dto.CommunityEventRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["event_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	event, err := h.CommunityEventService.UpdateEvent(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Event has been updated",
+		Data:    event,
+	})
+}
+
+func (h *CommunityEventHandlerImpl) CancelEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["event_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+		return
+	}
+
+	if err := h.CommunityEventService.CancelEvent(r.Context(), id, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Event has been cancelled",
+	})
+}
+
+func (h *CommunityEventHandlerImpl) RespondEvent(w http.ResponseWriter, r *http.Request) {
+	var req dto.CommunityEventRSVPRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["event_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	rsvp, err := h.CommunityEventService.RespondEvent(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "RSVP has been saved",
+		Data:    rsvp,
+	})
+}
+
+func (h *CommunityEventHandlerImpl) GetEventAttendees(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["event_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+	page, limit := parsePagination(r)
+
+	attendees, total, err := h.CommunityEventService.GetEventAttendees(r.Context(), id, viewerID, r.URL.Query().Get("status"), page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Attendees have been retrieved",
+		Data:    attendees,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommunityEventHandlerImpl) CreateCalendarToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.CommunityEventService.CreateCalendarToken(r.Context(), requestUserID(r))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusCreated, dto.MessageResponse{
+		Message: "Calendar token has been created",
+		Data:    token,
+	})
+}
+
+// GetCommunityCalendar serves the iCalendar feed of a community. Private communities need
+// the calendar token of a member in the token query parameter.
+func (h *CommunityEventHandlerImpl) GetCommunityCalendar(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	calendar, err := h.CommunityEventService.GetCommunityCalendar(r.Context(), id, r.URL.Query().Get("token"))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeCalendar(w, calendar)
+}
+
+func (h *CommunityEventHandlerImpl) GetUserCalendar(w http.ResponseWriter, r *http.Request) {
+	calendar, err := h.CommunityEventService.GetUserCalendar(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeCalendar(w, calendar)
+}
+
+func writeCalendar(w http.ResponseWriter, calendar []byte) {
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(calendar)
+}
+
+// parseTimeQuery reads an RFC 3339 time from the query string. A missing parameter yields
+// the zero time.
+func parseTimeQuery(r *http.Request, name string) (time.Time, bool) {
+	value := strings.TrimSpace(r.URL.Query().Get(name))
+	if value == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/service"
+	rest "github.com/temuka-api-service/util/rest"
+)
+
+type CommunityFlairHandler interface {
+	GetFlairs(w http.ResponseWriter, r *http.Request)
+	CreateFlair(w http.ResponseWriter, r *http.Request)
+	UpdateFlair(w http.ResponseWriter, r *http.Request)
+	DeleteFlair(w http.ResponseWriter, r *http.Request)
+}
+
+type CommunityFlairHandlerImpl struct {
+	CommunityFlairService service.CommunityFlairService
+}
+
+func NewCommunityFlairHandler(flairService service.CommunityFlairService) CommunityFlairHandler {
+	return &CommunityFlairHandlerImpl{
+		CommunityFlairService: flairService,
+	}
+}
+
+func (h *CommunityFlairHandlerImpl) GetFlairs(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	flairs, err := h.CommunityFlairService.GetFlairs(r.Context(), id)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community flairs have been retrieved",
+		Data:    flairs,
+	})
+}
+
+func (h *CommunityFlairHandlerImpl) CreateFlair(w http.ResponseWriter, r *http.Request) {
+	var req dto.CommunityFlairRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	flair, err := h.CommunityFlairService.CreateFlair(r.Context(), id, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusCreated, dto.MessageResponse{
+		Message: "Community flair has been created",
+		Data:    flair,
+	})
+}
+
+func (h *CommunityFlairHandlerImpl) UpdateFlair(w http.ResponseWriter, r *http.Request) {
+	var req dto.CommunityFlairRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	flairID, err := strconv.Atoi(mux.Vars(r)["flair_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid flair ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	flair, err := h.CommunityFlairService.UpdateFlair(r.Context(), id, flairID, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community flair has been updated",
+		Data:    flair,
+	})
+}
+
+func (h *CommunityFlairHandlerImpl) DeleteFlair(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	flairID, err := strconv.Atoi(mux.Vars(r)["flair_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid flair ID"})
+		return
+	}
+
+	if err := h.CommunityFlairService.DeleteFlair(r.Context(), id, flairID, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Community flair has been deleted"})
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/dto"
 	"github.com/temuka-api-service/internal/service"
 	rest "github.com/temuka-api-service/util/rest"
@@ -13,9 +15,33 @@ import (
 type CommunityHandler interface {
 	CreateCommunity(w http.ResponseWriter, r *http.Request)
 	GetCommunities(w http.ResponseWriter, r *http.Request)
+	GetTrendingCommunities(w http.ResponseWriter, r *http.Request)
+	GetSimilarCommunities(w http.ResponseWriter, r *http.Request)
+	GetCommunityCategories(w http.ResponseWriter, r *http.Request)
 	UpdateCommunity(w http.ResponseWriter, r *http.Request)
 	DeleteCommunity(w http.ResponseWriter, r *http.Request)
+	ArchiveCommunity(w http.ResponseWriter, r *http.Request)
+	RestoreCommunity(w http.ResponseWriter, r *http.Request)
+	TransferOwnership(w http.ResponseWriter, r *http.Request)
+	AcceptOwnershipTransfer(w http.ResponseWriter, r *http.Request)
+	DeclineOwnershipTransfer(w http.ResponseWriter, r *http.Request)
+	CancelOwnershipTransfer(w http.ResponseWriter, r *http.Request)
 	JoinCommunity(w http.ResponseWriter, r *http.Request)
+	CancelJoinRequest(w http.ResponseWriter, r *http.Request)
+	GetJoinRequests(w http.ResponseWriter, r *http.Request)
+	ApproveJoinRequest(w http.ResponseWriter, r *http.Request)
+	RejectJoinRequest(w http.ResponseWriter, r *http.Request)
+	CreateInvite(w http.ResponseWriter, r *http.Request)
+	InviteUser(w http.ResponseWriter, r *http.Request)
+	GetInvites(w http.ResponseWriter, r *http.Request)
+	RevokeInvite(w http.ResponseWriter, r *http.Request)
+	LeaveCommunity(w http.ResponseWriter, r *http.Request)
+	GetCommunityMembers(w http.ResponseWriter, r *http.Request)
+	BanMember(w http.ResponseWriter, r *http.Request)
+	UnbanMember(w http.ResponseWriter, r *http.Request)
+	MuteMember(w http.ResponseWriter, r *http.Request)
+	UnmuteMember(w http.ResponseWriter, r *http.Request)
+	GetCommunitySanctions(w http.ResponseWriter, r *http.Request)
 	GetCommunityPosts(w http.ResponseWriter, r *http.Request)
 	GetCommunityDetail(w http.ResponseWriter, r *http.Request)
 	GetUserJoinedCommunities(w http.ResponseWriter, r *http.Request)
@@ -23,10 +49,11 @@ type CommunityHandler interface {
 
 type CommunityHandlerImpl struct {
 	CommunityService service.CommunityService
+	PostStatsService service.PostStatsService
 }
 
-func NewCommunityHandler(service service.CommunityService) CommunityHandler {
-	return &CommunityHandlerImpl{CommunityService: service}
+func NewCommunityHandler(service service.CommunityService, postStatsService service.PostStatsService) CommunityHandler {
+	return &CommunityHandlerImpl{CommunityService: service, PostStatsService: postStatsService}
 }
 
 func (h *CommunityHandlerImpl) CreateCommunity(w http.ResponseWriter, r *http.Request) {
@@ -49,15 +76,89 @@ func (h *CommunityHandlerImpl) CreateCommunity(w http.ResponseWriter, r *http.Re
 }
 
 func (h *CommunityHandlerImpl) GetCommunities(w http.ResponseWriter, r *http.Request) {
-	communities, err := h.CommunityService.GetCommunities(r.Context())
+	page, limit := parsePagination(r)
+
+	req := dto.SearchCommunitiesRequest{
+		Query:    r.URL.Query().Get("q"),
+		Category: r.URL.Query().Get("category"),
+		Tag:      r.URL.Query().Get("tag"),
+	}
+	if universityID := r.URL.Query().Get("university_id"); universityID != "" {
+		id, err := strconv.Atoi(universityID)
+		if err != nil {
+			rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid university ID"})
+			return
+		}
+		req.UniversityID = id
+	}
+
+	communities, total, err := h.CommunityService.GetCommunities(r.Context(), req, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Communities have been retrieved",
+		Data:    communities,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommunityHandlerImpl) GetTrendingCommunities(w http.ResponseWriter, r *http.Request) {
+	page, limit := parsePagination(r)
+
+	communities, total, err := h.CommunityService.GetTrendingCommunities(r.Context(), r.URL.Query().Get("window"), page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Trending communities have been retrieved",
+		Data:    communities,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommunityHandlerImpl) GetSimilarCommunities(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	page, limit := parsePagination(r)
+
+	communities, total, err := h.CommunityService.GetSimilarCommunities(r.Context(), id, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Similar communities have been retrieved",
+		Data:    communities,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommunityHandlerImpl) GetCommunityCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.CommunityService.GetCommunityCategories(r.Context())
 	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
 
-	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
-		"message": "Communities have been retrieved",
-		"data":    communities,
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community categories have been retrieved",
+		Data:    categories,
 	})
 }
 
@@ -76,7 +177,7 @@ func (h *CommunityHandlerImpl) UpdateCommunity(w http.ResponseWriter, r *http.Re
 
 	community, err := h.CommunityService.UpdateCommunity(r.Context(), id, req)
 	if err != nil {
-		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		writeError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -93,12 +194,110 @@ func (h *CommunityHandlerImpl) DeleteCommunity(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if err := h.CommunityService.DeleteCommunity(r.Context(), id); err != nil {
-		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	recoverableUntil, err := h.CommunityService.DeleteCommunity(r.Context(), id, requestUserID(r))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community has been deleted",
+		Data:    dto.DeleteCommunityResponse{RecoverableUntil: recoverableUntil},
+	})
+}
+
+func (h *CommunityHandlerImpl) ArchiveCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	if err := h.CommunityService.ArchiveCommunity(r.Context(), id, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Community has been archived"})
+}
+
+func (h *CommunityHandlerImpl) RestoreCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	community, err := h.CommunityService.RestoreCommunity(r.Context(), id, requestUserID(r))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community has been restored",
+		Data:    community,
+	})
+}
+
+func (h *CommunityHandlerImpl) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	var req dto.TransferOwnershipRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	if err := h.CommunityService.TransferOwnership(r.Context(), id, req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
-	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Community has been deleted"})
+	rest.WriteResponse(w, http.StatusCreated, map[string]string{"message": "Ownership transfer has been offered"})
+}
+
+func (h *CommunityHandlerImpl) AcceptOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	h.respondOwnershipTransfer(w, r, true, "Ownership transfer has been accepted")
+}
+
+func (h *CommunityHandlerImpl) DeclineOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	h.respondOwnershipTransfer(w, r, false, "Ownership transfer has been declined")
+}
+
+func (h *CommunityHandlerImpl) respondOwnershipTransfer(w http.ResponseWriter, r *http.Request, accept bool, message string) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	if err := h.CommunityService.RespondOwnershipTransfer(r.Context(), id, accept, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": message})
+}
+
+func (h *CommunityHandlerImpl) CancelOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	if err := h.CommunityService.CancelOwnershipTransfer(r.Context(), id, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Ownership transfer has been cancelled"})
 }
 
 func (h *CommunityHandlerImpl) JoinCommunity(w http.ResponseWriter, r *http.Request) {
@@ -114,12 +313,237 @@ func (h *CommunityHandlerImpl) JoinCommunity(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if err := h.CommunityService.JoinCommunity(r.Context(), id, req); err != nil {
+	req.UserID = requestUserID(r)
+	status, err := h.CommunityService.JoinCommunity(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if status == constant.CommunityJoinStatusRequested {
+		rest.WriteResponse(w, http.StatusAccepted, dto.MessageResponse{
+			Message: "Join request has been sent to the moderators",
+			Data:    map[string]string{"status": status},
+		})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Successfully joined the community",
+		Data:    map[string]string{"status": status},
+	})
+}
+
+func (h *CommunityHandlerImpl) CancelJoinRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	if err := h.CommunityService.CancelJoinRequest(r.Context(), id, requestUserID(r)); err != nil {
 		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
-	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Successfully joined the community"})
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Join request has been cancelled"})
+}
+
+func (h *CommunityHandlerImpl) GetJoinRequests(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	moderatorID := requestUserID(r)
+
+	page, limit := parsePagination(r)
+
+	requests, total, err := h.CommunityService.GetJoinRequests(r.Context(), id, moderatorID, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Join requests have been retrieved",
+		Data:    requests,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommunityHandlerImpl) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.reviewJoinRequest(w, r, true, "Join request has been approved")
+}
+
+func (h *CommunityHandlerImpl) RejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.reviewJoinRequest(w, r, false, "Join request has been rejected")
+}
+
+func (h *CommunityHandlerImpl) reviewJoinRequest(w http.ResponseWriter, r *http.Request, approve bool, message string) {
+	var req dto.ReviewJoinRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	requestID, err := strconv.Atoi(mux.Vars(r)["request_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid join request ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	if err := h.CommunityService.ReviewJoinRequest(r.Context(), id, requestID, approve, req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": message})
+}
+
+func (h *CommunityHandlerImpl) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateCommunityInviteRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	invite, err := h.CommunityService.CreateInvite(r.Context(), id, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusCreated, dto.MessageResponse{
+		Message: "Invite has been created",
+		Data:    invite,
+	})
+}
+
+func (h *CommunityHandlerImpl) InviteUser(w http.ResponseWriter, r *http.Request) {
+	var req dto.InviteUserRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	if err := h.CommunityService.InviteUser(r.Context(), id, req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Invite has been sent"})
+}
+
+func (h *CommunityHandlerImpl) GetInvites(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	moderatorID := requestUserID(r)
+
+	page, limit := parsePagination(r)
+
+	invites, total, err := h.CommunityService.GetInvites(r.Context(), id, moderatorID, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Invites have been retrieved",
+		Data:    invites,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommunityHandlerImpl) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	inviteID, err := strconv.Atoi(mux.Vars(r)["invite_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid invite ID"})
+		return
+	}
+
+	if err := h.CommunityService.RevokeInvite(r.Context(), id, inviteID, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Invite has been revoked"})
+}
+
+func (h *CommunityHandlerImpl) LeaveCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["community_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	if err := h.CommunityService.LeaveCommunity(r.Context(), id, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Successfully left the community"})
+}
+
+func (h *CommunityHandlerImpl) GetCommunityMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+	page, limit := parsePagination(r)
+
+	members, total, err := h.CommunityService.GetCommunityMembers(r.Context(), id, viewerID, r.URL.Query().Get("q"), page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Community members have been retrieved",
+		Data:    members,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
 }
 
 func (h *CommunityHandlerImpl) GetCommunityPosts(w http.ResponseWriter, r *http.Request) {
@@ -139,13 +563,25 @@ func (h *CommunityHandlerImpl) GetCommunityPosts(w http.ResponseWriter, r *http.
 	if sortBy := r.URL.Query().Get("sort_by"); sortBy != "" {
 		filters["sort_by"] = sortBy
 	}
+	if flair := r.URL.Query().Get("flair_id"); flair != "" {
+		flairID, err := strconv.Atoi(flair)
+		if err != nil {
+			rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid flair ID"})
+			return
+		}
+		filters["flair"] = flairID
+	}
+
+	viewerID := requestUserID(r)
 
-	posts, err := h.CommunityService.GetCommunityPosts(r.Context(), id, filters)
+	posts, err := h.CommunityService.GetCommunityPosts(r.Context(), id, viewerID, filters)
 	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
 
+	h.PostStatsService.RecordImpressions(r.Context(), posts, viewerID)
+
 	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
 		"message": "Community posts have been retrieved",
 		"data":    posts,
@@ -155,6 +591,9 @@ func (h *CommunityHandlerImpl) GetCommunityPosts(w http.ResponseWriter, r *http.
 func (h *CommunityHandlerImpl) GetCommunityDetail(w http.ResponseWriter, r *http.Request) {
 	slug := mux.Vars(r)["slug"]
 	community, err := h.CommunityService.GetCommunityDetail(r.Context(), slug)
+	if writeSlugRedirect(w, r, err) {
+		return
+	}
 	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
@@ -184,3 +623,67 @@ func (h *CommunityHandlerImpl) GetUserJoinedCommunities(w http.ResponseWriter, r
 		"data":    communities,
 	})
 }
+
+func (h *CommunityHandlerImpl) BanMember(w http.ResponseWriter, r *http.Request) {
+	h.applySanction(w, r, h.CommunityService.BanMember, "Member has been banned")
+}
+
+func (h *CommunityHandlerImpl) UnbanMember(w http.ResponseWriter, r *http.Request) {
+	h.applySanction(w, r, h.CommunityService.UnbanMember, "Member has been unbanned")
+}
+
+func (h *CommunityHandlerImpl) MuteMember(w http.ResponseWriter, r *http.Request) {
+	h.applySanction(w, r, h.CommunityService.MuteMember, "Member has been muted")
+}
+
+func (h *CommunityHandlerImpl) UnmuteMember(w http.ResponseWriter, r *http.Request) {
+	h.applySanction(w, r, h.CommunityService.UnmuteMember, "Member has been unmuted")
+}
+
+func (h *CommunityHandlerImpl) applySanction(w http.ResponseWriter, r *http.Request, apply func(context.Context, int, dto.CommunitySanctionRequest) error, message string) {
+	var req dto.CommunitySanctionRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.ModeratorID = requestUserID(r)
+	if err := apply(r.Context(), id, req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": message})
+}
+
+func (h *CommunityHandlerImpl) GetCommunitySanctions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	moderatorID := requestUserID(r)
+
+	page, limit := parsePagination(r)
+
+	sanctions, total, err := h.CommunityService.GetCommunitySanctions(r.Context(), id, moderatorID, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Community sanctions have been retrieved",
+		Data:    sanctions,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/service"
+	rest "github.com/temuka-api-service/util/rest"
+)
+
+type CommunityRuleHandler interface {
+	GetRules(w http.ResponseWriter, r *http.Request)
+	CreateRule(w http.ResponseWriter, r *http.Request)
+	UpdateRule(w http.ResponseWriter, r *http.Request)
+	DeleteRule(w http.ResponseWriter, r *http.Request)
+	ReorderRules(w http.ResponseWriter, r *http.Request)
+}
+
+type CommunityRuleHandlerImpl struct {
+	CommunityRuleService service.CommunityRuleService
+}
+
+func NewCommunityRuleHandler(ruleService service.CommunityRuleService) CommunityRuleHandler {
+	return &CommunityRuleHandlerImpl{
+		CommunityRuleService: ruleService,
+	}
+}
+
+func (h *CommunityRuleHandlerImpl) GetRules(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	rules, err := h.CommunityRuleService.GetRules(r.Context(), id)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community rules have been retrieved",
+		Data:    rules,
+	})
+}
+
+func (h *CommunityRuleHandlerImpl) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req dto.CommunityRuleRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	rule, err := h.CommunityRuleService.CreateRule(r.Context(), id, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusCreated, dto.MessageResponse{
+		Message: "Community rule has been created",
+		Data:    rule,
+	})
+}
+
+func (h *CommunityRuleHandlerImpl) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	var req dto.CommunityRuleRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	ruleID, err := strconv.Atoi(mux.Vars(r)["rule_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid rule ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	rule, err := h.CommunityRuleService.UpdateRule(r.Context(), id, ruleID, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community rule has been updated",
+		Data:    rule,
+	})
+}
+
+func (h *CommunityRuleHandlerImpl) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	ruleID, err := strconv.Atoi(mux.Vars(r)["rule_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid rule ID"})
+		return
+	}
+
+	if err := h.CommunityRuleService.DeleteRule(r.Context(), id, ruleID, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "Community rule has been deleted"})
+}
+
+func (h *CommunityRuleHandlerImpl) ReorderRules(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReorderCommunityRulesRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	rules, err := h.CommunityRuleService.ReorderRules(r.Context(), id, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Community rules have been reordered",
+		Data:    rules,
+	})
+}
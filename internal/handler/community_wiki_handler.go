@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/service"
+	rest "github.com/temuka-api-service/util/rest"
+)
+
+type CommunityWikiHandler interface {
+	GetPages(w http.ResponseWriter, r *http.Request)
+	CreatePage(w http.ResponseWriter, r *http.Request)
+	GetPageByPath(w http.ResponseWriter, r *http.Request)
+	GetPage(w http.ResponseWriter, r *http.Request)
+	UpdatePage(w http.ResponseWriter, r *http.Request)
+	DeletePage(w http.ResponseWriter, r *http.Request)
+	SetEditPermission(w http.ResponseWriter, r *http.Request)
+	RevertPage(w http.ResponseWriter, r *http.Request)
+	GetRevisions(w http.ResponseWriter, r *http.Request)
+	GetRevision(w http.ResponseWriter, r *http.Request)
+	DiffRevisions(w http.ResponseWriter, r *http.Request)
+}
+
+type CommunityWikiHandlerImpl struct {
+	CommunityWikiService service.CommunityWikiService
+}
+
+func NewCommunityWikiHandler(wikiService service.CommunityWikiService) CommunityWikiHandler {
+	return &CommunityWikiHandlerImpl{
+		CommunityWikiService: wikiService,
+	}
+}
+
+// GetPages lists the wiki pages of a community. The optional prefix query parameter limits
+// the list to one page and the pages below it.
+func (h *CommunityWikiHandlerImpl) GetPages(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+
+	pages, err := h.CommunityWikiService.GetPages(r.Context(), id, viewerID, r.URL.Query().Get("prefix"))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki pages have been retrieved",
+		Data:    pages,
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) CreatePage(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateWikiPageRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	page, err := h.CommunityWikiService.CreatePage(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusCreated, dto.MessageResponse{
+		Message: "Wiki page has been created",
+		Data:    page,
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) GetPageByPath(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+
+	page, err := h.CommunityWikiService.GetPageByPath(r.Context(), id, viewerID, r.URL.Query().Get("path"))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki page has been retrieved",
+		Data:    page,
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) GetPage(w http.ResponseWriter, r *http.Request) {
+	pageID, err := strconv.Atoi(mux.Vars(r)["page_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid page ID"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+
+	page, err := h.CommunityWikiService.GetPage(r.Context(), pageID, viewerID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki page has been retrieved",
+		Data:    page,
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) UpdatePage(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateWikiPageRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	pageID, err := strconv.Atoi(mux.Vars(r)["page_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid page ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	page, err := h.CommunityWikiService.UpdatePage(r.Context(), pageID, req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki page has been updated",
+		Data:    page,
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) DeletePage(w http.ResponseWriter, r *http.Request) {
+	pageID, err := strconv.Atoi(mux.Vars(r)["page_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid page ID"})
+		return
+	}
+
+	if err := h.CommunityWikiService.DeletePage(r.Context(), pageID, requestUserID(r)); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki page has been deleted",
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) SetEditPermission(w http.ResponseWriter, r *http.Request) {
+	var req dto.WikiPagePermissionRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	pageID, err := strconv.Atoi(mux.Vars(r)["page_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid page ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	page, err := h.CommunityWikiService.SetEditPermission(r.Context(), pageID, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki page permission has been updated",
+		Data:    page,
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) RevertPage(w http.ResponseWriter, r *http.Request) {
+	var req dto.RevertWikiPageRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	pageID, err := strconv.Atoi(mux.Vars(r)["page_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid page ID"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	page, err := h.CommunityWikiService.RevertPage(r.Context(), pageID, req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki page has been reverted",
+		Data:    page,
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	pageID, err := strconv.Atoi(mux.Vars(r)["page_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid page ID"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+	page, limit := parsePagination(r)
+
+	revisions, total, err := h.CommunityWikiService.GetRevisions(r.Context(), pageID, viewerID, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Wiki revisions have been retrieved",
+		Data:    revisions,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *CommunityWikiHandlerImpl) GetRevision(w http.ResponseWriter, r *http.Request) {
+	pageID, err := strconv.Atoi(mux.Vars(r)["page_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid page ID"})
+		return
+	}
+	revision, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid revision"})
+		return
+	}
+
+	viewerID := requestUserID(r)
+
+	rev, err := h.CommunityWikiService.GetRevision(r.Context(), pageID, revision, viewerID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki revision has been retrieved",
+		Data:    rev,
+	})
+}
+
+// DiffRevisions compares the revisions in the from and to query parameters. Leaving out to
+// compares against the current revision.
+func (h *CommunityWikiHandlerImpl) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	pageID, err := strconv.Atoi(mux.Vars(r)["page_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid page ID"})
+		return
+	}
+	query := r.URL.Query()
+
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid from revision"})
+		return
+	}
+	to := 0
+	if query.Get("to") != "" {
+		if to, err = strconv.Atoi(query.Get("to")); err != nil {
+			rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid to revision"})
+			return
+		}
+	}
+
+	viewerID := requestUserID(r)
+
+	diff, err := h.CommunityWikiService.DiffRevisions(r.Context(), pageID, from, to, viewerID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{
+		Message: "Wiki revisions have been compared",
+		Data:    diff,
+	})
+}
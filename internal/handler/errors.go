@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/service"
+	"github.com/temuka-api-service/util/rest"
+)
+
+// serviceErrorStatuses maps the codes of service errors that are not plain 403s.
+var serviceErrorStatuses = map[string]int{
+	constant.CommentErrorSlowMode:       http.StatusTooManyRequests,
+	constant.PostStatsErrorPostNotFound: http.StatusNotFound,
+	constant.PostStatsErrorInvalidRange: http.StatusBadRequest,
+}
+
+// writeError writes err as a JSON error. Coded service errors carry their code and are
+// written as 403 unless listed in serviceErrorStatuses; other errors are written with status.
+func writeError(w http.ResponseWriter, err error, status int) {
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) {
+		rest.WriteResponse(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+
+	status = http.StatusForbidden
+	if s, ok := serviceErrorStatuses[serviceErr.Code]; ok {
+		status = s
+	}
+	rest.WriteResponse(w, status, map[string]string{"error": serviceErr.Message, "code": serviceErr.Code})
+}
+
+// writeSlugRedirect answers a lookup by a retired slug with a permanent redirect to the
+// same route under the current slug. It reports false when err is not a SlugMovedError.
+func writeSlugRedirect(w http.ResponseWriter, r *http.Request, err error) bool {
+	var moved *service.SlugMovedError
+	if !errors.As(err, &moved) {
+		return false
+	}
+
+	location := url.URL{Path: path.Join(path.Dir(r.URL.Path), moved.Slug), RawQuery: r.URL.RawQuery}
+	w.Header().Set("Location", location.String())
+	rest.WriteResponse(w, http.StatusMovedPermanently, map[string]string{
+		"message": "This page has moved",
+		"slug":    moved.Slug,
+	})
+	return true
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/service"
+	rest "github.com/temuka-api-service/util/rest"
+)
+
+type PollHandler interface {
+	GetPostPoll(w http.ResponseWriter, r *http.Request)
+	Vote(w http.ResponseWriter, r *http.Request)
+	GetPollVoters(w http.ResponseWriter, r *http.Request)
+}
+
+type PollHandlerImpl struct {
+	PollService service.PollService
+}
+
+func NewPollHandler(pollService service.PollService) PollHandler {
+	return &PollHandlerImpl{
+		PollService: pollService,
+	}
+}
+
+func (h *PollHandlerImpl) GetPostPoll(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["post_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid post ID"})
+		return
+	}
+	viewerID := requestUserID(r)
+
+	poll, err := h.PollService.GetPostPoll(r.Context(), postID, viewerID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Poll has been retrieved",
+		"data":    poll,
+	})
+}
+
+func (h *PollHandlerImpl) Vote(w http.ResponseWriter, r *http.Request) {
+	pollID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid poll ID"})
+		return
+	}
+
+	var req dto.VotePollRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	req.UserID = requestUserID(r)
+	if req.UserID == 0 {
+		rest.WriteResponse(w, http.StatusUnauthorized, map[string]string{"error": "You are not authorized"})
+		return
+	}
+
+	poll, err := h.PollService.Vote(r.Context(), pollID, req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Vote has been cast",
+		"data":    poll,
+	})
+}
+
+func (h *PollHandlerImpl) GetPollVoters(w http.ResponseWriter, r *http.Request) {
+	pollID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid poll ID"})
+		return
+	}
+	viewerID := requestUserID(r)
+
+	voters, err := h.PollService.GetPollVoters(r.Context(), pollID, viewerID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Poll voters have been retrieved",
+		"data":    voters,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/dto"
 	"github.com/temuka-api-service/internal/service"
 	"github.com/temuka-api-service/util/rest"
@@ -18,14 +19,28 @@ type PostHandler interface {
 	DeletePost(w http.ResponseWriter, r *http.Request)
 	GetTimelinePosts(w http.ResponseWriter, r *http.Request)
 	LikePost(w http.ResponseWriter, r *http.Request)
+	GetUnpublishedPosts(w http.ResponseWriter, r *http.Request)
+	PublishPost(w http.ResponseWriter, r *http.Request)
+	RepostPost(w http.ResponseWriter, r *http.Request)
+	GetPostStats(w http.ResponseWriter, r *http.Request)
+	LockPost(w http.ResponseWriter, r *http.Request)
+	UnlockPost(w http.ResponseWriter, r *http.Request)
+	UpdateCommentSettings(w http.ResponseWriter, r *http.Request)
+	SetPostFlair(w http.ResponseWriter, r *http.Request)
+	PinPost(w http.ResponseWriter, r *http.Request)
+	UnpinPost(w http.ResponseWriter, r *http.Request)
+	ReorderPinnedPosts(w http.ResponseWriter, r *http.Request)
+	CreateAnnouncement(w http.ResponseWriter, r *http.Request)
+	GetPostModerationLogs(w http.ResponseWriter, r *http.Request)
 }
 
 type PostHandlerImpl struct {
-	postService service.PostService
+	postService      service.PostService
+	postStatsService service.PostStatsService
 }
 
-func NewPostHandler(s service.PostService) PostHandler {
-	return &PostHandlerImpl{postService: s}
+func NewPostHandler(s service.PostService, statsService service.PostStatsService) PostHandler {
+	return &PostHandlerImpl{postService: s, postStatsService: statsService}
 }
 
 func (h *PostHandlerImpl) CreatePost(w http.ResponseWriter, r *http.Request) {
@@ -37,7 +52,7 @@ func (h *PostHandlerImpl) CreatePost(w http.ResponseWriter, r *http.Request) {
 
 	post, err := h.postService.CreatePost(r.Context(), &req)
 	if err != nil {
-		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		writeError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -47,22 +62,32 @@ func (h *PostHandlerImpl) CreatePost(w http.ResponseWriter, r *http.Request) {
 
 func (h *PostHandlerImpl) GetPostDetail(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
-	post, err := h.postService.GetPostDetail(r.Context(), id)
+	viewerID, viewerKey := viewerFromRequest(r)
+
+	post, err := h.postService.GetPostDetail(r.Context(), id, viewerID)
 	if err != nil {
 		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": "Post not found"})
 		return
 	}
+
+	h.postStatsService.RecordView(r.Context(), post, viewerID, viewerKey)
+
 	resp := dto.MessageResponse{Message: "Post detail retrieved", Data: post}
 	rest.WriteResponse(w, http.StatusOK, resp)
 }
 
 func (h *PostHandlerImpl) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 	userID, _ := strconv.Atoi(mux.Vars(r)["user_id"])
-	posts, err := h.postService.GetUserPosts(r.Context(), userID)
+	viewerID, _ := viewerFromRequest(r)
+
+	posts, err := h.postService.GetUserPosts(r.Context(), userID, viewerID)
 	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+
+	h.postStatsService.RecordImpressions(r.Context(), posts, viewerID)
+
 	resp := dto.MessageResponse{Message: "User posts retrieved", Data: posts}
 	rest.WriteResponse(w, http.StatusOK, resp)
 }
@@ -75,6 +100,7 @@ func (h *PostHandlerImpl) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req.UserID = requestUserID(r)
 	post, err := h.postService.UpdatePost(r.Context(), id, &req)
 	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
@@ -97,26 +123,246 @@ func (h *PostHandlerImpl) DeletePost(w http.ResponseWriter, r *http.Request) {
 
 func (h *PostHandlerImpl) GetTimelinePosts(w http.ResponseWriter, r *http.Request) {
 	userID, _ := strconv.Atoi(mux.Vars(r)["user_id"])
+	if userID != requestUserID(r) {
+		rest.WriteResponse(w, http.StatusForbidden, map[string]string{"error": "Not allowed to view this timeline"})
+		return
+	}
+
 	posts, err := h.postService.GetTimelinePosts(r.Context(), userID)
 	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
+
+	h.postStatsService.RecordImpressions(r.Context(), posts, userID)
+
 	resp := dto.MessageResponse{Message: "Timeline posts retrieved", Data: posts}
 	rest.WriteResponse(w, http.StatusOK, resp)
 }
 
 func (h *PostHandlerImpl) LikePost(w http.ResponseWriter, r *http.Request) {
 	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
-	var req dto.LikePostRequest
+	liked, err := h.postService.LikePost(r.Context(), postID, requestUserID(r))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if liked {
+		h.postStatsService.RecordEngagement(r.Context(), postID, constant.PostStatLikes)
+	}
+
+	resp := dto.MessageResponse{Message: "You have liked this post"}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) GetUnpublishedPosts(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.Atoi(mux.Vars(r)["user_id"])
+	if userID != requestUserID(r) {
+		rest.WriteResponse(w, http.StatusForbidden, map[string]string{"error": "Not allowed to view these posts"})
+		return
+	}
+
+	posts, err := h.postService.GetUnpublishedPosts(r.Context(), userID)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	resp := dto.MessageResponse{Message: "Unpublished posts retrieved", Data: posts}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) PublishPost(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	post, err := h.postService.PublishPost(r.Context(), postID, requestUserID(r))
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	resp := dto.MessageResponse{Message: "Post published", Data: post}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) RepostPost(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var req dto.RepostRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		return
+	}
+	reposts, err := h.postService.RepostPost(r.Context(), postID, &req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	resp := dto.MessageResponse{Message: "Post reposted", Data: reposts}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) GetPostStats(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	query := r.URL.Query()
+
+	userID := requestUserID(r)
+
+	stats, err := h.postStatsService.GetPostStats(r.Context(), postID, userID, query.Get("from"), query.Get("to"))
+	if err != nil {
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp := dto.MessageResponse{Message: "Post stats retrieved", Data: stats}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) LockPost(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var req dto.LockPostRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		return
+	}
+	req.UserID = requestUserID(r)
+	if err := h.postService.LockPost(r.Context(), postID, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{Message: "Post locked"})
+}
+
+func (h *PostHandlerImpl) UnlockPost(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var req dto.LockPostRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		return
+	}
+	req.UserID = requestUserID(r)
+	if err := h.postService.UnlockPost(r.Context(), postID, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	rest.WriteResponse(w, http.StatusOK, dto.MessageResponse{Message: "Post unlocked"})
+}
+
+func (h *PostHandlerImpl) UpdateCommentSettings(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var req dto.CommentSettingsRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		return
+	}
+	req.UserID = requestUserID(r)
+	post, err := h.postService.UpdateCommentSettings(r.Context(), postID, &req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	resp := dto.MessageResponse{Message: "Comment settings updated", Data: post}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) SetPostFlair(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var req dto.SetPostFlairRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		return
+	}
+	req.UserID = requestUserID(r)
+	post, err := h.postService.SetPostFlair(r.Context(), postID, &req)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	resp := dto.MessageResponse{Message: "Post flair updated", Data: post}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) PinPost(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var req dto.PinPostRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		return
+	}
+	req.UserID = requestUserID(r)
+	if err := h.postService.PinPost(r.Context(), postID, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	resp := dto.MessageResponse{Message: "Post pinned"}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) UnpinPost(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var req dto.PinPostRequest
 	if err := rest.ReadRequest(r, &req); err != nil {
 		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 		return
 	}
-	if err := h.postService.LikePost(r.Context(), postID, req.UserID); err != nil {
+	req.UserID = requestUserID(r)
+	if err := h.postService.UnpinPost(r.Context(), postID, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	resp := dto.MessageResponse{Message: "Post unpinned"}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) ReorderPinnedPosts(w http.ResponseWriter, r *http.Request) {
+	communityID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+	var req dto.ReorderPinnedPostsRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		return
+	}
+	req.UserID = requestUserID(r)
+	if err := h.postService.ReorderPinnedPosts(r.Context(), communityID, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	resp := dto.MessageResponse{Message: "Pinned posts reordered"}
+	rest.WriteResponse(w, http.StatusOK, resp)
+}
+
+func (h *PostHandlerImpl) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	communityID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid community ID"})
+		return
+	}
+	var req dto.CreateAnnouncementRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+		return
+	}
+	req.UserID = requestUserID(r)
+	post, err := h.postService.CreateAnnouncement(r.Context(), communityID, &req)
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	resp := dto.MessageResponse{Message: "Announcement created", Data: post}
+	rest.WriteResponse(w, http.StatusCreated, resp)
+}
+
+func (h *PostHandlerImpl) GetPostModerationLogs(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	userID := requestUserID(r)
+
+	logs, err := h.postService.GetPostModerationLogs(r.Context(), postID, userID)
+	if err != nil {
 		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
-	resp := dto.MessageResponse{Message: "You have liked this post"}
+
+	resp := dto.MessageResponse{Message: "Moderation log retrieved", Data: logs}
 	rest.WriteResponse(w, http.StatusOK, resp)
 }
@@ -35,13 +35,18 @@ func (h *ReportHandlerImpl) CreateReport(w http.ResponseWriter, r *http.Request)
 	}
 
 	report := &model.Report{
-		CommentID: &request.CommentID,
-		PostID:    &request.PostID,
-		Reason:    request.Reason,
+		Reason: request.Reason,
+		RuleID: request.RuleID,
+	}
+	if request.CommentID != 0 {
+		report.CommentID = &request.CommentID
+	}
+	if request.PostID != 0 {
+		report.PostID = &request.PostID
 	}
 
 	if err := h.ReportService.CreateReport(r.Context(), report); err != nil {
-		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
 
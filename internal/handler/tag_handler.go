@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/service"
+	rest "github.com/temuka-api-service/util/rest"
+)
+
+type TagHandler interface {
+	GetTagPosts(w http.ResponseWriter, r *http.Request)
+	GetTrendingTags(w http.ResponseWriter, r *http.Request)
+}
+
+type TagHandlerImpl struct {
+	TagService service.TagService
+}
+
+func NewTagHandler(tagService service.TagService) TagHandler {
+	return &TagHandlerImpl{
+		TagService: tagService,
+	}
+}
+
+func (h *TagHandlerImpl) GetTagPosts(w http.ResponseWriter, r *http.Request) {
+	page, limit := parsePagination(r)
+
+	viewerID := requestUserID(r)
+
+	posts, total, err := h.TagService.GetTagPosts(r.Context(), mux.Vars(r)["name"], viewerID, page, limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, dto.PaginatedResponse{
+		Message: "Tag posts have been retrieved",
+		Data:    posts,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	})
+}
+
+func (h *TagHandlerImpl) GetTrendingTags(w http.ResponseWriter, r *http.Request) {
+	_, limit := parsePagination(r)
+
+	tags, err := h.TagService.GetTrendingTags(r.Context(), r.URL.Query().Get("window"), limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Trending tags have been retrieved",
+		"data":    tags,
+	})
+}
@@ -17,6 +17,8 @@ type UniversityHandler interface {
 	GetUniversities(w http.ResponseWriter, r *http.Request)
 	AddReview(w http.ResponseWriter, r *http.Request)
 	GetUniversityReviews(w http.ResponseWriter, r *http.Request)
+	VerifyUniversityMember(w http.ResponseWriter, r *http.Request)
+	RevokeUniversityVerification(w http.ResponseWriter, r *http.Request)
 }
 
 type UniversityHandlerImpl struct {
@@ -80,7 +82,12 @@ func (h *UniversityHandlerImpl) GetUniversityDetail(w http.ResponseWriter, r *ht
 	vars := mux.Vars(r)
 	slug := vars["slug"]
 
-	university, err := h.UniversityService.GetUniversityDetail(r.Context(), slug)
+	page, limit := parsePagination(r)
+
+	university, err := h.UniversityService.GetUniversityDetail(r.Context(), slug, page, limit)
+	if writeSlugRedirect(w, r, err) {
+		return
+	}
 	if err != nil {
 		rest.WriteResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
 		return
@@ -145,3 +152,45 @@ func (h *UniversityHandlerImpl) GetUniversityReviews(w http.ResponseWriter, r *h
 		"data":    reviews,
 	})
 }
+
+func (h *UniversityHandlerImpl) VerifyUniversityMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid university ID"})
+		return
+	}
+
+	var req dto.VerifyUniversityMemberRequest
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.UniversityService.VerifyUniversityMember(r.Context(), id, requestUserID(r), req.UserID); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "University member has been verified"})
+}
+
+func (h *UniversityHandlerImpl) RevokeUniversityVerification(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid university ID"})
+		return
+	}
+	userID, err := strconv.Atoi(vars["user_id"])
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.UniversityService.RevokeUniversityVerification(r.Context(), id, requestUserID(r), userID); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rest.WriteResponse(w, http.StatusOK, map[string]string{"message": "University verification has been revoked"})
+}
@@ -17,15 +17,20 @@ type UserHandler interface {
 	UpdateUser(w http.ResponseWriter, r *http.Request)
 	FollowUser(w http.ResponseWriter, r *http.Request)
 	GetFollowers(w http.ResponseWriter, r *http.Request)
+	BlockUser(w http.ResponseWriter, r *http.Request)
+	UnblockUser(w http.ResponseWriter, r *http.Request)
+	AutocompleteMentions(w http.ResponseWriter, r *http.Request)
 }
 
 type UserHandlerImpl struct {
-	UserService service.UserService
+	UserService    service.UserService
+	MentionService service.MentionService
 }
 
-func NewUserHandler(userService service.UserService) UserHandler {
+func NewUserHandler(userService service.UserService, mentionService service.MentionService) UserHandler {
 	return &UserHandlerImpl{
-		UserService: userService,
+		UserService:    userService,
+		MentionService: mentionService,
 	}
 }
 
@@ -168,3 +173,69 @@ func (h *UserHandlerImpl) GetFollowers(w http.ResponseWriter, r *http.Request) {
 
 	rest.WriteResponse(w, http.StatusOK, response)
 }
+
+func (h *UserHandlerImpl) BlockUser(w http.ResponseWriter, r *http.Request) {
+	var req dto.BlockUserDTO
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.UserService.BlockUser(r.Context(), req); err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	response := map[string]string{"message": "User blocked successfully"}
+	rest.WriteResponse(w, http.StatusOK, response)
+}
+
+func (h *UserHandlerImpl) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	var req dto.BlockUserDTO
+	if err := rest.ReadRequest(r, &req); err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.UserService.UnblockUser(r.Context(), req); err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	response := map[string]string{"message": "User unblocked successfully"}
+	rest.WriteResponse(w, http.StatusOK, response)
+}
+
+func (h *UserHandlerImpl) AutocompleteMentions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	userID, err := strconv.Atoi(query.Get("user_id"))
+	if err != nil {
+		rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		return
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil {
+			rest.WriteResponse(w, http.StatusBadRequest, map[string]string{"error": "Invalid limit"})
+			return
+		}
+	}
+
+	candidates, err := h.MentionService.AutocompleteMentions(r.Context(), userID, query.Get("q"), limit)
+	if err != nil {
+		rest.WriteResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	response := struct {
+		Message string      `json:"message"`
+		Data    interface{} `json:"data"`
+	}{
+		Message: "Mention suggestions retrieved successfully",
+		Data:    candidates,
+	}
+
+	rest.WriteResponse(w, http.StatusOK, response)
+}
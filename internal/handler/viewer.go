@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/middleware"
+)
+
+// trustedProxies are the reverse proxies whose X-Forwarded-For header is believed,
+// read once from TRUSTED_PROXIES as a comma-separated list of IPs or CIDRs.
+var trustedProxies = sync.OnceValue(func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv(constant.EnvTrustedProxies), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+})
+
+// requestUserID is the user that middleware.CheckAuth authenticated the request as, or 0
+// on routes that are not behind it. Reads must use it as the viewer rather than any ID the
+// caller sends, or restricted content is one query parameter away.
+func requestUserID(r *http.Request) int {
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	return userID
+}
+
+// viewerFromRequest identifies who is looking at a response. The viewer ID decides what
+// the viewer may read; the key that counts unique viewers is the user when the request is
+// authenticated, so one person on several networks counts once and several people behind
+// one NAT count apart, and the client address otherwise.
+func viewerFromRequest(r *http.Request) (int, string) {
+	viewerID := requestUserID(r)
+	if viewerID != 0 {
+		return viewerID, "user:" + strconv.Itoa(viewerID)
+	}
+	return 0, "ip:" + clientIP(r)
+}
+
+// clientIP is the peer address of the request. X-Forwarded-For is only followed while
+// the hop that appended to it is a trusted proxy, so clients cannot choose their address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0 && isTrustedProxy(host); i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		host = hop
+	}
+	return host
+}
+
+func isTrustedProxy(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies() {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
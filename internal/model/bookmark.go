@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// Bookmark points at a post, comment or university. When the target is deleted the
+// bookmark is kept as a tombstone (TargetDeletedAt set) so the user sees what went away.
+type Bookmark struct {
+	ID              int        `gorm:"primary_key;column:id"`
+	UserID          int        `gorm:"column:user_id;index"`
+	CollectionID    int        `gorm:"column:collection_id;uniqueIndex:idx_bookmarks_collection_target"`
+	TargetType      string     `gorm:"column:target_type;uniqueIndex:idx_bookmarks_collection_target;index:idx_bookmarks_target"`
+	TargetID        int        `gorm:"column:target_id;uniqueIndex:idx_bookmarks_collection_target;index:idx_bookmarks_target"`
+	TargetDeletedAt *time.Time `gorm:"column:target_deleted_at;default:null"`
+	CreatedAt       time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (b *Bookmark) TableName() string {
+	return "bookmarks"
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BookmarkCollection struct {
+	gorm.Model
+	ID        int        `gorm:"primary_key;column:id"`
+	UserID    int        `gorm:"column:user_id;index"`
+	Name      string     `gorm:"column:name"`
+	Bookmarks []Bookmark `gorm:"foreignKey:CollectionID"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (b *BookmarkCollection) TableName() string {
+	return "bookmark_collections"
+}
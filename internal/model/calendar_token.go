@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// CalendarToken is the secret that lets calendar apps, which cannot log in, read the
+// personal event feed of a user.
+type CalendarToken struct {
+	ID        int       `gorm:"primary_key;column:id"`
+	UserID    int       `gorm:"column:user_id;uniqueIndex"`
+	Token     string    `gorm:"column:token;uniqueIndex"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (t *CalendarToken) TableName() string {
+	return "calendar_tokens"
+}
@@ -13,10 +13,20 @@ type Comment struct {
 	PostID        int            `gorm:"column:post_id"`
 	ParentID      *int           `gorm:"column:parent_id"`
 	Content       string         `gorm:"column:content"`
+	ContentHTML   string         `gorm:"column:content_html"`
+	Upvotes       int            `gorm:"column:upvotes;default:0"`
+	Downvotes     int            `gorm:"column:downvotes;default:0"`
+	Score         int            `gorm:"column:score;default:0"`
+	Status        string         `gorm:"column:status;default:active"`
+	EditedAt      *time.Time     `gorm:"column:edited_at;default:null"`
+	RemovedBy     *int           `gorm:"column:removed_by;default:null"`
+	RemovedAt     *time.Time     `gorm:"column:removed_at;default:null"`
+	RemovalReason string         `gorm:"column:removal_reason"`
+	RemovalRuleID *int           `gorm:"column:removal_rule_id;default:null"`
 	Replies       []Comment      `gorm:"foreignKey:ParentID;references:ID"`
 	Parent        *Comment       `gorm:"foreignKey:ParentID;references:ID"`
-	Votes         []*User        `gorm:"many2many:user_votes;"`
-	Notifications []Notification `gorm:foreignKey:CommentID`
+	Votes         []CommentVote  `gorm:"foreignKey:CommentID"`
+	Notifications []Notification `gorm:"foreignKey:CommentID"`
 	CreatedAt     time.Time      `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt     time.Time      `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 }
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// CommentEdit keeps a previous version of a comment. One is written on every edit and
+// when a comment is tombstoned, so its original content stays available to moderators.
+// Action is "edit", "deleted" or "removed".
+type CommentEdit struct {
+	ID        int       `gorm:"primary_key;column:id"`
+	CommentID int       `gorm:"column:comment_id;index"`
+	EditorID  int       `gorm:"column:editor_id"`
+	Action    string    `gorm:"column:action"`
+	Content   string    `gorm:"column:content"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (ce *CommentEdit) TableName() string {
+	return "comment_edits"
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// CommentVote is a user's vote on a comment, +1 for up and -1 for down. The composite
+// primary key allows a single vote per user; retracting a vote deletes the row.
+type CommentVote struct {
+	CommentID int       `gorm:"primaryKey;autoIncrement:false;column:comment_id"`
+	UserID    int       `gorm:"primaryKey;autoIncrement:false;column:user_id;index"`
+	Value     int       `gorm:"column:value;default:1"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (cv *CommentVote) TableName() string {
+	return "user_votes"
+}
@@ -6,13 +6,21 @@ import (
 	"gorm.io/gorm"
 )
 
+// Community is a group users join to post and talk together. Deleting one only sets DeletedAt;
+// it can be restored until the recovery window ends, after which it is purged for good.
 type Community struct {
 	gorm.Model
 	ID               int               `gorm:"primary_key;column:id"`
+	OwnerID          *int              `gorm:"column:owner_id;default:null;index"`
 	Name             string            `gorm:"column:name"`
-	Slug             string            `gorm:"column:slug"`
+	Slug             string            `gorm:"column:slug;uniqueIndex"`
 	Description      string            `gorm:"column:desc"`
-	Rules            string            `gorm:"column:rules"`
+	Category         string            `gorm:"column:category;index"`
+	UniversityID     *int              `gorm:"column:university_id;default:null;index"`
+	Visibility       string            `gorm:"column:visibility;default:public;index"`
+	FlairRequired    bool              `gorm:"column:flair_required;default:false"`
+	Status           string            `gorm:"column:status;default:active;index"`
+	ArchivedAt       *time.Time        `gorm:"column:archived_at;default:null"`
 	MembersCount     int               `gorm:"column:members_count"`
 	PostsCount       int               `gorm:"column:posts_count"`
 	LogoPicture      string            `gorm:"column:logo_picture"`
@@ -20,6 +28,8 @@ type Community struct {
 	CommunityMembers []CommunityMember `gorm:"foreignKey:CommunityID"`
 	Moderators       []Moderator       `gorm:"foreignKey:CommunityID"`
 	CommunityPosts   []CommunityPost   `gorm:"foreignKey:CommunityID"`
+	Rules            []CommunityRule   `gorm:"foreignKey:CommunityID"`
+	Tags             []CommunityTag    `gorm:"foreignKey:CommunityID"`
 	CreatedAt        time.Time         `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt        time.Time         `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 }
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunityAnnouncement tracks the delivery of an announcement post to the members of its
+// community. Members are notified in batches by ascending membership ID; LastMemberID is
+// the cursor of the last batch, so delivery resumes where it stopped after a restart.
+type CommunityAnnouncement struct {
+	ID             int        `gorm:"primary_key;column:id"`
+	PostID         int        `gorm:"column:post_id;uniqueIndex"`
+	CommunityID    int        `gorm:"column:community_id;index"`
+	AuthorID       int        `gorm:"column:author_id"`
+	Status         string     `gorm:"column:status;default:pending;index"`
+	LastMemberID   int        `gorm:"column:last_member_id;default:0"`
+	DeliveredCount int        `gorm:"column:delivered_count;default:0"`
+	CompletedAt    *time.Time `gorm:"column:completed_at;default:null"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (a *CommunityAnnouncement) TableName() string {
+	return "community_announcements"
+}
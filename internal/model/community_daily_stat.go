@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// CommunityDailyStat holds the activity of a community on one UTC day, as computed by the
+// analytics rollup.
+type CommunityDailyStat struct {
+	ID          int       `gorm:"primary_key;column:id"`
+	CommunityID int       `gorm:"column:community_id;uniqueIndex:idx_community_daily_stats_community_date"`
+	Date        time.Time `gorm:"column:date;type:date;uniqueIndex:idx_community_daily_stats_community_date;index"`
+	NewMembers  int64     `gorm:"column:new_members;default:0"`
+	Leaves      int64     `gorm:"column:leaves;default:0"`
+	Posts       int64     `gorm:"column:posts;default:0"`
+	Comments    int64     `gorm:"column:comments;default:0"`
+	Likes       int64     `gorm:"column:likes;default:0"`
+	ActiveUsers int64     `gorm:"column:active_users;default:0"`
+	Reports     int64     `gorm:"column:reports;default:0"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (s *CommunityDailyStat) TableName() string {
+	return "community_daily_stats"
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommunityEvent is a meetup or session organised in a community. StartsAt and EndsAt are
+// instants; TimeZone is the IANA zone the organiser planned it in, used to display it. A
+// Capacity of 0 means no limit. Sequence grows with every change so calendar clients pick
+// up updates.
+type CommunityEvent struct {
+	gorm.Model
+	ID            int        `gorm:"primary_key;column:id"`
+	CommunityID   int        `gorm:"column:community_id;index:idx_community_events_community_start,priority:1"`
+	OrganizerID   int        `gorm:"column:organizer_id"`
+	Title         string     `gorm:"column:title"`
+	Description   string     `gorm:"column:description"`
+	StartsAt      time.Time  `gorm:"column:starts_at;index:idx_community_events_community_start,priority:2;index"`
+	EndsAt        time.Time  `gorm:"column:ends_at"`
+	TimeZone      string     `gorm:"column:time_zone"`
+	OnlineURL     string     `gorm:"column:online_url"`
+	Location      string     `gorm:"column:location"`
+	Capacity      int        `gorm:"column:capacity;default:0"`
+	GoingCount    int        `gorm:"column:going_count;default:0"`
+	MaybeCount    int        `gorm:"column:maybe_count;default:0"`
+	WaitlistCount int        `gorm:"column:waitlist_count;default:0"`
+	Status        string     `gorm:"column:status;default:scheduled"`
+	Sequence      int        `gorm:"column:sequence;default:0"`
+	RemindedAt    *time.Time `gorm:"column:reminded_at;default:null"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (e *CommunityEvent) TableName() string {
+	return "community_events"
+}
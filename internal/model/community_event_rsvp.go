@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunityEventRSVP is the answer of a user to an event. WaitlistedAt orders the waitlist.
+type CommunityEventRSVP struct {
+	ID           int        `gorm:"primary_key;column:id"`
+	EventID      int        `gorm:"column:event_id;uniqueIndex:idx_community_event_rsvps_event_user,priority:1"`
+	UserID       int        `gorm:"column:user_id;uniqueIndex:idx_community_event_rsvps_event_user,priority:2;index"`
+	Status       string     `gorm:"column:status"`
+	WaitlistedAt *time.Time `gorm:"column:waitlisted_at;default:null"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (r *CommunityEventRSVP) TableName() string {
+	return "community_event_rsvps"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommunityFlair is a label moderators define for the posts of a community. Colours are
+// hex strings such as "#1d9bf0".
+type CommunityFlair struct {
+	gorm.Model
+	ID              int       `gorm:"primary_key;column:id"`
+	CommunityID     int       `gorm:"column:community_id;uniqueIndex:idx_community_flairs_community_name,priority:1"`
+	Name            string    `gorm:"column:name;uniqueIndex:idx_community_flairs_community_name,priority:2"`
+	BackgroundColor string    `gorm:"column:background_color"`
+	TextColor       string    `gorm:"column:text_color"`
+	Position        int       `gorm:"column:position"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (c *CommunityFlair) TableName() string {
+	return "community_flairs"
+}
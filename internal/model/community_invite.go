@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunityInvite lets users join a community without a join request. An invite link can be
+// used by anyone holding its code; a direct invite is bound to InvitedUserID. MaxUses of 0
+// means unlimited and a nil ExpiresAt never expires.
+type CommunityInvite struct {
+	ID            int        `gorm:"primary_key;column:id"`
+	CommunityID   int        `gorm:"column:community_id;index"`
+	Code          string     `gorm:"column:code;uniqueIndex"`
+	CreatedBy     int        `gorm:"column:created_by"`
+	InvitedUserID *int       `gorm:"column:invited_user_id;default:null;index"`
+	MaxUses       int        `gorm:"column:max_uses;default:0"`
+	Uses          int        `gorm:"column:uses;default:0"`
+	ExpiresAt     *time.Time `gorm:"column:expires_at;default:null"`
+	RevokedAt     *time.Time `gorm:"column:revoked_at;default:null"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (i *CommunityInvite) TableName() string {
+	return "community_invites"
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunityJoinRequest asks to join a restricted or private community. A user has at most
+// one pending request per community; moderators approve or reject it.
+type CommunityJoinRequest struct {
+	ID          int        `gorm:"primary_key;column:id"`
+	CommunityID int        `gorm:"column:community_id;uniqueIndex:idx_community_join_requests_pending,where:status = 'pending'"`
+	UserID      int        `gorm:"column:user_id;uniqueIndex:idx_community_join_requests_pending;index"`
+	Message     string     `gorm:"column:message"`
+	Status      string     `gorm:"column:status;default:pending;index"`
+	ReviewedBy  *int       `gorm:"column:reviewed_by;default:null"`
+	ReviewedAt  *time.Time `gorm:"column:reviewed_at;default:null"`
+	Reason      string     `gorm:"column:reason"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (r *CommunityJoinRequest) TableName() string {
+	return "community_join_requests"
+}
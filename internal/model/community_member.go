@@ -6,14 +6,21 @@ import (
 	"gorm.io/gorm"
 )
 
+// CommunityMember is a membership of a user in a community. A nil BannedUntil or MutedUntil
+// on a banned or muted member means the sanction is permanent. BanOnly marks a row created
+// to ban a user who never joined; it goes away when the ban is lifted.
 type CommunityMember struct {
 	gorm.Model
-	ID          int       `gorm:"primary_key;column:id"`
-	UserID      int       `gorm:"column:user_id"`
-	CommunityID int       `gorm:"column:community_id"`
-	Banned      bool      `gorm:"column:banned;default:false"`
-	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt   time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	ID          int        `gorm:"primary_key;column:id"`
+	UserID      int        `gorm:"column:user_id;uniqueIndex:idx_community_members_community_user,priority:2"`
+	CommunityID int        `gorm:"column:community_id;uniqueIndex:idx_community_members_community_user,priority:1"`
+	Banned      bool       `gorm:"column:banned;default:false"`
+	BannedUntil *time.Time `gorm:"column:banned_until;default:null"`
+	BanOnly     bool       `gorm:"column:ban_only;default:false"`
+	Muted       bool       `gorm:"column:muted;default:false"`
+	MutedUntil  *time.Time `gorm:"column:muted_until;default:null"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 }
 
 func (c *CommunityMember) TableName() string {
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunityMembershipLog records a user joining or leaving a community. Memberships are
+// deleted when a user leaves, so this is the only trace of the leave.
+type CommunityMembershipLog struct {
+	ID          int       `gorm:"primary_key;column:id"`
+	CommunityID int       `gorm:"column:community_id;index:idx_community_membership_logs_community_created,priority:1"`
+	UserID      int       `gorm:"column:user_id"`
+	Action      string    `gorm:"column:action"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;index:idx_community_membership_logs_community_created,priority:2;index"`
+}
+
+func (l *CommunityMembershipLog) TableName() string {
+	return "community_membership_logs"
+}
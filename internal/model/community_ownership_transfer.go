@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunityOwnershipTransfer offers the ownership of a community to another member, who has
+// to accept it before ExpiresAt. A community has at most one pending transfer.
+type CommunityOwnershipTransfer struct {
+	ID          int        `gorm:"primary_key;column:id"`
+	CommunityID int        `gorm:"column:community_id;uniqueIndex:idx_community_ownership_transfers_pending,where:status = 'pending'"`
+	FromUserID  int        `gorm:"column:from_user_id"`
+	ToUserID    int        `gorm:"column:to_user_id;index"`
+	Status      string     `gorm:"column:status;default:pending"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at"`
+	RespondedAt *time.Time `gorm:"column:responded_at;default:null"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (t *CommunityOwnershipTransfer) TableName() string {
+	return "community_ownership_transfers"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommunityRule is one of the numbered rules of a community. AppliesTo says whether it
+// governs posts, comments or both, which decides what a report or removal may cite it for.
+type CommunityRule struct {
+	gorm.Model
+	ID          int       `gorm:"primary_key;column:id"`
+	CommunityID int       `gorm:"column:community_id;index"`
+	Position    int       `gorm:"column:position"`
+	Title       string    `gorm:"column:title"`
+	Description string    `gorm:"column:description;type:text"`
+	AppliesTo   string    `gorm:"column:applies_to;default:both"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (c *CommunityRule) TableName() string {
+	return "community_rules"
+}
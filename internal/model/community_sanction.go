@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunitySanction records a ban, mute, unban or unmute of a user in a community.
+// ModeratorID is 0 when a timed sanction expired on its own.
+type CommunitySanction struct {
+	ID          int        `gorm:"primary_key;column:id"`
+	CommunityID int        `gorm:"column:community_id;index"`
+	UserID      int        `gorm:"column:user_id;index"`
+	ModeratorID int        `gorm:"column:moderator_id"`
+	Action      string     `gorm:"column:action"`
+	Reason      string     `gorm:"column:reason"`
+	ExpiresAt   *time.Time `gorm:"column:expires_at;default:null"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (s *CommunitySanction) TableName() string {
+	return "community_sanctions"
+}
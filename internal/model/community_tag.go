@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunityTag is a free-form discovery tag of a community, normalised like post hashtags.
+type CommunityTag struct {
+	ID          int       `gorm:"primary_key;column:id"`
+	CommunityID int       `gorm:"column:community_id;uniqueIndex:idx_community_tags_community_name,priority:1"`
+	Name        string    `gorm:"column:name;uniqueIndex:idx_community_tags_community_name,priority:2;index"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (t *CommunityTag) TableName() string {
+	return "community_tags"
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommunityWikiPage is the current version of a wiki page. Path is unique among the live
+// pages of a community; Revision is the number of its latest CommunityWikiRevision.
+type CommunityWikiPage struct {
+	gorm.Model
+	ID             int       `gorm:"primary_key;column:id"`
+	CommunityID    int       `gorm:"column:community_id;uniqueIndex:idx_community_wiki_pages_community_path,where:deleted_at IS NULL"`
+	Path           string    `gorm:"column:path;uniqueIndex:idx_community_wiki_pages_community_path,where:deleted_at IS NULL"`
+	Title          string    `gorm:"column:title"`
+	Content        string    `gorm:"column:content;type:text"`
+	ContentHTML    string    `gorm:"column:content_html;type:text"`
+	EditPermission string    `gorm:"column:edit_permission;default:members"`
+	Revision       int       `gorm:"column:revision;default:1"`
+	CreatedByID    int       `gorm:"column:created_by_id"`
+	LastEditorID   int       `gorm:"column:last_editor_id"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (p *CommunityWikiPage) TableName() string {
+	return "community_wiki_pages"
+}
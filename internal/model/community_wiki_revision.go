@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// CommunityWikiRevision is a full snapshot of a wiki page written on every save. Action is
+// "create", "edit" or "revert"; RevertedFrom is the revision a revert restored.
+type CommunityWikiRevision struct {
+	ID           int       `gorm:"primary_key;column:id"`
+	PageID       int       `gorm:"column:page_id;uniqueIndex:idx_community_wiki_revisions_page_revision,priority:1"`
+	CommunityID  int       `gorm:"column:community_id;index"`
+	Revision     int       `gorm:"column:revision;uniqueIndex:idx_community_wiki_revisions_page_revision,priority:2"`
+	EditorID     int       `gorm:"column:editor_id"`
+	Action       string    `gorm:"column:action"`
+	Title        string    `gorm:"column:title"`
+	Content      string    `gorm:"column:content;type:text"`
+	Summary      string    `gorm:"column:summary"`
+	RevertedFrom *int      `gorm:"column:reverted_from;default:null"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (r *CommunityWikiRevision) TableName() string {
+	return "community_wiki_revisions"
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type LinkPreview struct {
+	ID          int        `gorm:"primary_key;column:id"`
+	PostID      int        `gorm:"column:post_id;uniqueIndex"`
+	URL         string     `gorm:"column:url"`
+	Title       string     `gorm:"column:title"`
+	Description string     `gorm:"column:description"`
+	ImageURL    string     `gorm:"column:image_url"`
+	SiteName    string     `gorm:"column:site_name"`
+	Status      string     `gorm:"column:status;default:pending;index"`
+	Attempts    int        `gorm:"column:attempts;default:0"`
+	FetchedAt   *time.Time `gorm:"column:fetched_at;default:null"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (l *LinkPreview) TableName() string {
+	return "link_previews"
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+type Mention struct {
+	ID              int       `gorm:"primary_key;column:id"`
+	SourceType      string    `gorm:"column:source_type;uniqueIndex:idx_mentions_source_user"`
+	SourceID        int       `gorm:"column:source_id;uniqueIndex:idx_mentions_source_user"`
+	MentionedUserID int       `gorm:"column:mentioned_user_id;uniqueIndex:idx_mentions_source_user;index"`
+	ActorID         int       `gorm:"column:actor_id"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (m *Mention) TableName() string {
+	return "mentions"
+}
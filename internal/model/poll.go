@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Poll struct {
+	gorm.Model
+	ID                int          `gorm:"primary_key;column:id"`
+	PostID            int          `gorm:"column:post_id;uniqueIndex"`
+	MultipleChoice    bool         `gorm:"column:multiple_choice;default:false"`
+	Anonymous         bool         `gorm:"column:anonymous;default:false"`
+	ResultsVisibility string       `gorm:"column:results_visibility;default:always"`
+	ClosesAt          *time.Time   `gorm:"column:closes_at;default:null"`
+	VotersCount       int          `gorm:"column:voters_count;default:0"`
+	Options           []PollOption `gorm:"foreignKey:PollID"`
+	CreatedAt         time.Time    `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt         time.Time    `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (p *Poll) TableName() string {
+	return "polls"
+}
+
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosesAt != nil && !now.Before(*p.ClosesAt)
+}
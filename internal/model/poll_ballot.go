@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PollBallot is the single ballot a user casts in a poll; the unique index is what
+// enforces one vote per user, while Votes holds the chosen option(s).
+type PollBallot struct {
+	gorm.Model
+	ID        int        `gorm:"primary_key;column:id"`
+	PollID    int        `gorm:"column:poll_id;uniqueIndex:idx_poll_ballots_poll_user"`
+	UserID    int        `gorm:"column:user_id;uniqueIndex:idx_poll_ballots_poll_user"`
+	Votes     []PollVote `gorm:"foreignKey:BallotID"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (p *PollBallot) TableName() string {
+	return "poll_ballots"
+}
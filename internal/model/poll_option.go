@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PollOption struct {
+	gorm.Model
+	ID         int       `gorm:"primary_key;column:id"`
+	PollID     int       `gorm:"column:poll_id;index"`
+	Text       string    `gorm:"column:text"`
+	Position   int       `gorm:"column:position"`
+	VotesCount int       `gorm:"column:votes_count;default:0"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (p *PollOption) TableName() string {
+	return "poll_options"
+}
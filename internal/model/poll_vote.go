@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PollVote struct {
+	gorm.Model
+	ID        int       `gorm:"primary_key;column:id"`
+	BallotID  int       `gorm:"column:ballot_id;uniqueIndex:idx_poll_votes_ballot_option"`
+	PollID    int       `gorm:"column:poll_id;index"`
+	OptionID  int       `gorm:"column:option_id;uniqueIndex:idx_poll_votes_ballot_option"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (p *PollVote) TableName() string {
+	return "poll_votes"
+}
@@ -8,17 +8,38 @@ import (
 
 type Post struct {
 	gorm.Model
-	ID             int             `gorm:"primary_key;column:id"`
-	UserID         int             `gorm:"column:user_id"`
-	Title          string          `gorm:"column:title"`
-	Description    string          `gorm:"column:desc"`
-	Image          string          `gorm:"column:image"`
-	Likes          []*User         `gorm:"many2many:post_likes;"`
-	Comments       []Comment       `gorm:"foreignKey:PostID"`
-	CommunityPosts []CommunityPost `gorm:"foreignKey:PostID"`
-	Notification   []Notification  `gorm:"foreignKey:PostID"`
-	CreatedAt      time.Time       `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt      time.Time       `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	ID                 int             `gorm:"primary_key;column:id"`
+	UserID             int             `gorm:"column:user_id"`
+	Title              string          `gorm:"column:title"`
+	Description        string          `gorm:"column:desc"`
+	DescriptionHTML    string          `gorm:"column:desc_html"`
+	Image              string          `gorm:"column:image"`
+	CommunityID        int             `gorm:"column:community_id"`
+	FlairID            *int            `gorm:"column:flair_id;default:null;index"`
+	Type               string          `gorm:"column:type;default:post"`
+	PinnedPosition     *int            `gorm:"column:pinned_position;default:null"`
+	PinnedAt           *time.Time      `gorm:"column:pinned_at;default:null"`
+	Flair              *CommunityFlair `gorm:"foreignKey:FlairID"`
+	Status             string          `gorm:"column:status;default:published;index"`
+	Visibility         string          `gorm:"column:visibility;default:public;index"`
+	ScheduledAt        *time.Time      `gorm:"column:scheduled_at;default:null;index"`
+	PublishedAt        *time.Time      `gorm:"column:published_at;default:null"`
+	RepostOfID         *int            `gorm:"column:repost_of_id;default:null;index"`
+	RepostOf           *Post           `gorm:"foreignKey:RepostOfID"`
+	Locked             bool            `gorm:"column:locked;default:false"`
+	CommentRestriction string          `gorm:"column:comment_restriction;default:everyone"`
+	SlowModeSeconds    int             `gorm:"column:slow_mode_seconds;default:0"`
+	SharesCount        int             `gorm:"column:shares_count;default:0"`
+	ViewsCount         int64           `gorm:"column:views_count;default:0"`
+	UniqueViewersCount int64           `gorm:"column:unique_viewers_count;default:0"`
+	ImpressionsCount   int64           `gorm:"column:impressions_count;default:0"`
+	LinkPreview        *LinkPreview    `gorm:"foreignKey:PostID"`
+	Likes              []*User         `gorm:"many2many:post_likes;"`
+	Comments           []Comment       `gorm:"foreignKey:PostID"`
+	CommunityPosts     []CommunityPost `gorm:"foreignKey:PostID"`
+	Notification       []Notification  `gorm:"foreignKey:PostID"`
+	CreatedAt          time.Time       `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time       `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 }
 
 func (p *Post) TableName() string {
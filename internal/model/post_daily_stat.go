@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type PostDailyStat struct {
+	ID            int       `gorm:"primary_key;column:id"`
+	PostID        int       `gorm:"column:post_id;uniqueIndex:idx_post_daily_stats_post_date"`
+	Date          time.Time `gorm:"column:date;type:date;uniqueIndex:idx_post_daily_stats_post_date"`
+	Views         int64     `gorm:"column:views;default:0"`
+	UniqueViewers int64     `gorm:"column:unique_viewers;default:0"`
+	Impressions   int64     `gorm:"column:impressions;default:0"`
+	Likes         int64     `gorm:"column:likes;default:0"`
+	Comments      int64     `gorm:"column:comments;default:0"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (p *PostDailyStat) TableName() string {
+	return "post_daily_stats"
+}
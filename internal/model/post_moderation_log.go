@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// PostModerationLog records a lock, an unlock, a pin, an unpin or a change of the comment
+// settings of a post.
+// Reason is free text given by the moderator; Details describes the settings after a change.
+type PostModerationLog struct {
+	ID          int       `gorm:"primary_key;column:id"`
+	PostID      int       `gorm:"column:post_id;index"`
+	ModeratorID int       `gorm:"column:moderator_id"`
+	Action      string    `gorm:"column:action"`
+	Reason      string    `gorm:"column:reason"`
+	Details     string    `gorm:"column:details"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (l *PostModerationLog) TableName() string {
+	return "post_moderation_logs"
+}
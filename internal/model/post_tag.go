@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+type PostTag struct {
+	ID        int       `gorm:"primary_key;column:id"`
+	PostID    int       `gorm:"column:post_id;uniqueIndex:idx_post_tags_post_tag"`
+	TagID     int       `gorm:"column:tag_id;uniqueIndex:idx_post_tags_post_tag;index"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;index"`
+}
+
+func (p *PostTag) TableName() string {
+	return "post_tags"
+}
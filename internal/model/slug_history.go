@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// SlugHistory remembers a slug an entity used to have, so links using it can be redirected
+// to the current one. A retired slug stays reserved for its entity.
+type SlugHistory struct {
+	ID         int       `gorm:"primary_key;column:id"`
+	EntityType string    `gorm:"column:entity_type;uniqueIndex:idx_slug_histories_entity_slug,priority:1"`
+	Slug       string    `gorm:"column:slug;uniqueIndex:idx_slug_histories_entity_slug,priority:2"`
+	EntityID   int       `gorm:"column:entity_id;index"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (h *SlugHistory) TableName() string {
+	return "slug_histories"
+}
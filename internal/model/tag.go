@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Tag struct {
+	gorm.Model
+	ID        int       `gorm:"primary_key;column:id"`
+	Name      string    `gorm:"column:name;uniqueIndex"`
+	PostTags  []PostTag `gorm:"foreignKey:TagID"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (t *Tag) TableName() string {
+	return "tags"
+}
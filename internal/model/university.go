@@ -10,7 +10,7 @@ type University struct {
 	gorm.Model
 	ID             int       `gorm:"primary_key;university_id"`
 	Name           string    `gorm:"column:name"`
-	Slug           string    `gorm:"column:slug"`
+	Slug           string    `gorm:"column:slug;uniqueIndex"`
 	Logo           string    `gorm:"column:logo"`
 	Summary        string    `gorm:"column:summary"`
 	LocationID     int       `gorm:"column:location_id"`
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+type UserBlock struct {
+	ID        int       `gorm:"primary_key;column:id"`
+	BlockerID int       `gorm:"column:blocker_id;uniqueIndex:idx_user_blocks_blocker_blocked"`
+	BlockedID int       `gorm:"column:blocked_id;uniqueIndex:idx_user_blocks_blocker_blocked;index"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (ub *UserBlock) TableName() string {
+	return "user_blocks"
+}
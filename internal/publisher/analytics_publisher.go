@@ -0,0 +1,43 @@
+package publisher
+
+import (
+	"time"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/util/queue"
+)
+
+type AnalyticsEvent struct {
+	Event      string                 `json:"event"`
+	Type       string                 `json:"type"`
+	EntityID   string                 `json:"entity_id"`
+	Data       map[string]interface{} `json:"data"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+type AnalyticsPublisher interface {
+	PublishEvent(event, entityType, entityID string, data map[string]interface{}) error
+}
+
+type analyticsPublisherImpl struct {
+	rmq queue.RabbitMQChannel
+}
+
+func NewAnalyticsPublisher(rmq queue.RabbitMQChannel) AnalyticsPublisher {
+	_ = rmq.RegisterExchange(constant.AnalyticsExchange, "direct", true, false)
+	_, _ = rmq.InitQueue(constant.AnalyticsExchange, constant.AnalyticsEventRoutingKey, true, false)
+
+	return &analyticsPublisherImpl{rmq: rmq}
+}
+
+func (p *analyticsPublisherImpl) PublishEvent(event, entityType, entityID string, data map[string]interface{}) error {
+	analyticsEvent := AnalyticsEvent{
+		Event:      event,
+		Type:       entityType,
+		EntityID:   entityID,
+		Data:       data,
+		OccurredAt: time.Now(),
+	}
+
+	return p.rmq.PublishMessage(constant.AnalyticsExchange, constant.AnalyticsEventRoutingKey, analyticsEvent)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+)
+
+type BookmarkRepository interface {
+	CreateCollection(ctx context.Context, collection *model.BookmarkCollection) error
+	GetCollectionByID(ctx context.Context, id int) (*model.BookmarkCollection, error)
+	GetCollectionsByUserID(ctx context.Context, userID int) ([]model.BookmarkCollection, error)
+	DeleteCollection(ctx context.Context, id int) error
+	CreateBookmark(ctx context.Context, bookmark *model.Bookmark) error
+	DeleteBookmark(ctx context.Context, collectionID int, targetType string, targetID int) (bool, error)
+	GetBookmarksByCollectionID(ctx context.Context, collectionID, offset, limit int) ([]model.Bookmark, int64, error)
+	TombstoneBookmarksByTarget(ctx context.Context, targetType string, targetID int) error
+}
+
+type BookmarkRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewBookmarkRepository(db database.PostgresWrapper) BookmarkRepository {
+	return &BookmarkRepositoryImpl{db: db}
+}
+
+func (r *BookmarkRepositoryImpl) CreateCollection(ctx context.Context, collection *model.BookmarkCollection) error {
+	if err := r.db.Create(ctx, collection); err != nil {
+		return fmt.Errorf("failed to create bookmark collection: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepositoryImpl) GetCollectionByID(ctx context.Context, id int) (*model.BookmarkCollection, error) {
+	var collection model.BookmarkCollection
+
+	if err := r.db.First(ctx, &collection, id); err != nil {
+		return nil, fmt.Errorf("failed to get bookmark collection: %w", err)
+	}
+
+	return &collection, nil
+}
+
+func (r *BookmarkRepositoryImpl) GetCollectionsByUserID(ctx context.Context, userID int) ([]model.BookmarkCollection, error) {
+	var collections []model.BookmarkCollection
+
+	q := r.db.Where(ctx, "user_id = ?", userID).Order("name asc")
+	if err := q.Find(&collections).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bookmark collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+func (r *BookmarkRepositoryImpl) DeleteCollection(ctx context.Context, id int) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&model.Bookmark{}).Error; err != nil {
+			return fmt.Errorf("failed to delete bookmarks of collection: %w", err)
+		}
+		if err := tx.Delete(&model.BookmarkCollection{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete bookmark collection: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *BookmarkRepositoryImpl) CreateBookmark(ctx context.Context, bookmark *model.Bookmark) error {
+	if err := r.db.Create(ctx, bookmark); err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateRecord
+		}
+		return fmt.Errorf("failed to create bookmark: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepositoryImpl) DeleteBookmark(ctx context.Context, collectionID int, targetType string, targetID int) (bool, error) {
+	q := r.db.Where(ctx, "collection_id = ? AND target_type = ? AND target_id = ?", collectionID, targetType, targetID).
+		Delete(&model.Bookmark{})
+
+	if q.Error != nil {
+		return false, fmt.Errorf("failed to delete bookmark: %w", q.Error)
+	}
+
+	return q.RowsAffected > 0, nil
+}
+
+func (r *BookmarkRepositoryImpl) GetBookmarksByCollectionID(ctx context.Context, collectionID, offset, limit int) ([]model.Bookmark, int64, error) {
+	var bookmarks []model.Bookmark
+	var total int64
+
+	if err := r.db.Model(ctx, &model.Bookmark{}).Where("collection_id = ?", collectionID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+
+	q := r.db.Where(ctx, "collection_id = ?", collectionID).
+		Order("created_at desc").
+		Offset(offset).
+		Limit(limit)
+	if err := q.Find(&bookmarks).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+
+	return bookmarks, total, nil
+}
+
+func (r *BookmarkRepositoryImpl) TombstoneBookmarksByTarget(ctx context.Context, targetType string, targetID int) error {
+	err := r.db.Model(ctx, &model.Bookmark{}).
+		Where("target_type = ? AND target_id = ? AND target_deleted_at IS NULL", targetType, targetID).
+		Update("target_deleted_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to tombstone bookmarks: %w", err)
+	}
+	return nil
+}
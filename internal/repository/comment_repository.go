@@ -2,18 +2,26 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/model"
 	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CommentRepository interface {
 	CreateComment(ctx context.Context, comment *model.Comment) error
-	GetCommentsByPostID(ctx context.Context, postID int) ([]model.Comment, error)
-	DeleteComment(ctx context.Context, commentID int) error
-	GetRepliesByParentID(ctx context.Context, parentID int) ([]model.Comment, error)
+	GetCommentTree(ctx context.Context, postID int, parentID *int, sort string, offset, limit, maxDepth, maxNodes int) ([]CommentTreeRow, error)
+	CountReplies(ctx context.Context, postID int, parentID *int) (int64, error)
 	GetCommentDetailByID(ctx context.Context, id int) (*model.Comment, error)
+	GetCommentsByIDs(ctx context.Context, ids []int, viewerID int) ([]model.Comment, error)
+	SetCommentVote(ctx context.Context, commentID, userID, value int) (*model.Comment, error)
+	GetUserCommentVotes(ctx context.Context, userID int, commentIDs []int) (map[int]int, error)
+	ReviseComment(ctx context.Context, commentID int, updates map[string]interface{}, previous *model.CommentEdit) error
+	GetCommentEdits(ctx context.Context, commentID int) ([]model.CommentEdit, error)
 }
 
 type CommentRepositoryImpl struct {
@@ -34,33 +42,140 @@ func (r *CommentRepositoryImpl) CreateComment(ctx context.Context, comment *mode
 	return nil
 }
 
-func (r *CommentRepositoryImpl) GetCommentsByPostID(ctx context.Context, postID int) ([]model.Comment, error) {
-	var comments []model.Comment
+// Best ranks by the lower bound of the Wilson score interval of the upvote ratio at 95%
+// confidence, so a comment with few votes does not outrank a well established one.
+// Controversial favours comments with many votes that are evenly split.
+const (
+	commentWilsonScore = `CASE WHEN %[1]s.upvotes + %[1]s.downvotes = 0 THEN 0 ELSE
+		((%[1]s.upvotes + 1.9208) / (%[1]s.upvotes + %[1]s.downvotes)
+		- 1.96 * SQRT((%[1]s.upvotes * %[1]s.downvotes)::float / (%[1]s.upvotes + %[1]s.downvotes) + 0.9604)
+		/ (%[1]s.upvotes + %[1]s.downvotes))
+		/ (1 + 3.8416 / (%[1]s.upvotes + %[1]s.downvotes)) END`
+	commentControversy = `CASE WHEN %[1]s.upvotes = 0 OR %[1]s.downvotes = 0 THEN 0 ELSE
+		POWER(%[1]s.upvotes + %[1]s.downvotes,
+			LEAST(%[1]s.upvotes, %[1]s.downvotes)::float / GREATEST(%[1]s.upvotes, %[1]s.downvotes)) END`
+)
 
-	db := r.db.Where(ctx, "post_id = ?", postID)
-	if err := db.Find(&comments).Error; err != nil {
-		return nil, fmt.Errorf("failed to get comments: %w", err)
+var commentSortOrders = map[string]string{
+	constant.CommentSortBest:          commentWilsonScore + " DESC, %[1]s.created_at DESC",
+	constant.CommentSortTop:           "%[1]s.score DESC, %[1]s.created_at DESC",
+	constant.CommentSortNew:           "%[1]s.created_at DESC",
+	constant.CommentSortControversial: commentControversy + " DESC, %[1]s.created_at DESC",
+}
+
+// commentOrder returns the ORDER BY clause of sort for comment rows aliased as table.
+// The id tie-breaker makes the order total so pages and continuations never overlap.
+func commentOrder(sort, table string) string {
+	order, ok := commentSortOrders[sort]
+	if !ok {
+		order = commentSortOrders[constant.CommentSortBest]
 	}
+	return fmt.Sprintf(order+", %[1]s.id DESC", table)
+}
 
-	return comments, nil
+// CommentTreeRow is a comment loaded as part of a tree, Depth levels below the page it
+// was loaded for, with the number of its direct replies.
+type CommentTreeRow struct {
+	model.Comment
+	Depth      int `gorm:"column:depth"`
+	ReplyCount int `gorm:"column:reply_count"`
 }
 
-func (r *CommentRepositoryImpl) DeleteComment(ctx context.Context, commentID int) error {
-	if err := r.db.Delete(ctx, &model.Comment{}, commentID); err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
+// GetCommentTree loads a page of the comments directly under parentID (top-level comments
+// when nil) together with their replies up to maxDepth levels down, in one recursive query.
+// Each comment contributes at most commentTreeFanOut replies to the recursion, so maxNodes
+// bounds the rows read as well as the rows returned. Rows are ordered by depth and then by
+// sort; since the order is total, the replies loaded for any comment are always a prefix of
+// all its replies.
+func (r *CommentRepositoryImpl) GetCommentTree(ctx context.Context, postID int, parentID *int, sort string, offset, limit, maxDepth, maxNodes int) ([]CommentTreeRow, error) {
+	var rows []CommentTreeRow
+
+	parentFilter := "comments.parent_id IS NULL"
+	if parentID != nil {
+		parentFilter = "comments.parent_id = @parent"
 	}
-	return nil
+
+	rawQuery := `
+		WITH RECURSIVE tree AS (
+			SELECT roots.*, 0 AS depth FROM (
+				SELECT comments.* FROM comments
+				WHERE comments.post_id = @post AND ` + parentFilter + ` AND comments.deleted_at IS NULL
+				ORDER BY ` + commentOrder(sort, "comments") + `
+				LIMIT @limit OFFSET @offset
+			) roots
+			UNION ALL
+			SELECT replies.*, tree.depth + 1 FROM tree
+			CROSS JOIN LATERAL (
+				SELECT comments.* FROM comments
+				WHERE comments.parent_id = tree.id AND comments.deleted_at IS NULL
+				ORDER BY ` + commentOrder(sort, "comments") + `
+				LIMIT @fan_out
+			) replies
+			WHERE tree.depth < @max_depth
+		)
+		SELECT tree.*,
+			(SELECT COUNT(*) FROM comments c WHERE c.parent_id = tree.id AND c.deleted_at IS NULL) AS reply_count
+		FROM tree
+		ORDER BY tree.depth, ` + commentOrder(sort, "tree") + `
+		LIMIT @max_nodes`
+
+	args := map[string]interface{}{
+		"post":      postID,
+		"parent":    parentID,
+		"limit":     limit,
+		"offset":    offset,
+		"max_depth": maxDepth,
+		"max_nodes": maxNodes,
+		"fan_out":   commentTreeFanOut(limit, maxDepth, maxNodes),
+	}
+
+	if err := r.db.DB.WithContext(ctx).Raw(rawQuery, args).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comment tree: %w", err)
+	}
+
+	return rows, nil
 }
 
-func (r *CommentRepositoryImpl) GetRepliesByParentID(ctx context.Context, parentID int) ([]model.Comment, error) {
-	var replies []model.Comment
+// commentTreeFanOut is the largest number of replies per comment for which a page of limit
+// comments with maxDepth levels of replies below it still fits in maxNodes rows. Replies
+// past it are reached through continuation cursors.
+func commentTreeFanOut(limit, maxDepth, maxNodes int) int {
+	fits := func(fanOut int) bool {
+		nodes, level := 0, limit
+		for depth := 0; depth <= maxDepth; depth++ {
+			nodes += level
+			if nodes > maxNodes {
+				return false
+			}
+			level *= fanOut
+		}
+		return true
+	}
 
-	db := r.db.Where(ctx, "parent_id = ?", parentID)
-	if err := db.Find(&replies).Error; err != nil {
-		return nil, fmt.Errorf("failed to get replies: %w", err)
+	fanOut := 1
+	for fanOut < maxNodes && fits(fanOut+1) {
+		fanOut++
 	}
+	return fanOut
+}
+
+// CountReplies counts the comments directly under parentID, or the top-level comments of
+// the post when parentID is nil.
+func (r *CommentRepositoryImpl) CountReplies(ctx context.Context, postID int, parentID *int) (int64, error) {
+	var count int64
 
-	return replies, nil
+	q := r.db.Model(ctx, &model.Comment{}).Where("post_id = ?", postID)
+	if parentID != nil {
+		q = q.Where("parent_id = ?", *parentID)
+	} else {
+		q = q.Where("parent_id IS NULL")
+	}
+
+	if err := q.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	return count, nil
 }
 
 func (r *CommentRepositoryImpl) GetCommentDetailByID(ctx context.Context, id int) (*model.Comment, error) {
@@ -72,3 +187,141 @@ func (r *CommentRepositoryImpl) GetCommentDetailByID(ctx context.Context, id int
 
 	return &comment, nil
 }
+
+// GetCommentsByIDs only returns comments on posts viewerID is allowed to see.
+func (r *CommentRepositoryImpl) GetCommentsByIDs(ctx context.Context, ids []int, viewerID int) ([]model.Comment, error) {
+	var comments []model.Comment
+
+	if len(ids) == 0 {
+		return comments, nil
+	}
+
+	q := r.db.Where(ctx, "comments.id IN ?", ids).
+		Joins("INNER JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Scopes(visiblePostsTo(viewerID))
+
+	if err := q.Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comments by ids: %w", err)
+	}
+
+	return comments, nil
+}
+
+// SetCommentVote records the vote of userID on a comment, replacing an earlier one; a value
+// of 0 retracts it. The comment row is locked so concurrent votes keep the counters exact.
+func (r *CommentRepositoryImpl) SetCommentVote(ctx context.Context, commentID, userID, value int) (*model.Comment, error) {
+	var comment model.Comment
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&comment, commentID).Error; err != nil {
+			return err
+		}
+
+		var existing model.CommentVote
+		previous := constant.CommentVoteNone
+		err := tx.Where("comment_id = ? AND user_id = ?", commentID, userID).First(&existing).Error
+		switch {
+		case err == nil:
+			previous = existing.Value
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		if previous == value {
+			return nil
+		}
+
+		switch {
+		case value == constant.CommentVoteNone:
+			err = tx.Where("comment_id = ? AND user_id = ?", commentID, userID).Delete(&model.CommentVote{}).Error
+		case previous == constant.CommentVoteNone:
+			err = tx.Create(&model.CommentVote{CommentID: commentID, UserID: userID, Value: value}).Error
+		default:
+			err = tx.Model(&model.CommentVote{}).
+				Where("comment_id = ? AND user_id = ?", commentID, userID).
+				Update("value", value).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		upDelta := countVote(value, constant.CommentVoteUp) - countVote(previous, constant.CommentVoteUp)
+		downDelta := countVote(value, constant.CommentVoteDown) - countVote(previous, constant.CommentVoteDown)
+
+		if err := tx.Model(&model.Comment{}).Where("id = ?", commentID).Updates(map[string]interface{}{
+			"upvotes":   gorm.Expr("upvotes + ?", upDelta),
+			"downvotes": gorm.Expr("downvotes + ?", downDelta),
+			"score":     gorm.Expr("score + ?", upDelta-downDelta),
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.First(&comment, commentID).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set comment vote: %w", err)
+	}
+
+	return &comment, nil
+}
+
+func countVote(vote, kind int) int {
+	if vote == kind {
+		return 1
+	}
+	return 0
+}
+
+// GetUserCommentVotes maps each of commentIDs userID has voted on to the vote value.
+func (r *CommentRepositoryImpl) GetUserCommentVotes(ctx context.Context, userID int, commentIDs []int) (map[int]int, error) {
+	votes := make(map[int]int)
+
+	if userID == 0 || len(commentIDs) == 0 {
+		return votes, nil
+	}
+
+	var rows []model.CommentVote
+	if err := r.db.Where(ctx, "user_id = ? AND comment_id IN ?", userID, commentIDs).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comment votes: %w", err)
+	}
+
+	for _, v := range rows {
+		votes[v.CommentID] = v.Value
+	}
+
+	return votes, nil
+}
+
+// ReviseComment applies updates to an active comment and stores its previous content in
+// the same transaction. ErrStaleRecord means the comment was tombstoned in the meantime.
+func (r *CommentRepositoryImpl) ReviseComment(ctx context.Context, commentID int, updates map[string]interface{}, previous *model.CommentEdit) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(&model.Comment{}).
+			Where("id = ? AND status = ?", commentID, constant.CommentStatusActive).
+			Updates(updates)
+		if res.Error != nil {
+			return fmt.Errorf("failed to update comment: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleRecord
+		}
+
+		if err := tx.Create(previous).Error; err != nil {
+			return fmt.Errorf("failed to create comment edit: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *CommentRepositoryImpl) GetCommentEdits(ctx context.Context, commentID int) ([]model.CommentEdit, error) {
+	var edits []model.CommentEdit
+
+	if err := r.db.Where(ctx, "comment_id = ?", commentID).
+		Order("created_at DESC, id DESC").
+		Find(&edits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comment edits: %w", err)
+	}
+
+	return edits, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+)
+
+type CommunityAnalyticsRepository interface {
+	RollupDay(ctx context.Context, day time.Time) error
+	GetDailyStats(ctx context.Context, communityID int, from, to time.Time) ([]model.CommunityDailyStat, error)
+}
+
+type CommunityAnalyticsRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewCommunityAnalyticsRepository(db database.PostgresWrapper) CommunityAnalyticsRepository {
+	return &CommunityAnalyticsRepositoryImpl{
+		db: db,
+	}
+}
+
+// communityRollupQuery computes the activity of every community on the day starting at
+// @start. Posts count when they are published; posts, comments and reports count even when
+// they were deleted since, so a day does not change once it is over. Likes come from the
+// per-post daily stats, and active users are the distinct authors of posts and comments.
+const communityRollupQuery = `
+	WITH memberships AS (
+		SELECT community_id,
+			COUNT(*) FILTER (WHERE action = @joined) AS new_members,
+			COUNT(*) FILTER (WHERE action = @left) AS leaves
+		FROM community_membership_logs
+		WHERE created_at >= @start AND created_at < @end
+		GROUP BY community_id
+	), day_posts AS (
+		SELECT community_id, user_id
+		FROM posts
+		WHERE community_id > 0 AND status = @published
+			AND COALESCE(published_at, created_at) >= @start AND COALESCE(published_at, created_at) < @end
+	), day_comments AS (
+		SELECT p.community_id, cm.user_id
+		FROM comments cm
+		INNER JOIN posts p ON p.id = cm.post_id
+		WHERE p.community_id > 0 AND cm.created_at >= @start AND cm.created_at < @end
+	), likes AS (
+		SELECT p.community_id, SUM(s.likes) AS likes
+		FROM post_daily_stats s
+		INNER JOIN posts p ON p.id = s.post_id
+		WHERE p.community_id > 0 AND s.date = @day
+		GROUP BY p.community_id
+	), reports AS (
+		SELECT p.community_id, COUNT(*) AS reports
+		FROM reports r
+		LEFT JOIN comments cm ON cm.id = r.comment_id
+		INNER JOIN posts p ON p.id = COALESCE(r.post_id, cm.post_id)
+		WHERE p.community_id > 0 AND r.created_at >= @start AND r.created_at < @end
+		GROUP BY p.community_id
+	), activity AS (
+		SELECT community_id,
+			COUNT(*) FILTER (WHERE is_post) AS posts,
+			COUNT(*) FILTER (WHERE NOT is_post) AS comments,
+			COUNT(DISTINCT user_id) AS active_users
+		FROM (
+			SELECT community_id, user_id, TRUE AS is_post FROM day_posts
+			UNION ALL
+			SELECT community_id, user_id, FALSE AS is_post FROM day_comments
+		) a
+		GROUP BY community_id
+	), active_communities AS (
+		SELECT community_id FROM memberships
+		UNION SELECT community_id FROM activity
+		UNION SELECT community_id FROM likes
+		UNION SELECT community_id FROM reports
+	)
+	INSERT INTO community_daily_stats
+		(community_id, date, new_members, leaves, posts, comments, likes, active_users, reports, created_at, updated_at)
+	SELECT ac.community_id, @day,
+		COALESCE(m.new_members, 0), COALESCE(m.leaves, 0),
+		COALESCE(a.posts, 0), COALESCE(a.comments, 0), COALESCE(l.likes, 0),
+		COALESCE(a.active_users, 0), COALESCE(r.reports, 0),
+		NOW(), NOW()
+	FROM active_communities ac
+	INNER JOIN communities c ON c.id = ac.community_id AND c.deleted_at IS NULL
+	LEFT JOIN memberships m ON m.community_id = ac.community_id
+	LEFT JOIN activity a ON a.community_id = ac.community_id
+	LEFT JOIN likes l ON l.community_id = ac.community_id
+	LEFT JOIN reports r ON r.community_id = ac.community_id`
+
+// RollupDay recomputes the daily stats of every community for the UTC day starting at day.
+// Rows of that day are replaced as a whole, so rerunning it is safe.
+func (r *CommunityAnalyticsRepositoryImpl) RollupDay(ctx context.Context, day time.Time) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Where("date = ?", day).Delete(&model.CommunityDailyStat{}).Error; err != nil {
+			return fmt.Errorf("failed to clear community daily stats: %w", err)
+		}
+
+		err := tx.Exec(communityRollupQuery, map[string]interface{}{
+			"day":       day,
+			"start":     day,
+			"end":       day.AddDate(0, 0, 1),
+			"joined":    constant.CommunityMembershipJoined,
+			"left":      constant.CommunityMembershipLeft,
+			"published": constant.PostStatusPublished,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to roll up community daily stats: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetDailyStats returns the stats of a community between from and to inclusive. Days
+// without any activity have no row.
+func (r *CommunityAnalyticsRepositoryImpl) GetDailyStats(ctx context.Context, communityID int, from, to time.Time) ([]model.CommunityDailyStat, error) {
+	var stats []model.CommunityDailyStat
+
+	q := r.db.Where(ctx, "community_id = ? AND date BETWEEN ? AND ?", communityID, from, to).
+		Order("date asc")
+
+	if err := q.Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get community daily stats: %w", err)
+	}
+
+	return stats, nil
+}
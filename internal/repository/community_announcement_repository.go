@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommunityAnnouncementRepository interface {
+	CreateAnnouncement(ctx context.Context, announcement *model.CommunityAnnouncement) error
+	GetPendingAnnouncements(ctx context.Context, limit int) ([]model.CommunityAnnouncement, error)
+	DeliverAnnouncementBatch(ctx context.Context, announcementID int, template model.Notification, batchSize int) (bool, error)
+}
+
+type CommunityAnnouncementRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewCommunityAnnouncementRepository(db database.PostgresWrapper) CommunityAnnouncementRepository {
+	return &CommunityAnnouncementRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *CommunityAnnouncementRepositoryImpl) CreateAnnouncement(ctx context.Context, announcement *model.CommunityAnnouncement) error {
+	if err := r.db.Create(ctx, announcement); err != nil {
+		return fmt.Errorf("failed to create community announcement: %w", err)
+	}
+	return nil
+}
+
+// GetPendingAnnouncements returns the announcements still being delivered, oldest first.
+func (r *CommunityAnnouncementRepositoryImpl) GetPendingAnnouncements(ctx context.Context, limit int) ([]model.CommunityAnnouncement, error) {
+	var announcements []model.CommunityAnnouncement
+
+	err := r.db.Where(ctx, "status = ?", constant.CommunityAnnouncementStatusPending).
+		Order("id asc").
+		Limit(limit).
+		Find(&announcements).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending community announcements: %w", err)
+	}
+
+	return announcements, nil
+}
+
+// DeliverAnnouncementBatch notifies the next batchSize members of the announcement's
+// community, copying template for each of them, and moves the cursor past them in the same
+// transaction so no member is notified twice. Banned users and the author are skipped. It
+// reports true once every member has been notified.
+func (r *CommunityAnnouncementRepositoryImpl) DeliverAnnouncementBatch(ctx context.Context, announcementID int, template model.Notification, batchSize int) (bool, error) {
+	done := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var announcement model.CommunityAnnouncement
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&announcement, announcementID).Error; err != nil {
+			return fmt.Errorf("failed to get community announcement: %w", err)
+		}
+		if announcement.Status != constant.CommunityAnnouncementStatusPending {
+			done = true
+			return nil
+		}
+
+		var members []model.CommunityMember
+		if err := tx.Select("id", "user_id").
+			Where("community_id = ? AND id > ? AND banned = false", announcement.CommunityID, announcement.LastMemberID).
+			Order("id asc").
+			Limit(batchSize).
+			Find(&members).Error; err != nil {
+			return fmt.Errorf("failed to get community members: %w", err)
+		}
+
+		notifications := make([]model.Notification, 0, len(members))
+		for _, member := range members {
+			if member.UserID == announcement.AuthorID {
+				continue
+			}
+			notification := template
+			notification.UserID = member.UserID
+			notifications = append(notifications, notification)
+		}
+		if len(notifications) > 0 {
+			if err := tx.Create(&notifications).Error; err != nil {
+				return fmt.Errorf("failed to create announcement notifications: %w", err)
+			}
+		}
+
+		updates := map[string]interface{}{
+			"delivered_count": gorm.Expr("delivered_count + ?", len(notifications)),
+		}
+		if len(members) > 0 {
+			updates["last_member_id"] = members[len(members)-1].ID
+		}
+		if len(members) < batchSize {
+			updates["status"] = constant.CommunityAnnouncementStatusCompleted
+			updates["completed_at"] = time.Now()
+			done = true
+		}
+
+		if err := tx.Model(&announcement).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update community announcement: %w", err)
+		}
+		return nil
+	})
+
+	return done, err
+}
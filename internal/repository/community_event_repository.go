@@ -0,0 +1,454 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// communityEventNotificationBatchSize bounds the notifications inserted per statement when
+// every attendee of an event is notified.
+const communityEventNotificationBatchSize = 500
+
+// CommunityEventAttendeeRow is an RSVP with the profile of the user who answered.
+type CommunityEventAttendeeRow struct {
+	model.CommunityEventRSVP
+	Username       string `gorm:"column:username"`
+	Displayname    string `gorm:"column:displayname"`
+	ProfilePicture string `gorm:"column:profile_picture"`
+}
+
+type CommunityEventRepository interface {
+	CreateEvent(ctx context.Context, event *model.CommunityEvent) error
+	GetEventByID(ctx context.Context, id int) (*model.CommunityEvent, error)
+	UpdateEvent(ctx context.Context, event *model.CommunityEvent) ([]int, error)
+	CancelEvent(ctx context.Context, id int) (bool, error)
+	GetCommunityEvents(ctx context.Context, communityID int, from, to time.Time, offset, limit int) ([]model.CommunityEvent, int64, error)
+	GetUserCalendarEvents(ctx context.Context, userID int, since time.Time, limit int) ([]model.CommunityEvent, error)
+	GetRSVP(ctx context.Context, eventID, userID int) (*model.CommunityEventRSVP, error)
+	SetRSVP(ctx context.Context, eventID, userID int, status string) (*model.CommunityEventRSVP, []int, error)
+	GetAttendees(ctx context.Context, eventID int, status string, offset, limit int) ([]CommunityEventAttendeeRow, int64, error)
+	NotifyAttendees(ctx context.Context, eventID int, statuses []string, template model.Notification) error
+	GetEventsDueForReminder(ctx context.Context, until time.Time, limit int) ([]model.CommunityEvent, error)
+	SendEventReminder(ctx context.Context, eventID int, template model.Notification) (bool, error)
+	GetCalendarToken(ctx context.Context, userID int) (*model.CalendarToken, error)
+	SaveCalendarToken(ctx context.Context, userID int, token string) error
+	GetUserIDByCalendarToken(ctx context.Context, token string) (int, error)
+}
+
+type CommunityEventRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewCommunityEventRepository(db database.PostgresWrapper) CommunityEventRepository {
+	return &CommunityEventRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *CommunityEventRepositoryImpl) CreateEvent(ctx context.Context, event *model.CommunityEvent) error {
+	if err := r.db.Create(ctx, event); err != nil {
+		return fmt.Errorf("failed to create community event: %w", err)
+	}
+	return nil
+}
+
+func (r *CommunityEventRepositoryImpl) GetEventByID(ctx context.Context, id int) (*model.CommunityEvent, error) {
+	var event model.CommunityEvent
+	if err := r.db.First(ctx, &event, id); err != nil {
+		return nil, fmt.Errorf("failed to get community event: %w", err)
+	}
+	return &event, nil
+}
+
+// UpdateEvent saves the details of a scheduled event and bumps its sequence. Moving the
+// start re-arms the reminder, and raising the capacity moves waitlisted users up; their IDs
+// are returned. ErrStaleRecord means the event was cancelled.
+func (r *CommunityEventRepositoryImpl) UpdateEvent(ctx context.Context, event *model.CommunityEvent) ([]int, error) {
+	var promoted []int
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		existing, err := lockCommunityEvent(tx, event.ID)
+		if err != nil {
+			return err
+		}
+		if existing.Status != constant.CommunityEventStatusScheduled {
+			return ErrStaleRecord
+		}
+
+		updates := map[string]interface{}{
+			"title":       event.Title,
+			"description": event.Description,
+			"starts_at":   event.StartsAt,
+			"ends_at":     event.EndsAt,
+			"time_zone":   event.TimeZone,
+			"online_url":  event.OnlineURL,
+			"location":    event.Location,
+			"capacity":    event.Capacity,
+			"sequence":    existing.Sequence + 1,
+		}
+		if !event.StartsAt.Equal(existing.StartsAt) {
+			updates["reminded_at"] = nil
+		}
+		if err := tx.Model(existing).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update community event: %w", err)
+		}
+
+		existing.Capacity = event.Capacity
+		if promoted, err = promoteWaitlist(tx, existing); err != nil {
+			return err
+		}
+		return saveRSVPCounts(tx, existing)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return promoted, nil
+}
+
+// CancelEvent cancels a scheduled event. It reports false when the event was not scheduled.
+func (r *CommunityEventRepositoryImpl) CancelEvent(ctx context.Context, id int) (bool, error) {
+	res := r.db.Model(ctx, &model.CommunityEvent{}).
+		Where("id = ? AND status = ?", id, constant.CommunityEventStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":   constant.CommunityEventStatusCancelled,
+			"sequence": gorm.Expr("sequence + 1"),
+		})
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to cancel community event: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// GetCommunityEvents lists the events of a community that have not ended by from and start
+// before to, soonest first. A zero to means no upper bound.
+func (r *CommunityEventRepositoryImpl) GetCommunityEvents(ctx context.Context, communityID int, from, to time.Time, offset, limit int) ([]model.CommunityEvent, int64, error) {
+	var events []model.CommunityEvent
+	var total int64
+
+	query := func() *gorm.DB {
+		q := r.db.Model(ctx, &model.CommunityEvent{}).
+			Where("community_id = ? AND ends_at >= ?", communityID, from)
+		if !to.IsZero() {
+			q = q.Where("starts_at < ?", to)
+		}
+		return q
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count community events: %w", err)
+	}
+
+	if err := query().Order("starts_at asc, id asc").Offset(offset).Limit(limit).Find(&events).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get community events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// GetUserCalendarEvents lists the events a user is going to, might go to or is waitlisted
+// for that ended after since, in communities that still exist.
+func (r *CommunityEventRepositoryImpl) GetUserCalendarEvents(ctx context.Context, userID int, since time.Time, limit int) ([]model.CommunityEvent, error) {
+	var events []model.CommunityEvent
+
+	err := r.db.Model(ctx, &model.CommunityEvent{}).
+		Joins("INNER JOIN community_event_rsvps rsvp ON rsvp.event_id = community_events.id").
+		Joins("INNER JOIN communities c ON c.id = community_events.community_id AND c.deleted_at IS NULL").
+		Where("rsvp.user_id = ? AND rsvp.status IN ? AND community_events.ends_at >= ?", userID,
+			[]string{constant.CommunityEventRSVPGoing, constant.CommunityEventRSVPMaybe, constant.CommunityEventRSVPWaitlisted}, since).
+		Order("community_events.starts_at asc").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user calendar events: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetRSVP returns the answer of a user to an event, or nil when they have not answered.
+func (r *CommunityEventRepositoryImpl) GetRSVP(ctx context.Context, eventID, userID int) (*model.CommunityEventRSVP, error) {
+	var rsvp model.CommunityEventRSVP
+
+	res := r.db.Where(ctx, "event_id = ? AND user_id = ?", eventID, userID).Limit(1).Find(&rsvp)
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to get event rsvp: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &rsvp, nil
+}
+
+// SetRSVP records the answer of a user to an event. Going to a full event puts the user on
+// the waitlist; a going user dropping out frees a place for the first waitlisted one. It
+// returns the resulting RSVP and the IDs of the users moved up from the waitlist.
+// ErrStaleRecord means the event was cancelled or is over.
+func (r *CommunityEventRepositoryImpl) SetRSVP(ctx context.Context, eventID, userID int, status string) (*model.CommunityEventRSVP, []int, error) {
+	var rsvp model.CommunityEventRSVP
+	var promoted []int
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		event, err := lockCommunityEvent(tx, eventID)
+		if err != nil {
+			return err
+		}
+		if event.Status != constant.CommunityEventStatusScheduled || !event.EndsAt.After(time.Now()) {
+			return ErrStaleRecord
+		}
+
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("event_id = ? AND user_id = ?", eventID, userID).
+			Limit(1).Find(&rsvp)
+		if res.Error != nil {
+			return fmt.Errorf("failed to get event rsvp: %w", res.Error)
+		}
+		previous := ""
+		if res.RowsAffected > 0 {
+			previous = rsvp.Status
+		}
+
+		next := status
+		if status == constant.CommunityEventRSVPGoing && previous != constant.CommunityEventRSVPGoing &&
+			event.Capacity > 0 && event.GoingCount >= event.Capacity {
+			next = constant.CommunityEventRSVPWaitlisted
+		}
+		if next == previous {
+			return nil
+		}
+
+		adjustRSVPCount(event, previous, -1)
+		adjustRSVPCount(event, next, 1)
+
+		rsvp.EventID, rsvp.UserID, rsvp.Status = eventID, userID, next
+		rsvp.WaitlistedAt = nil
+		if next == constant.CommunityEventRSVPWaitlisted {
+			now := time.Now()
+			rsvp.WaitlistedAt = &now
+		}
+		if err := tx.Save(&rsvp).Error; err != nil {
+			return fmt.Errorf("failed to save event rsvp: %w", err)
+		}
+
+		if previous == constant.CommunityEventRSVPGoing {
+			if promoted, err = promoteWaitlist(tx, event); err != nil {
+				return err
+			}
+		}
+		return saveRSVPCounts(tx, event)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &rsvp, promoted, nil
+}
+
+// GetAttendees lists the users who gave an event the given answer, in the order they did;
+// the waitlist comes out in the order places are handed out.
+func (r *CommunityEventRepositoryImpl) GetAttendees(ctx context.Context, eventID int, status string, offset, limit int) ([]CommunityEventAttendeeRow, int64, error) {
+	var rows []CommunityEventAttendeeRow
+	var total int64
+
+	query := func() *gorm.DB {
+		return r.db.Model(ctx, &model.CommunityEventRSVP{}).
+			Joins("INNER JOIN users u ON u.id = community_event_rsvps.user_id AND u.deleted_at IS NULL").
+			Where("community_event_rsvps.event_id = ? AND community_event_rsvps.status = ?", eventID, status)
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count event attendees: %w", err)
+	}
+
+	err := query().
+		Select("community_event_rsvps.*, u.username, u.displayname, u.profile_picture").
+		Order("community_event_rsvps.waitlisted_at ASC NULLS LAST, community_event_rsvps.updated_at ASC, community_event_rsvps.id ASC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get event attendees: %w", err)
+	}
+
+	return rows, total, nil
+}
+
+// NotifyAttendees sends a copy of template to every user whose answer to the event is one of
+// statuses.
+func (r *CommunityEventRepositoryImpl) NotifyAttendees(ctx context.Context, eventID int, statuses []string, template model.Notification) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		return notifyEventAttendees(tx, eventID, statuses, template)
+	})
+}
+
+// GetEventsDueForReminder lists scheduled events starting between now and until whose
+// attendees have not been reminded yet.
+func (r *CommunityEventRepositoryImpl) GetEventsDueForReminder(ctx context.Context, until time.Time, limit int) ([]model.CommunityEvent, error) {
+	var events []model.CommunityEvent
+
+	err := r.db.Where(ctx, "status = ? AND reminded_at IS NULL AND starts_at > ? AND starts_at <= ?",
+		constant.CommunityEventStatusScheduled, time.Now(), until).
+		Order("starts_at asc").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events due for reminder: %w", err)
+	}
+
+	return events, nil
+}
+
+// SendEventReminder notifies the going and maybe attendees of an event and marks it as
+// reminded in the same transaction, so nobody is reminded twice. It reports false when the
+// event was already reminded or is no longer scheduled.
+func (r *CommunityEventRepositoryImpl) SendEventReminder(ctx context.Context, eventID int, template model.Notification) (bool, error) {
+	sent := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(&model.CommunityEvent{}).
+			Where("id = ? AND status = ? AND reminded_at IS NULL", eventID, constant.CommunityEventStatusScheduled).
+			Update("reminded_at", time.Now())
+		if res.Error != nil {
+			return fmt.Errorf("failed to mark event reminded: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		sent = true
+		return notifyEventAttendees(tx, eventID, []string{constant.CommunityEventRSVPGoing, constant.CommunityEventRSVPMaybe}, template)
+	})
+
+	return sent, err
+}
+
+func (r *CommunityEventRepositoryImpl) GetCalendarToken(ctx context.Context, userID int) (*model.CalendarToken, error) {
+	var token model.CalendarToken
+	if err := r.db.Where(ctx, "user_id = ?", userID).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("failed to get calendar token: %w", err)
+	}
+	return &token, nil
+}
+
+// SaveCalendarToken sets the calendar feed token of a user, replacing the previous one.
+func (r *CommunityEventRepositoryImpl) SaveCalendarToken(ctx context.Context, userID int, token string) error {
+	entry := model.CalendarToken{UserID: userID, Token: token}
+	if err := r.db.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"token": token, "updated_at": time.Now()}),
+	}).Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to save calendar token: %w", err)
+	}
+	return nil
+}
+
+func (r *CommunityEventRepositoryImpl) GetUserIDByCalendarToken(ctx context.Context, token string) (int, error) {
+	var entry model.CalendarToken
+	if err := r.db.Where(ctx, "token = ?", token).First(&entry).Error; err != nil {
+		return 0, fmt.Errorf("failed to get calendar token: %w", err)
+	}
+	return entry.UserID, nil
+}
+
+func lockCommunityEvent(tx *gorm.DB, id int) (*model.CommunityEvent, error) {
+	var event model.CommunityEvent
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock community event: %w", err)
+	}
+	return &event, nil
+}
+
+func adjustRSVPCount(event *model.CommunityEvent, status string, delta int) {
+	switch status {
+	case constant.CommunityEventRSVPGoing:
+		event.GoingCount += delta
+	case constant.CommunityEventRSVPMaybe:
+		event.MaybeCount += delta
+	case constant.CommunityEventRSVPWaitlisted:
+		event.WaitlistCount += delta
+	}
+}
+
+// promoteWaitlist moves waitlisted users of a locked event up, first come first served,
+// until the event is full. It returns their user IDs; the event counters are updated in
+// memory only.
+func promoteWaitlist(tx *gorm.DB, event *model.CommunityEvent) ([]int, error) {
+	q := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("event_id = ? AND status = ?", event.ID, constant.CommunityEventRSVPWaitlisted).
+		Order("waitlisted_at asc, id asc")
+	if event.Capacity > 0 {
+		free := event.Capacity - event.GoingCount
+		if free <= 0 {
+			return nil, nil
+		}
+		q = q.Limit(free)
+	}
+
+	var rsvps []model.CommunityEventRSVP
+	if err := q.Find(&rsvps).Error; err != nil {
+		return nil, fmt.Errorf("failed to get event waitlist: %w", err)
+	}
+	if len(rsvps) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int, len(rsvps))
+	userIDs := make([]int, len(rsvps))
+	for i := range rsvps {
+		ids[i], userIDs[i] = rsvps[i].ID, rsvps[i].UserID
+	}
+	if err := tx.Model(&model.CommunityEventRSVP{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"status":        constant.CommunityEventRSVPGoing,
+		"waitlisted_at": nil,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to promote event waitlist: %w", err)
+	}
+
+	event.GoingCount += len(rsvps)
+	event.WaitlistCount -= len(rsvps)
+	return userIDs, nil
+}
+
+func saveRSVPCounts(tx *gorm.DB, event *model.CommunityEvent) error {
+	if err := tx.Model(event).Updates(map[string]interface{}{
+		"going_count":    event.GoingCount,
+		"maybe_count":    event.MaybeCount,
+		"waitlist_count": event.WaitlistCount,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update event rsvp counts: %w", err)
+	}
+	return nil
+}
+
+func notifyEventAttendees(tx *gorm.DB, eventID int, statuses []string, template model.Notification) error {
+	var userIDs []int
+	if err := tx.Model(&model.CommunityEventRSVP{}).
+		Where("event_id = ? AND status IN ?", eventID, statuses).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return fmt.Errorf("failed to get event attendees: %w", err)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	notifications := make([]model.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		notifications[i] = template
+		notifications[i].UserID = userID
+	}
+	if err := tx.CreateInBatches(&notifications, communityEventNotificationBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to notify event attendees: %w", err)
+	}
+	return nil
+}
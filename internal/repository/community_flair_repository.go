@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommunityFlairRepository interface {
+	CreateFlair(ctx context.Context, flair *model.CommunityFlair, maxFlairs int) error
+	GetFlairs(ctx context.Context, communityID int) ([]model.CommunityFlair, error)
+	GetFlairByID(ctx context.Context, communityID, flairID int) (*model.CommunityFlair, error)
+	UpdateFlair(ctx context.Context, communityID, flairID int, updates map[string]interface{}) (bool, error)
+	DeleteFlair(ctx context.Context, communityID, flairID int) (bool, error)
+	SetPostFlair(ctx context.Context, postID int, flairID *int) error
+}
+
+type CommunityFlairRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewCommunityFlairRepository(db database.PostgresWrapper) CommunityFlairRepository {
+	return &CommunityFlairRepositoryImpl{
+		db: db,
+	}
+}
+
+// CreateFlair adds a flair to a community. ErrDuplicateRecord means the community already
+// has a flair with that name and ErrLimitReached that it has maxFlairs of them.
+func (r *CommunityFlairRepositoryImpl) CreateFlair(ctx context.Context, flair *model.CommunityFlair, maxFlairs int) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := lockCommunity(tx, flair.CommunityID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.CommunityFlair{}).Where("community_id = ?", flair.CommunityID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count community flairs: %w", err)
+		}
+		if count >= int64(maxFlairs) {
+			return ErrLimitReached
+		}
+
+		flair.Position = int(count) + 1
+		if err := tx.Create(flair).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrDuplicateRecord
+			}
+			return fmt.Errorf("failed to create community flair: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *CommunityFlairRepositoryImpl) GetFlairs(ctx context.Context, communityID int) ([]model.CommunityFlair, error) {
+	var flairs []model.CommunityFlair
+	if err := r.db.Where(ctx, "community_id = ?", communityID).Order("position asc").Find(&flairs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get community flairs: %w", err)
+	}
+	return flairs, nil
+}
+
+func (r *CommunityFlairRepositoryImpl) GetFlairByID(ctx context.Context, communityID, flairID int) (*model.CommunityFlair, error) {
+	var flair model.CommunityFlair
+	if err := r.db.Where(ctx, "id = ? AND community_id = ?", flairID, communityID).First(&flair).Error; err != nil {
+		return nil, fmt.Errorf("failed to get community flair: %w", err)
+	}
+	return &flair, nil
+}
+
+// UpdateFlair changes a flair. ErrDuplicateRecord means it was renamed to the name of
+// another flair of the community.
+func (r *CommunityFlairRepositoryImpl) UpdateFlair(ctx context.Context, communityID, flairID int, updates map[string]interface{}) (bool, error) {
+	res := r.db.Model(ctx, &model.CommunityFlair{}).
+		Where("id = ? AND community_id = ?", flairID, communityID).
+		Updates(updates)
+	if res.Error != nil {
+		if isUniqueViolation(res.Error) {
+			return false, ErrDuplicateRecord
+		}
+		return false, fmt.Errorf("failed to update community flair: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteFlair removes a flair for good, so its name can be reused, and takes it off the
+// posts that carried it.
+func (r *CommunityFlairRepositoryImpl) DeleteFlair(ctx context.Context, communityID, flairID int) (bool, error) {
+	deleted := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var flair model.CommunityFlair
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND community_id = ?", flairID, communityID).
+			Limit(1).Find(&flair)
+		if res.Error != nil {
+			return fmt.Errorf("failed to get community flair: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&model.Post{}).Where("flair_id = ?", flairID).Update("flair_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to clear post flairs: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&flair).Error; err != nil {
+			return fmt.Errorf("failed to delete community flair: %w", err)
+		}
+
+		deleted = true
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (r *CommunityFlairRepositoryImpl) SetPostFlair(ctx context.Context, postID int, flairID *int) error {
+	if err := r.db.Model(ctx, &model.Post{}).Where("id = ?", postID).Update("flair_id", flairID).Error; err != nil {
+		return fmt.Errorf("failed to set post flair: %w", err)
+	}
+	return nil
+}
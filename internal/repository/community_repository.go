@@ -2,27 +2,76 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/model"
 	database "github.com/temuka-api-service/util/database"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// CommunityFilter narrows SearchCommunities; zero fields are ignored. Query is matched
+// against the name and description of communities.
+type CommunityFilter struct {
+	Query        string
+	Category     string
+	Tag          string
+	UniversityID int
+}
+
+type CommunityCategoryCount struct {
+	Category         string `json:"category"`
+	CommunitiesCount int64  `json:"communities_count"`
+}
+
 type CommunityRepository interface {
 	CreateCommunity(ctx context.Context, community *model.Community) error
 	CheckCommunityNameAvailability(ctx context.Context, name string) bool
 	UpdateCommunity(ctx context.Context, id int, community *model.Community) error
-	GetCommunities(ctx context.Context) ([]model.Community, error)
+	SetCommunityFlairRequired(ctx context.Context, id int, required bool) error
 	GetUserJoinedCommunities(ctx context.Context, userID int) ([]model.Community, error)
 	GetCommunityDetailByID(ctx context.Context, id int) (*model.Community, error)
 	CheckMembership(ctx context.Context, communityID, userID int) (*model.CommunityMember, error)
 	AddCommunityMember(ctx context.Context, member *model.CommunityMember) error
-	GetCommunityPosts(ctx context.Context, id int, filters map[string]interface{}) ([]model.CommunityPost, error)
-	UpdateCommunityPostsCount(ctx context.Context, id int) error
-	UpdateCommunityMembersCount(ctx context.Context, id int) error
+	RemoveCommunityMember(ctx context.Context, communityID, userID int) (bool, error)
+	GetCommunityMembers(ctx context.Context, communityID int, search string, offset, limit int) ([]CommunityMemberRow, int64, error)
+	GetCommunityPosts(ctx context.Context, id, viewerID int, filters map[string]interface{}) ([]model.Post, error)
+	UpdateCommunityPostsCount(ctx context.Context, id int, delta int) error
+	ReconcileCommunityCounts(ctx context.Context) (int64, error)
+	BanCommunityMember(ctx context.Context, communityID, userID int, until *time.Time, entry *model.CommunitySanction) error
+	UnbanCommunityMember(ctx context.Context, communityID, userID int, expiredBy *time.Time, entry *model.CommunitySanction) (bool, error)
+	MuteCommunityMember(ctx context.Context, communityID, userID int, until *time.Time, entry *model.CommunitySanction) (bool, error)
+	UnmuteCommunityMember(ctx context.Context, communityID, userID int, expiredBy *time.Time, entry *model.CommunitySanction) (bool, error)
+	GetExpiredSanctions(ctx context.Context, now time.Time, limit int) ([]model.CommunityMember, error)
+	GetCommunitySanctions(ctx context.Context, communityID, offset, limit int) ([]model.CommunitySanction, int64, error)
+	JoinWithInvite(ctx context.Context, member *model.CommunityMember, code string) error
+	CreateJoinRequest(ctx context.Context, request *model.CommunityJoinRequest) error
+	CancelJoinRequest(ctx context.Context, communityID, userID int) (bool, error)
+	GetPendingJoinRequests(ctx context.Context, communityID, offset, limit int) ([]CommunityJoinRequestRow, int64, error)
+	ReviewJoinRequest(ctx context.Context, communityID, requestID, reviewerID int, approve bool, reason string) (*model.CommunityJoinRequest, error)
+	CreateInvite(ctx context.Context, invite *model.CommunityInvite) error
+	GetInvites(ctx context.Context, communityID, offset, limit int) ([]model.CommunityInvite, int64, error)
+	RevokeInvite(ctx context.Context, communityID, inviteID int) (bool, error)
+	GetPublicPostsAfter(ctx context.Context, communityID, afterID, limit int) ([]model.Post, error)
 	DeleteCommunity(ctx context.Context, id int) error
 	GetCommunityDetailBySlug(ctx context.Context, slug string) (*model.Community, error)
+	SetCommunityArchived(ctx context.Context, id int, archived bool) error
+	SetCommunityUniversity(ctx context.Context, id int, universityID *int) error
+	SetCommunityTags(ctx context.Context, id int, names []string) error
+	SearchCommunities(ctx context.Context, filter CommunityFilter, offset, limit int) ([]model.Community, int64, error)
+	GetTrendingCommunities(ctx context.Context, since time.Time, offset, limit int) ([]model.Community, int64, error)
+	GetSimilarCommunities(ctx context.Context, community *model.Community, offset, limit int) ([]model.Community, int64, error)
+	GetCategoryCounts(ctx context.Context) ([]CommunityCategoryCount, error)
+	GetDeletedCommunity(ctx context.Context, id int) (*model.Community, error)
+	RestoreCommunity(ctx context.Context, id int, deletedAfter time.Time) (bool, error)
+	GetCommunitiesDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Community, error)
+	PurgeCommunity(ctx context.Context, id int) ([]int, error)
+	CreateOwnershipTransfer(ctx context.Context, transfer *model.CommunityOwnershipTransfer) error
+	RespondOwnershipTransfer(ctx context.Context, communityID, userID int, accept bool) (*model.CommunityOwnershipTransfer, error)
+	CancelOwnershipTransfer(ctx context.Context, communityID int) (bool, error)
 }
 
 type CommunityRepositoryImpl struct {
@@ -35,17 +84,40 @@ func NewCommunityRepository(db database.PostgresWrapper) CommunityRepository {
 	}
 }
 
+// CreateCommunity creates a community and makes its owner, if it has one, its first member
+// and moderator in the same transaction. ErrDuplicateRecord means its slug is taken.
 func (r *CommunityRepositoryImpl) CreateCommunity(ctx context.Context, community *model.Community) error {
-	if err := r.db.Create(ctx, community); err != nil {
-		return fmt.Errorf("failed to create community: %w", err)
-	}
-	return nil
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(community).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrDuplicateRecord
+			}
+			return fmt.Errorf("failed to create community: %w", err)
+		}
+		if community.OwnerID == nil {
+			return nil
+		}
+
+		member := model.CommunityMember{UserID: *community.OwnerID, CommunityID: community.ID}
+		if err := insertCommunityMember(tx, &member); err != nil {
+			return err
+		}
+		community.MembersCount = 1
+
+		if err := tx.Create(&model.Moderator{CommunityID: community.ID, CommunityMemberID: member.ID}).Error; err != nil {
+			return fmt.Errorf("failed to create community moderator: %w", err)
+		}
+		return nil
+	})
 }
 
+// CheckCommunityNameAvailability also counts deleted communities, whose names stay taken
+// while they can still be restored.
 func (r *CommunityRepositoryImpl) CheckCommunityNameAvailability(ctx context.Context, name string) bool {
 	var count int64
 
 	err := r.db.Model(ctx, &model.Community{}).
+		Unscoped().
 		Where("name = ?", name).
 		Count(&count).Error
 
@@ -65,6 +137,15 @@ func (r *CommunityRepositoryImpl) UpdateCommunity(ctx context.Context, id int, c
 	return nil
 }
 
+func (r *CommunityRepositoryImpl) SetCommunityFlairRequired(ctx context.Context, id int, required bool) error {
+	if err := r.db.Model(ctx, &model.Community{}).
+		Where("id = ?", id).
+		Update("flair_required", required).Error; err != nil {
+		return fmt.Errorf("failed to update community flair setting: %w", err)
+	}
+	return nil
+}
+
 func (r *CommunityRepositoryImpl) GetCommunityDetailByID(ctx context.Context, id int) (*model.Community, error) {
 	var community model.Community
 	if err := r.db.First(ctx, &community, id); err != nil {
@@ -73,14 +154,8 @@ func (r *CommunityRepositoryImpl) GetCommunityDetailByID(ctx context.Context, id
 	return &community, nil
 }
 
-func (r *CommunityRepositoryImpl) GetCommunities(ctx context.Context) ([]model.Community, error) {
-	var communities []model.Community
-	if err := r.db.Find(ctx, &communities); err != nil {
-		return nil, fmt.Errorf("failed to get communities: %w", err)
-	}
-	return communities, nil
-}
-
+// DeleteCommunity soft-deletes a community. PurgeCommunity removes it for good once the
+// recovery window has passed.
 func (r *CommunityRepositoryImpl) DeleteCommunity(ctx context.Context, id int) error {
 	if err := r.db.Delete(ctx, &model.Community{}, id); err != nil {
 		return fmt.Errorf("failed to delete community: %w", err)
@@ -88,31 +163,574 @@ func (r *CommunityRepositoryImpl) DeleteCommunity(ctx context.Context, id int) e
 	return nil
 }
 
+// AddCommunityMember adds a member and bumps members_count in the same transaction.
+// ErrDuplicateRecord means the user is already a member.
 func (r *CommunityRepositoryImpl) AddCommunityMember(ctx context.Context, member *model.CommunityMember) error {
-	if err := r.db.Create(ctx, member); err != nil {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		return insertCommunityMember(tx, member)
+	})
+}
+
+// insertCommunityMember creates a membership, bumps members_count and closes a pending
+// join request the user may still have, whichever way they got in.
+func insertCommunityMember(tx *gorm.DB, member *model.CommunityMember) error {
+	if err := tx.Create(member).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateRecord
+		}
 		return fmt.Errorf("failed to add community member: %w", err)
 	}
+
+	if member.Banned {
+		return nil
+	}
+	if err := updateMembersCount(tx, member.CommunityID, 1); err != nil {
+		return err
+	}
+	if err := logMembership(tx, member.CommunityID, member.UserID, constant.CommunityMembershipJoined); err != nil {
+		return err
+	}
+
+	if err := tx.Model(&model.CommunityJoinRequest{}).
+		Where("community_id = ? AND user_id = ? AND status = ?", member.CommunityID, member.UserID, constant.CommunityJoinRequestPending).
+		Updates(map[string]interface{}{"status": constant.CommunityJoinRequestApproved, "reviewed_at": time.Now()}).Error; err != nil {
+		return fmt.Errorf("failed to close join request: %w", err)
+	}
+
 	return nil
 }
 
-func (r *CommunityRepositoryImpl) UpdateCommunityPostsCount(ctx context.Context, id int) error {
-	if err := r.db.Model(ctx, &model.Community{}).
-		Where("id = ?", id).
-		Update("posts_count", gorm.Expr("posts_count + 1")).Error; err != nil {
-		return fmt.Errorf("failed to update community posts count: %w", err)
+// logMembership records a join or leave for community analytics.
+func logMembership(tx *gorm.DB, communityID, userID int, action string) error {
+	entry := model.CommunityMembershipLog{CommunityID: communityID, UserID: userID, Action: action}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to log community membership: %w", err)
 	}
 	return nil
 }
 
-func (r *CommunityRepositoryImpl) UpdateCommunityMembersCount(ctx context.Context, id int) error {
+// JoinWithInvite adds member using the invite with the given code, counting the use in the
+// same transaction. ErrStaleRecord means the invite does not exist for this community and
+// user, was revoked, expired or ran out of uses.
+func (r *CommunityRepositoryImpl) JoinWithInvite(ctx context.Context, member *model.CommunityMember, code string) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(&model.CommunityInvite{}).
+			Where("code = ? AND community_id = ? AND revoked_at IS NULL", code, member.CommunityID).
+			Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+			Where("max_uses = 0 OR uses < max_uses").
+			Where("invited_user_id IS NULL OR invited_user_id = ?", member.UserID).
+			Update("uses", gorm.Expr("uses + 1"))
+		if res.Error != nil {
+			return fmt.Errorf("failed to redeem community invite: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleRecord
+		}
+
+		return insertCommunityMember(tx, member)
+	})
+}
+
+// CreateJoinRequest stores a pending join request. ErrDuplicateRecord means the user
+// already has one pending for the community.
+func (r *CommunityRepositoryImpl) CreateJoinRequest(ctx context.Context, request *model.CommunityJoinRequest) error {
+	if err := r.db.Create(ctx, request); err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateRecord
+		}
+		return fmt.Errorf("failed to create join request: %w", err)
+	}
+	return nil
+}
+
+func (r *CommunityRepositoryImpl) CancelJoinRequest(ctx context.Context, communityID, userID int) (bool, error) {
+	res := r.db.Model(ctx, &model.CommunityJoinRequest{}).
+		Where("community_id = ? AND user_id = ? AND status = ?", communityID, userID, constant.CommunityJoinRequestPending).
+		Update("status", constant.CommunityJoinRequestCancelled)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to cancel join request: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// CommunityJoinRequestRow is a join request with the profile of the user asking to join.
+type CommunityJoinRequestRow struct {
+	model.CommunityJoinRequest
+	Username       string `gorm:"column:username"`
+	Displayname    string `gorm:"column:displayname"`
+	ProfilePicture string `gorm:"column:profile_picture"`
+}
+
+// GetPendingJoinRequests lists the approval queue of a community, oldest request first.
+func (r *CommunityRepositoryImpl) GetPendingJoinRequests(ctx context.Context, communityID, offset, limit int) ([]CommunityJoinRequestRow, int64, error) {
+	var rows []CommunityJoinRequestRow
+	var total int64
+
+	query := func() *gorm.DB {
+		return r.db.Model(ctx, &model.CommunityJoinRequest{}).
+			Joins("INNER JOIN users u ON u.id = community_join_requests.user_id AND u.deleted_at IS NULL").
+			Where("community_join_requests.community_id = ? AND community_join_requests.status = ?", communityID, constant.CommunityJoinRequestPending)
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count join requests: %w", err)
+	}
+
+	err := query().
+		Select("community_join_requests.*, u.username, u.displayname, u.profile_picture").
+		Order("community_join_requests.created_at ASC, community_join_requests.id ASC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get join requests: %w", err)
+	}
+
+	return rows, total, nil
+}
+
+// ReviewJoinRequest approves or rejects a pending join request; an approved user becomes a
+// member in the same transaction. ErrStaleRecord means the request is no longer pending.
+func (r *CommunityRepositoryImpl) ReviewJoinRequest(ctx context.Context, communityID, requestID, reviewerID int, approve bool, reason string) (*model.CommunityJoinRequest, error) {
+	var request model.CommunityJoinRequest
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND community_id = ? AND status = ?", requestID, communityID, constant.CommunityJoinRequestPending).
+			First(&request).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrStaleRecord
+			}
+			return fmt.Errorf("failed to get join request: %w", err)
+		}
+
+		now := time.Now()
+		request.Status = constant.CommunityJoinRequestRejected
+		if approve {
+			request.Status = constant.CommunityJoinRequestApproved
+		}
+		request.ReviewedBy = &reviewerID
+		request.ReviewedAt = &now
+		request.Reason = reason
+
+		if err := tx.Model(&model.CommunityJoinRequest{}).Where("id = ?", request.ID).
+			Updates(map[string]interface{}{
+				"status":      request.Status,
+				"reviewed_by": reviewerID,
+				"reviewed_at": now,
+				"reason":      reason,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to review join request: %w", err)
+		}
+
+		if !approve {
+			return nil
+		}
+		member := model.CommunityMember{UserID: request.UserID, CommunityID: communityID}
+		if err := insertCommunityMember(tx, &member); err != nil && !errors.Is(err, ErrDuplicateRecord) {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *CommunityRepositoryImpl) CreateInvite(ctx context.Context, invite *model.CommunityInvite) error {
+	if err := r.db.Create(ctx, invite); err != nil {
+		return fmt.Errorf("failed to create community invite: %w", err)
+	}
+	return nil
+}
+
+func (r *CommunityRepositoryImpl) GetInvites(ctx context.Context, communityID, offset, limit int) ([]model.CommunityInvite, int64, error) {
+	var invites []model.CommunityInvite
+	var total int64
+
+	if err := r.db.Model(ctx, &model.CommunityInvite{}).Where("community_id = ?", communityID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count community invites: %w", err)
+	}
+
+	q := r.db.Where(ctx, "community_id = ?", communityID).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit)
+	if err := q.Find(&invites).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get community invites: %w", err)
+	}
+
+	return invites, total, nil
+}
+
+func (r *CommunityRepositoryImpl) RevokeInvite(ctx context.Context, communityID, inviteID int) (bool, error) {
+	res := r.db.Model(ctx, &model.CommunityInvite{}).
+		Where("id = ? AND community_id = ? AND revoked_at IS NULL", inviteID, communityID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to revoke community invite: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// GetPublicPostsAfter returns published public posts of a community with an id above
+// afterID, in id order, so callers can walk all of them in batches.
+func (r *CommunityRepositoryImpl) GetPublicPostsAfter(ctx context.Context, communityID, afterID, limit int) ([]model.Post, error) {
+	var posts []model.Post
+
+	err := r.db.Where(ctx, "community_id = ? AND status = ? AND visibility = ? AND id > ?",
+		communityID, constant.PostStatusPublished, constant.PostVisibilityPublic, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&posts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get community posts: %w", err)
+	}
+
+	return posts, nil
+}
+
+// RemoveCommunityMember deletes a membership together with the moderator role held
+// through it and lowers members_count. It reports false when the user was not a member;
+// banned users are not, and their row stays so the ban holds.
+func (r *CommunityRepositoryImpl) RemoveCommunityMember(ctx context.Context, communityID, userID int) (bool, error) {
+	removed := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var member model.CommunityMember
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("community_id = ? AND user_id = ?", communityID, userID).
+			First(&member).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to get community member: %w", err)
+		}
+
+		if member.Banned {
+			return nil
+		}
+
+		if err := tx.Where("community_id = ? AND communitymember_id = ?", communityID, member.ID).
+			Delete(&model.Moderator{}).Error; err != nil {
+			return fmt.Errorf("failed to delete moderator: %w", err)
+		}
+
+		// Memberships are deleted for good so the user can join again later.
+		if err := tx.Unscoped().Delete(&model.CommunityMember{}, member.ID).Error; err != nil {
+			return fmt.Errorf("failed to remove community member: %w", err)
+		}
+
+		if err := updateMembersCount(tx, communityID, -1); err != nil {
+			return err
+		}
+		if err := logMembership(tx, communityID, userID, constant.CommunityMembershipLeft); err != nil {
+			return err
+		}
+
+		removed = true
+		return nil
+	})
+
+	return removed, err
+}
+
+// BanCommunityMember bans userID from a community until the given time, or for good when
+// until is nil, and records entry. A user who is not a member gets a ban-only membership so
+// they cannot join; banning an already banned member replaces the end of the ban.
+func (r *CommunityRepositoryImpl) BanCommunityMember(ctx context.Context, communityID, userID int, until *time.Time, entry *model.CommunitySanction) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		member, err := lockCommunityMember(tx, communityID, userID)
+		if err != nil {
+			return err
+		}
+
+		if member == nil {
+			banned := model.CommunityMember{
+				UserID:      userID,
+				CommunityID: communityID,
+				Banned:      true,
+				BannedUntil: until,
+				BanOnly:     true,
+			}
+			if err := tx.Create(&banned).Error; err != nil {
+				return fmt.Errorf("failed to create banned member: %w", err)
+			}
+		} else {
+			if err := tx.Model(&model.CommunityMember{}).Where("id = ?", member.ID).
+				Updates(map[string]interface{}{"banned": true, "banned_until": until}).Error; err != nil {
+				return fmt.Errorf("failed to ban community member: %w", err)
+			}
+			if !member.Banned {
+				if err := updateMembersCount(tx, communityID, -1); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to create community sanction: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// UnbanCommunityMember lifts the ban of userID and records entry, reporting false when the
+// user was not banned. With expiredBy set, only a timed ban ending by then is lifted, so a
+// ban extended in the meantime is left alone.
+func (r *CommunityRepositoryImpl) UnbanCommunityMember(ctx context.Context, communityID, userID int, expiredBy *time.Time, entry *model.CommunitySanction) (bool, error) {
+	lifted := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		member, err := lockCommunityMember(tx, communityID, userID)
+		if err != nil {
+			return err
+		}
+		if member == nil || !member.Banned || !sanctionExpired(member.BannedUntil, expiredBy) {
+			return nil
+		}
+
+		if member.BanOnly {
+			if err := tx.Unscoped().Delete(&model.CommunityMember{}, member.ID).Error; err != nil {
+				return fmt.Errorf("failed to remove banned member: %w", err)
+			}
+		} else {
+			if err := tx.Model(&model.CommunityMember{}).Where("id = ?", member.ID).
+				Updates(map[string]interface{}{"banned": false, "banned_until": nil}).Error; err != nil {
+				return fmt.Errorf("failed to unban community member: %w", err)
+			}
+			if err := updateMembersCount(tx, communityID, 1); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to create community sanction: %w", err)
+		}
+
+		lifted = true
+		return nil
+	})
+
+	return lifted, err
+}
+
+// MuteCommunityMember makes a member read-only until the given time, or for good when until
+// is nil, and records entry. It reports false when the user is not a member.
+func (r *CommunityRepositoryImpl) MuteCommunityMember(ctx context.Context, communityID, userID int, until *time.Time, entry *model.CommunitySanction) (bool, error) {
+	muted := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		member, err := lockCommunityMember(tx, communityID, userID)
+		if err != nil {
+			return err
+		}
+		if member == nil || member.BanOnly {
+			return nil
+		}
+
+		if err := tx.Model(&model.CommunityMember{}).Where("id = ?", member.ID).
+			Updates(map[string]interface{}{"muted": true, "muted_until": until}).Error; err != nil {
+			return fmt.Errorf("failed to mute community member: %w", err)
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to create community sanction: %w", err)
+		}
+
+		muted = true
+		return nil
+	})
+
+	return muted, err
+}
+
+// UnmuteCommunityMember lifts the mute of userID like UnbanCommunityMember lifts a ban.
+func (r *CommunityRepositoryImpl) UnmuteCommunityMember(ctx context.Context, communityID, userID int, expiredBy *time.Time, entry *model.CommunitySanction) (bool, error) {
+	lifted := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		member, err := lockCommunityMember(tx, communityID, userID)
+		if err != nil {
+			return err
+		}
+		if member == nil || !member.Muted || !sanctionExpired(member.MutedUntil, expiredBy) {
+			return nil
+		}
+
+		if err := tx.Model(&model.CommunityMember{}).Where("id = ?", member.ID).
+			Updates(map[string]interface{}{"muted": false, "muted_until": nil}).Error; err != nil {
+			return fmt.Errorf("failed to unmute community member: %w", err)
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to create community sanction: %w", err)
+		}
+
+		lifted = true
+		return nil
+	})
+
+	return lifted, err
+}
+
+func lockCommunityMember(tx *gorm.DB, communityID, userID int) (*model.CommunityMember, error) {
+	var member model.CommunityMember
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("community_id = ? AND user_id = ?", communityID, userID).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get community member: %w", err)
+	}
+
+	return &member, nil
+}
+
+// sanctionExpired reports whether a sanction ending at until may be lifted as of expiredBy.
+// Lifting by hand (expiredBy nil) is always allowed.
+func sanctionExpired(until, expiredBy *time.Time) bool {
+	if expiredBy == nil {
+		return true
+	}
+	return until != nil && !until.After(*expiredBy)
+}
+
+// GetExpiredSanctions returns up to limit memberships whose timed ban or mute ended by now.
+func (r *CommunityRepositoryImpl) GetExpiredSanctions(ctx context.Context, now time.Time, limit int) ([]model.CommunityMember, error) {
+	var members []model.CommunityMember
+
+	err := r.db.Where(ctx, "(banned = true AND banned_until <= ?) OR (muted = true AND muted_until <= ?)", now, now).
+		Order("id").
+		Limit(limit).
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired community sanctions: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *CommunityRepositoryImpl) GetCommunitySanctions(ctx context.Context, communityID, offset, limit int) ([]model.CommunitySanction, int64, error) {
+	var sanctions []model.CommunitySanction
+	var total int64
+
+	if err := r.db.Model(ctx, &model.CommunitySanction{}).Where("community_id = ?", communityID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count community sanctions: %w", err)
+	}
+
+	q := r.db.Where(ctx, "community_id = ?", communityID).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit)
+	if err := q.Find(&sanctions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get community sanctions: %w", err)
+	}
+
+	return sanctions, total, nil
+}
+
+func updateMembersCount(tx *gorm.DB, communityID, delta int) error {
+	if err := tx.Model(&model.Community{}).
+		Where("id = ?", communityID).
+		Update("members_count", gorm.Expr("GREATEST(members_count + ?, 0)", delta)).Error; err != nil {
+		return fmt.Errorf("failed to update community members count: %w", err)
+	}
+	return nil
+}
+
+// CommunityMemberRow is a membership with the profile of the member and whether they
+// moderate the community.
+type CommunityMemberRow struct {
+	model.CommunityMember
+	Username       string `gorm:"column:username"`
+	Displayname    string `gorm:"column:displayname"`
+	ProfilePicture string `gorm:"column:profile_picture"`
+	IsModerator    bool   `gorm:"column:is_moderator"`
+}
+
+// GetCommunityMembers lists the members of a community that are not banned, moderators first
+// and then by join date. search matches the start of the username or display name.
+func (r *CommunityRepositoryImpl) GetCommunityMembers(ctx context.Context, communityID int, search string, offset, limit int) ([]CommunityMemberRow, int64, error) {
+	var rows []CommunityMemberRow
+	var total int64
+
+	query := func() *gorm.DB {
+		q := r.db.Model(ctx, &model.CommunityMember{}).
+			Joins("INNER JOIN users u ON u.id = community_members.user_id AND u.deleted_at IS NULL").
+			Where("community_members.community_id = ? AND community_members.banned = false", communityID)
+		if search != "" {
+			pattern := escapeLike(search) + "%"
+			q = q.Where("(u.username ILIKE ? OR u.displayname ILIKE ?)", pattern, pattern)
+		}
+		return q
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count community members: %w", err)
+	}
+
+	err := query().
+		Select(`community_members.*, u.username, u.displayname, u.profile_picture,
+			EXISTS (SELECT 1 FROM moderators m WHERE m.communitymember_id = community_members.id
+				AND m.community_id = community_members.community_id AND m.deleted_at IS NULL) AS is_moderator`).
+		Order("is_moderator DESC, community_members.created_at ASC, community_members.id ASC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get community members: %w", err)
+	}
+
+	return rows, total, nil
+}
+
+func (r *CommunityRepositoryImpl) UpdateCommunityPostsCount(ctx context.Context, id int, delta int) error {
 	if err := r.db.Model(ctx, &model.Community{}).
 		Where("id = ?", id).
-		Update("members_count", gorm.Expr("members_count + 1")).Error; err != nil {
-		return fmt.Errorf("failed to update community members count: %w", err)
+		Update("posts_count", gorm.Expr("GREATEST(posts_count + ?, 0)", delta)).Error; err != nil {
+		return fmt.Errorf("failed to update community posts count: %w", err)
 	}
 	return nil
 }
 
+// ReconcileCommunityCounts recomputes members_count and posts_count from the member and post
+// tables and repairs the communities whose counters drifted. It returns how many were fixed.
+func (r *CommunityRepositoryImpl) ReconcileCommunityCounts(ctx context.Context) (int64, error) {
+	rawQuery := `
+		WITH actual AS (
+			SELECT c.id,
+				(SELECT COUNT(*) FROM community_members cm
+					WHERE cm.community_id = c.id AND cm.banned = false AND cm.deleted_at IS NULL) AS members_count,
+				(SELECT COUNT(*) FROM posts p
+					WHERE p.community_id = c.id AND p.status = @published AND p.deleted_at IS NULL) AS posts_count
+			FROM communities c
+			WHERE c.deleted_at IS NULL
+		)
+		UPDATE communities c
+		SET members_count = actual.members_count, posts_count = actual.posts_count
+		FROM actual
+		WHERE c.id = actual.id
+			AND (c.members_count <> actual.members_count OR c.posts_count <> actual.posts_count)
+	`
+
+	res := r.db.DB.WithContext(ctx).Exec(rawQuery, map[string]interface{}{"published": constant.PostStatusPublished})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to reconcile community counts: %w", res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
+
 func (r *CommunityRepositoryImpl) CheckMembership(ctx context.Context, communityID, userID int) (*model.CommunityMember, error) {
 	var member model.CommunityMember
 
@@ -129,28 +747,42 @@ func (r *CommunityRepositoryImpl) CheckMembership(ctx context.Context, community
 	return &member, nil
 }
 
-func (r *CommunityRepositoryImpl) GetCommunityPosts(ctx context.Context, communityID int, filters map[string]interface{}) ([]model.CommunityPost, error) {
-	var posts []model.CommunityPost
+var communityPostSortColumns = map[string]string{
+	"created_at":   "posts.created_at",
+	"published_at": "posts.published_at",
+	"shares_count": "posts.shares_count",
+	"views_count":  "posts.views_count",
+}
 
-	query := r.db.Where(ctx, "community_id = ?", communityID)
+// GetCommunityPosts returns the published posts of a community that viewerID may see.
+// Pinned posts come first in their pinned order, whatever the sort. The topic filter matches
+// the hashtags of a post and flair the ID of its flair; sort_by is limited to a fixed set
+// of columns.
+func (r *CommunityRepositoryImpl) GetCommunityPosts(ctx context.Context, communityID, viewerID int, filters map[string]interface{}) ([]model.Post, error) {
+	var posts []model.Post
 
-	for key, value := range filters {
-		if key == "sort" || key == "sort_by" {
-			continue
-		}
-		query = query.Where(key+" = ?", value)
-	}
+	query := r.db.Where(ctx, "posts.community_id = ? AND posts.status = ?", communityID, constant.PostStatusPublished).
+		Scopes(visiblePostsTo(viewerID)).
+		Preload("RepostOf", visiblePostsTo(viewerID)).
+		Preload("LinkPreview", "status = ?", constant.LinkPreviewStatusReady).
+		Preload("Flair")
 
-	sortBy, sortExists := filters["sort_by"].(string)
-	sortOrder, orderExists := filters["sort"].(string)
+	if topic, ok := filters["topic"].(string); ok && topic != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM post_tags pt INNER JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = posts.id AND t.name = ?)", topic)
+	}
+	if flairID, ok := filters["flair"].(int); ok {
+		query = query.Where("posts.flair_id = ?", flairID)
+	}
 
-	if sortExists && orderExists {
-		query = query.Order(sortBy + " " + sortOrder)
-	} else if sortExists {
-		query = query.Order(sortBy + " asc")
-	} else {
-		query = query.Order("created_at desc")
+	sortColumn, ok := communityPostSortColumns[fmt.Sprint(filters["sort_by"])]
+	if !ok {
+		sortColumn = "posts.created_at"
 	}
+	sortOrder := "desc"
+	if filters["sort"] == "asc" {
+		sortOrder = "asc"
+	}
+	query = query.Order("posts.pinned_position ASC NULLS LAST").Order(sortColumn + " " + sortOrder)
 
 	if err := query.Find(&posts).Error; err != nil {
 		return nil, fmt.Errorf("failed to get community posts: %w", err)
@@ -166,7 +798,7 @@ func (r *CommunityRepositoryImpl) GetUserJoinedCommunities(ctx context.Context,
 		SELECT c.*
 		FROM community_members cm
 		INNER JOIN communities c ON cm.community_id = c.id
-		WHERE cm.user_id = ? AND cm.banned = false
+		WHERE cm.user_id = ? AND cm.banned = false AND c.deleted_at IS NULL
 	`
 
 	if err := r.db.DB.WithContext(ctx).Raw(rawQuery, userID).Scan(&communities).Error; err != nil {
@@ -178,9 +810,431 @@ func (r *CommunityRepositoryImpl) GetUserJoinedCommunities(ctx context.Context,
 
 func (r *CommunityRepositoryImpl) GetCommunityDetailBySlug(ctx context.Context, slug string) (*model.Community, error) {
 	var community model.Community
-	err := r.db.Where(ctx, "slug = ?", slug).First(&community).Error
+	err := r.db.Where(ctx, "slug = ?", slug).
+		Preload("Rules", func(db *gorm.DB) *gorm.DB { return db.Order("position asc") }).
+		Preload("Tags").
+		First(&community).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get community detail by slug: %w", err)
 	}
 	return &community, nil
 }
+
+// SetCommunityArchived archives or unarchives a community. ErrStaleRecord means it already
+// was in that state.
+func (r *CommunityRepositoryImpl) SetCommunityArchived(ctx context.Context, id int, archived bool) error {
+	from, updates := constant.CommunityStatusActive, map[string]interface{}{
+		"status":      constant.CommunityStatusArchived,
+		"archived_at": time.Now(),
+	}
+	if !archived {
+		from, updates = constant.CommunityStatusArchived, map[string]interface{}{
+			"status":      constant.CommunityStatusActive,
+			"archived_at": nil,
+		}
+	}
+
+	res := r.db.Model(ctx, &model.Community{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update community status: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrStaleRecord
+	}
+	return nil
+}
+
+func (r *CommunityRepositoryImpl) GetDeletedCommunity(ctx context.Context, id int) (*model.Community, error) {
+	var community model.Community
+	if err := r.db.Model(ctx, &model.Community{}).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&community).Error; err != nil {
+		return nil, fmt.Errorf("failed to get deleted community: %w", err)
+	}
+	return &community, nil
+}
+
+// RestoreCommunity undeletes a community deleted after deletedAfter, i.e. still inside its
+// recovery window. It reports false when there was no such community.
+func (r *CommunityRepositoryImpl) RestoreCommunity(ctx context.Context, id int, deletedAfter time.Time) (bool, error) {
+	res := r.db.Model(ctx, &model.Community{}).Unscoped().
+		Where("id = ? AND deleted_at > ?", id, deletedAfter).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to restore community: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *CommunityRepositoryImpl) GetCommunitiesDeletedBefore(ctx context.Context, before time.Time, limit int) ([]model.Community, error) {
+	var communities []model.Community
+	if err := r.db.Model(ctx, &model.Community{}).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at <= ?", before).
+		Order("deleted_at asc").
+		Limit(limit).
+		Find(&communities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get deleted communities: %w", err)
+	}
+	return communities, nil
+}
+
+// communityChildTables are purged along with a community, children before the rows they
+// reference.
+var communityChildTables = []interface{}{
+	&model.Moderator{},
+	&model.CommunitySanction{},
+	&model.CommunityJoinRequest{},
+	&model.CommunityInvite{},
+	&model.CommunityOwnershipTransfer{},
+	&model.CommunityAnnouncement{},
+	&model.CommunityPost{},
+	&model.CommunityRule{},
+	&model.CommunityFlair{},
+	&model.CommunityTag{},
+	&model.CommunityEvent{},
+	&model.CommunityMembershipLog{},
+	&model.CommunityDailyStat{},
+	&model.CommunityWikiRevision{},
+	&model.CommunityWikiPage{},
+	&model.CommunityMember{},
+}
+
+// PurgeCommunity removes a deleted community for good: its posts are soft-deleted and its
+// members, moderators and other records are hard-deleted. It returns the IDs of the posts
+// it deleted so the caller can clean up after them.
+func (r *CommunityRepositoryImpl) PurgeCommunity(ctx context.Context, id int) ([]int, error) {
+	var postIDs []int
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var community model.Community
+		res := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Limit(1).Find(&community)
+		if res.Error != nil {
+			return fmt.Errorf("failed to lock community: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			// Restored or purged since it was listed.
+			return nil
+		}
+
+		if err := tx.Model(&model.Post{}).Where("community_id = ?", id).Pluck("id", &postIDs).Error; err != nil {
+			return fmt.Errorf("failed to get community posts: %w", err)
+		}
+		if err := tx.Unscoped().Model(&model.Post{}).Where("community_id = ?", id).
+			Updates(map[string]interface{}{"flair_id": nil, "pinned_position": nil, "pinned_at": nil}).Error; err != nil {
+			return fmt.Errorf("failed to detach community posts: %w", err)
+		}
+		if len(postIDs) > 0 {
+			if err := tx.Where("id IN ?", postIDs).Delete(&model.Post{}).Error; err != nil {
+				return fmt.Errorf("failed to delete community posts: %w", err)
+			}
+		}
+
+		if err := tx.Where("event_id IN (?)", tx.Model(&model.CommunityEvent{}).Unscoped().Select("id").Where("community_id = ?", id)).
+			Delete(&model.CommunityEventRSVP{}).Error; err != nil {
+			return fmt.Errorf("failed to purge community event rsvps: %w", err)
+		}
+
+		for _, table := range communityChildTables {
+			if err := tx.Unscoped().Where("community_id = ?", id).Delete(table).Error; err != nil {
+				return fmt.Errorf("failed to purge community records: %w", err)
+			}
+		}
+
+		if err := tx.Where("entity_type = ? AND entity_id = ?", constant.SlugEntityCommunity, id).
+			Delete(&model.SlugHistory{}).Error; err != nil {
+			return fmt.Errorf("failed to purge community slug history: %w", err)
+		}
+
+		if err := tx.Unscoped().Delete(&community).Error; err != nil {
+			return fmt.Errorf("failed to purge community: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return postIDs, nil
+}
+
+// CreateOwnershipTransfer stores a pending ownership transfer, first expiring a pending one
+// that ran out unanswered. ErrDuplicateRecord means the community already has one pending.
+func (r *CommunityRepositoryImpl) CreateOwnershipTransfer(ctx context.Context, transfer *model.CommunityOwnershipTransfer) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&model.CommunityOwnershipTransfer{}).
+			Where("community_id = ? AND status = ? AND expires_at <= ?",
+				transfer.CommunityID, constant.CommunityOwnershipTransferPending, time.Now()).
+			Update("status", constant.CommunityOwnershipTransferExpired).Error; err != nil {
+			return fmt.Errorf("failed to expire ownership transfer: %w", err)
+		}
+
+		if err := tx.Create(transfer).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrDuplicateRecord
+			}
+			return fmt.Errorf("failed to create ownership transfer: %w", err)
+		}
+		return nil
+	})
+}
+
+// RespondOwnershipTransfer accepts or declines the pending transfer offered to userID. On
+// acceptance userID becomes the owner, and a moderator if they were not one already.
+// ErrStaleRecord means there is no pending, unexpired transfer to userID, or the community
+// changed hands since it was offered.
+func (r *CommunityRepositoryImpl) RespondOwnershipTransfer(ctx context.Context, communityID, userID int, accept bool) (*model.CommunityOwnershipTransfer, error) {
+	var transfer model.CommunityOwnershipTransfer
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("community_id = ? AND to_user_id = ? AND status = ? AND expires_at > ?",
+				communityID, userID, constant.CommunityOwnershipTransferPending, time.Now()).
+			Limit(1).Find(&transfer)
+		if res.Error != nil {
+			return fmt.Errorf("failed to get ownership transfer: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleRecord
+		}
+
+		now := time.Now()
+		transfer.Status = constant.CommunityOwnershipTransferDeclined
+		if accept {
+			transfer.Status = constant.CommunityOwnershipTransferAccepted
+		}
+		transfer.RespondedAt = &now
+		if err := tx.Model(&transfer).Updates(map[string]interface{}{
+			"status":       transfer.Status,
+			"responded_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to respond to ownership transfer: %w", err)
+		}
+
+		if !accept {
+			return nil
+		}
+
+		res = tx.Model(&model.Community{}).
+			Where("id = ? AND owner_id = ?", communityID, transfer.FromUserID).
+			Update("owner_id", userID)
+		if res.Error != nil {
+			return fmt.Errorf("failed to transfer community ownership: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleRecord
+		}
+
+		member, err := lockCommunityMember(tx, communityID, userID)
+		if err != nil {
+			return err
+		}
+		if member == nil || member.Banned {
+			return ErrStaleRecord
+		}
+
+		var moderators int64
+		if err := tx.Model(&model.Moderator{}).
+			Where("community_id = ? AND communitymember_id = ?", communityID, member.ID).
+			Count(&moderators).Error; err != nil {
+			return fmt.Errorf("failed to check community moderator: %w", err)
+		}
+		if moderators == 0 {
+			if err := tx.Create(&model.Moderator{CommunityID: communityID, CommunityMemberID: member.ID}).Error; err != nil {
+				return fmt.Errorf("failed to create community moderator: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+// CancelOwnershipTransfer withdraws the pending, unexpired transfer of a community, if any.
+func (r *CommunityRepositoryImpl) CancelOwnershipTransfer(ctx context.Context, communityID int) (bool, error) {
+	res := r.db.Model(ctx, &model.CommunityOwnershipTransfer{}).
+		Where("community_id = ? AND status = ? AND expires_at > ?",
+			communityID, constant.CommunityOwnershipTransferPending, time.Now()).
+		Update("status", constant.CommunityOwnershipTransferCancelled)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to cancel ownership transfer: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *CommunityRepositoryImpl) SetCommunityUniversity(ctx context.Context, id int, universityID *int) error {
+	if err := r.db.Model(ctx, &model.Community{}).
+		Where("id = ?", id).
+		Update("university_id", universityID).Error; err != nil {
+		return fmt.Errorf("failed to update community university: %w", err)
+	}
+	return nil
+}
+
+// SetCommunityTags makes the tag set of a community exactly names.
+func (r *CommunityRepositoryImpl) SetCommunityTags(ctx context.Context, id int, names []string) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		stale := tx.Where("community_id = ?", id)
+		if len(names) > 0 {
+			stale = stale.Where("name NOT IN ?", names)
+		}
+		if err := stale.Delete(&model.CommunityTag{}).Error; err != nil {
+			return fmt.Errorf("failed to remove community tags: %w", err)
+		}
+
+		if len(names) == 0 {
+			return nil
+		}
+		tags := make([]model.CommunityTag, len(names))
+		for i, name := range names {
+			tags[i] = model.CommunityTag{CommunityID: id, Name: name}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+			return fmt.Errorf("failed to add community tags: %w", err)
+		}
+		return nil
+	})
+}
+
+// discoverableCommunities leaves archived communities out of discovery listings.
+func discoverableCommunities(db *gorm.DB) *gorm.DB {
+	return db.Where("communities.status = ?", constant.CommunityStatusActive)
+}
+
+const communitySearchDocument = `to_tsvector('simple', communities.name || ' ' || COALESCE(communities."desc", ''))`
+
+// SearchCommunities lists discoverable communities matching filter. Matches of a full-text
+// query are ranked by relevance; everything else by size.
+func (r *CommunityRepositoryImpl) SearchCommunities(ctx context.Context, filter CommunityFilter, offset, limit int) ([]model.Community, int64, error) {
+	var communities []model.Community
+	var total int64
+
+	query := func() *gorm.DB {
+		q := r.db.Model(ctx, &model.Community{}).Scopes(discoverableCommunities)
+		if filter.Query != "" {
+			q = q.Where("("+communitySearchDocument+" @@ websearch_to_tsquery('simple', ?) OR communities.name ILIKE ?)",
+				filter.Query, escapeLike(filter.Query)+"%")
+		}
+		if filter.Category != "" {
+			q = q.Where("communities.category = ?", filter.Category)
+		}
+		if filter.Tag != "" {
+			q = q.Where("EXISTS (SELECT 1 FROM community_tags ct WHERE ct.community_id = communities.id AND ct.name = ?)", filter.Tag)
+		}
+		if filter.UniversityID != 0 {
+			q = q.Where("communities.university_id = ?", filter.UniversityID)
+		}
+		return q
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count communities: %w", err)
+	}
+
+	q := query().Preload("Tags")
+	if filter.Query != "" {
+		q = q.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank(" + communitySearchDocument + ", websearch_to_tsquery('simple', ?)) DESC",
+			Vars:               []interface{}{filter.Query},
+			WithoutParentheses: true,
+		}})
+	}
+	if err := q.Order("communities.members_count DESC").Order("communities.id").
+		Offset(offset).Limit(limit).Find(&communities).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to search communities: %w", err)
+	}
+
+	return communities, total, nil
+}
+
+// GetTrendingCommunities ranks discoverable communities by their joins and posts since the
+// given time. Communities without any activity in the window are left out.
+func (r *CommunityRepositoryImpl) GetTrendingCommunities(ctx context.Context, since time.Time, offset, limit int) ([]model.Community, int64, error) {
+	var communities []model.Community
+	var total int64
+
+	query := func() *gorm.DB {
+		return r.db.Model(ctx, &model.Community{}).
+			Scopes(discoverableCommunities).
+			Joins(`LEFT JOIN (
+				SELECT community_id, COUNT(*) AS joins
+				FROM community_members
+				WHERE created_at >= ? AND banned = false AND deleted_at IS NULL
+				GROUP BY community_id
+			) j ON j.community_id = communities.id`, since).
+			Joins(`LEFT JOIN (
+				SELECT community_id, COUNT(*) AS posts
+				FROM posts
+				WHERE created_at >= ? AND status = ? AND deleted_at IS NULL
+				GROUP BY community_id
+			) p ON p.community_id = communities.id`, since, constant.PostStatusPublished).
+			Where("COALESCE(j.joins, 0) + COALESCE(p.posts, 0) > 0")
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count trending communities: %w", err)
+	}
+
+	score := fmt.Sprintf("COALESCE(j.joins, 0) * %d + COALESCE(p.posts, 0) DESC", constant.CommunityTrendingJoinWeight)
+	if err := query().Preload("Tags").
+		Order(score).Order("communities.members_count DESC").Order("communities.id").
+		Offset(offset).Limit(limit).Find(&communities).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get trending communities: %w", err)
+	}
+
+	return communities, total, nil
+}
+
+// GetSimilarCommunities lists the discoverable communities sharing members with community,
+// most similar first. Similarity is the Jaccard index of the two member sets, so large
+// communities do not come first just for being large.
+func (r *CommunityRepositoryImpl) GetSimilarCommunities(ctx context.Context, community *model.Community, offset, limit int) ([]model.Community, int64, error) {
+	var communities []model.Community
+	var total int64
+
+	query := func() *gorm.DB {
+		return r.db.Model(ctx, &model.Community{}).
+			Scopes(discoverableCommunities).
+			Joins(`INNER JOIN (
+				SELECT b.community_id, COUNT(*) AS shared
+				FROM community_members a
+				INNER JOIN community_members b ON b.user_id = a.user_id AND b.community_id <> a.community_id
+				WHERE a.community_id = ? AND a.banned = false AND a.deleted_at IS NULL
+					AND b.banned = false AND b.deleted_at IS NULL
+				GROUP BY b.community_id
+			) s ON s.community_id = communities.id`, community.ID)
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count similar communities: %w", err)
+	}
+
+	similarity := fmt.Sprintf("s.shared::float / GREATEST(%d + communities.members_count - s.shared, 1) DESC", community.MembersCount)
+	if err := query().Preload("Tags").
+		Order(similarity).Order("s.shared DESC").Order("communities.id").
+		Offset(offset).Limit(limit).Find(&communities).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get similar communities: %w", err)
+	}
+
+	return communities, total, nil
+}
+
+func (r *CommunityRepositoryImpl) GetCategoryCounts(ctx context.Context) ([]CommunityCategoryCount, error) {
+	var counts []CommunityCategoryCount
+
+	if err := r.db.Model(ctx, &model.Community{}).
+		Scopes(discoverableCommunities).
+		Select("communities.category AS category, COUNT(*) AS communities_count").
+		Where("communities.category <> ''").
+		Group("communities.category").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count communities by category: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommunityRuleRepository interface {
+	CreateRule(ctx context.Context, rule *model.CommunityRule, maxRules int) error
+	GetRules(ctx context.Context, communityID int) ([]model.CommunityRule, error)
+	GetRuleByID(ctx context.Context, communityID, ruleID int) (*model.CommunityRule, error)
+	UpdateRule(ctx context.Context, communityID, ruleID int, updates map[string]interface{}) (bool, error)
+	DeleteRule(ctx context.Context, communityID, ruleID int) (bool, error)
+	ReorderRules(ctx context.Context, communityID int, ruleIDs []int) error
+}
+
+type CommunityRuleRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewCommunityRuleRepository(db database.PostgresWrapper) CommunityRuleRepository {
+	return &CommunityRuleRepositoryImpl{
+		db: db,
+	}
+}
+
+// CreateRule appends a rule after the existing ones. The community row is locked so
+// concurrent inserts neither share a position nor go over maxRules, which is reported
+// as ErrLimitReached.
+func (r *CommunityRuleRepositoryImpl) CreateRule(ctx context.Context, rule *model.CommunityRule, maxRules int) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := lockCommunity(tx, rule.CommunityID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.CommunityRule{}).Where("community_id = ?", rule.CommunityID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count community rules: %w", err)
+		}
+		if count >= int64(maxRules) {
+			return ErrLimitReached
+		}
+
+		rule.Position = int(count) + 1
+		if err := tx.Create(rule).Error; err != nil {
+			return fmt.Errorf("failed to create community rule: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *CommunityRuleRepositoryImpl) GetRules(ctx context.Context, communityID int) ([]model.CommunityRule, error) {
+	var rules []model.CommunityRule
+	if err := r.db.Where(ctx, "community_id = ?", communityID).Order("position asc").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get community rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *CommunityRuleRepositoryImpl) GetRuleByID(ctx context.Context, communityID, ruleID int) (*model.CommunityRule, error) {
+	var rule model.CommunityRule
+	if err := r.db.Where(ctx, "id = ? AND community_id = ?", ruleID, communityID).First(&rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to get community rule: %w", err)
+	}
+	return &rule, nil
+}
+
+func (r *CommunityRuleRepositoryImpl) UpdateRule(ctx context.Context, communityID, ruleID int, updates map[string]interface{}) (bool, error) {
+	res := r.db.Model(ctx, &model.CommunityRule{}).
+		Where("id = ? AND community_id = ?", ruleID, communityID).
+		Updates(updates)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to update community rule: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteRule removes a rule and closes the gap it leaves in the numbering. Reports and
+// removals that cited it keep the rule ID, and the soft-deleted row stays readable for them.
+func (r *CommunityRuleRepositoryImpl) DeleteRule(ctx context.Context, communityID, ruleID int) (bool, error) {
+	deleted := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := lockCommunity(tx, communityID); err != nil {
+			return err
+		}
+
+		var rule model.CommunityRule
+		res := tx.Where("id = ? AND community_id = ?", ruleID, communityID).Limit(1).Find(&rule)
+		if res.Error != nil {
+			return fmt.Errorf("failed to get community rule: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Delete(&rule).Error; err != nil {
+			return fmt.Errorf("failed to delete community rule: %w", err)
+		}
+		if err := tx.Model(&model.CommunityRule{}).
+			Where("community_id = ? AND position > ?", communityID, rule.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return fmt.Errorf("failed to renumber community rules: %w", err)
+		}
+
+		deleted = true
+		return nil
+	})
+
+	return deleted, err
+}
+
+// ReorderRules numbers the rules of a community in the order of ruleIDs, which must list
+// every rule exactly once. ErrStaleRecord means it does not, e.g. because a rule was added
+// or deleted since the client read them.
+func (r *CommunityRuleRepositoryImpl) ReorderRules(ctx context.Context, communityID int, ruleIDs []int) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := lockCommunity(tx, communityID); err != nil {
+			return err
+		}
+
+		var existing []int
+		if err := tx.Model(&model.CommunityRule{}).Where("community_id = ?", communityID).Pluck("id", &existing).Error; err != nil {
+			return fmt.Errorf("failed to get community rules: %w", err)
+		}
+		if !sameIDs(existing, ruleIDs) {
+			return ErrStaleRecord
+		}
+
+		for i, id := range ruleIDs {
+			if err := tx.Model(&model.CommunityRule{}).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+				return fmt.Errorf("failed to reorder community rules: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// lockCommunity takes a row lock on a community to serialise changes to its child records.
+func lockCommunity(tx *gorm.DB, communityID int) error {
+	var community model.Community
+	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", communityID).Limit(1).Find(&community)
+	if res.Error != nil {
+		return fmt.Errorf("failed to lock community: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// sameIDs reports whether b holds exactly the IDs of a, each once, in any order.
+func sameIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[int]bool, len(a))
+	for _, id := range a {
+		seen[id] = true
+	}
+	for _, id := range b {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
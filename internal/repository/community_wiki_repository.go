@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// communityWikiPageSummaryColumns are the columns of a page without its content, for listings.
+var communityWikiPageSummaryColumns = []string{"id", "community_id", "path", "title", "edit_permission", "revision", "created_by_id", "last_editor_id", "created_at", "updated_at"}
+
+// CommunityWikiRevisionRow is a revision without its content, with the profile of its editor.
+type CommunityWikiRevisionRow struct {
+	ID             int       `gorm:"column:id"`
+	Revision       int       `gorm:"column:revision"`
+	EditorID       int       `gorm:"column:editor_id"`
+	Action         string    `gorm:"column:action"`
+	Title          string    `gorm:"column:title"`
+	Summary        string    `gorm:"column:summary"`
+	RevertedFrom   *int      `gorm:"column:reverted_from"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+	Username       string    `gorm:"column:username"`
+	Displayname    string    `gorm:"column:displayname"`
+	ProfilePicture string    `gorm:"column:profile_picture"`
+}
+
+type CommunityWikiRepository interface {
+	CreatePage(ctx context.Context, page *model.CommunityWikiPage, revision *model.CommunityWikiRevision) error
+	GetPageByID(ctx context.Context, id int) (*model.CommunityWikiPage, error)
+	GetPageByPath(ctx context.Context, communityID int, path string) (*model.CommunityWikiPage, error)
+	GetPages(ctx context.Context, communityID int, prefix string) ([]model.CommunityWikiPage, error)
+	GetPagesAfter(ctx context.Context, communityID, afterID, limit int) ([]model.CommunityWikiPage, error)
+	SavePage(ctx context.Context, page *model.CommunityWikiPage, revision *model.CommunityWikiRevision, baseRevision int) error
+	SetEditPermission(ctx context.Context, id int, permission string) error
+	DeletePage(ctx context.Context, id int) error
+	GetRevisions(ctx context.Context, pageID, offset, limit int) ([]CommunityWikiRevisionRow, int64, error)
+	GetRevision(ctx context.Context, pageID, revision int) (*model.CommunityWikiRevision, error)
+}
+
+type CommunityWikiRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewCommunityWikiRepository(db database.PostgresWrapper) CommunityWikiRepository {
+	return &CommunityWikiRepositoryImpl{
+		db: db,
+	}
+}
+
+// CreatePage creates a page together with its first revision. ErrMissingParent means the
+// page is nested below a path that has no page, and ErrDuplicateRecord that the path is taken.
+func (r *CommunityWikiRepositoryImpl) CreatePage(ctx context.Context, page *model.CommunityWikiPage, revision *model.CommunityWikiRevision) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if parent := path.Dir(page.Path); parent != "." {
+			// The share lock keeps the parent from being deleted until the child exists.
+			var parentIDs []int
+			if err := tx.Model(&model.CommunityWikiPage{}).
+				Clauses(clause.Locking{Strength: "SHARE"}).
+				Where("community_id = ? AND path = ?", page.CommunityID, parent).
+				Limit(1).
+				Pluck("id", &parentIDs).Error; err != nil {
+				return fmt.Errorf("failed to get parent wiki page: %w", err)
+			}
+			if len(parentIDs) == 0 {
+				return ErrMissingParent
+			}
+		}
+
+		page.Revision = 1
+		if err := tx.Create(page).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrDuplicateRecord
+			}
+			return fmt.Errorf("failed to create wiki page: %w", err)
+		}
+
+		revision.PageID, revision.CommunityID, revision.Revision = page.ID, page.CommunityID, page.Revision
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("failed to create wiki revision: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *CommunityWikiRepositoryImpl) GetPageByID(ctx context.Context, id int) (*model.CommunityWikiPage, error) {
+	var page model.CommunityWikiPage
+	if err := r.db.First(ctx, &page, id); err != nil {
+		return nil, fmt.Errorf("failed to get wiki page: %w", err)
+	}
+	return &page, nil
+}
+
+func (r *CommunityWikiRepositoryImpl) GetPageByPath(ctx context.Context, communityID int, path string) (*model.CommunityWikiPage, error) {
+	var page model.CommunityWikiPage
+	if err := r.db.Where(ctx, "community_id = ? AND path = ?", communityID, path).First(&page).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wiki page: %w", err)
+	}
+	return &page, nil
+}
+
+// GetPages lists the pages of a community without their content, ordered by path so every
+// page follows its parent. A non-empty prefix limits the list to that page and those below it.
+func (r *CommunityWikiRepositoryImpl) GetPages(ctx context.Context, communityID int, prefix string) ([]model.CommunityWikiPage, error) {
+	var pages []model.CommunityWikiPage
+
+	q := r.db.Model(ctx, &model.CommunityWikiPage{}).
+		Select(communityWikiPageSummaryColumns).
+		Where("community_id = ?", communityID)
+	if prefix != "" {
+		q = q.Where("(path = ? OR path LIKE ?)", prefix, escapeLike(prefix)+"/%")
+	}
+
+	if err := q.Order("path asc").Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wiki pages: %w", err)
+	}
+	return pages, nil
+}
+
+// GetPagesAfter returns the pages of a community with an ID above afterID in ID order, for
+// walking every page in batches.
+func (r *CommunityWikiRepositoryImpl) GetPagesAfter(ctx context.Context, communityID, afterID, limit int) ([]model.CommunityWikiPage, error) {
+	var pages []model.CommunityWikiPage
+
+	err := r.db.Where(ctx, "community_id = ? AND id > ?", communityID, afterID).
+		Order("id asc").
+		Limit(limit).
+		Find(&pages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wiki pages: %w", err)
+	}
+	return pages, nil
+}
+
+// SavePage stores a new version of a page and its revision. It fails with ErrStaleRecord
+// when the page has moved past baseRevision, so concurrent edits do not overwrite each
+// other; on success page and revision carry the new revision number.
+func (r *CommunityWikiRepositoryImpl) SavePage(ctx context.Context, page *model.CommunityWikiPage, revision *model.CommunityWikiRevision, baseRevision int) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var current model.CommunityWikiPage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, page.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			return fmt.Errorf("failed to lock wiki page: %w", err)
+		}
+		if current.Revision != baseRevision {
+			return ErrStaleRecord
+		}
+
+		page.Revision = current.Revision + 1
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"title":          page.Title,
+			"content":        page.Content,
+			"content_html":   page.ContentHTML,
+			"revision":       page.Revision,
+			"last_editor_id": page.LastEditorID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update wiki page: %w", err)
+		}
+
+		revision.PageID, revision.CommunityID, revision.Revision = page.ID, current.CommunityID, page.Revision
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("failed to create wiki revision: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *CommunityWikiRepositoryImpl) SetEditPermission(ctx context.Context, id int, permission string) error {
+	if err := r.db.Model(ctx, &model.CommunityWikiPage{}).
+		Where("id = ?", id).
+		Update("edit_permission", permission).Error; err != nil {
+		return fmt.Errorf("failed to update wiki page permission: %w", err)
+	}
+	return nil
+}
+
+// DeletePage deletes a page and keeps its revisions. ErrHasChildren means pages are still
+// nested below it.
+func (r *CommunityWikiRepositoryImpl) DeletePage(ctx context.Context, id int) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var page model.CommunityWikiPage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&page, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			return fmt.Errorf("failed to lock wiki page: %w", err)
+		}
+
+		var children int64
+		if err := tx.Model(&model.CommunityWikiPage{}).
+			Where("community_id = ? AND path LIKE ?", page.CommunityID, escapeLike(page.Path)+"/%").
+			Count(&children).Error; err != nil {
+			return fmt.Errorf("failed to count wiki subpages: %w", err)
+		}
+		if children > 0 {
+			return ErrHasChildren
+		}
+
+		if err := tx.Delete(&page).Error; err != nil {
+			return fmt.Errorf("failed to delete wiki page: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetRevisions lists the revisions of a page without their content, newest first.
+func (r *CommunityWikiRepositoryImpl) GetRevisions(ctx context.Context, pageID, offset, limit int) ([]CommunityWikiRevisionRow, int64, error) {
+	var rows []CommunityWikiRevisionRow
+	var total int64
+
+	query := func() *gorm.DB {
+		return r.db.Model(ctx, &model.CommunityWikiRevision{}).
+			Joins("LEFT JOIN users u ON u.id = community_wiki_revisions.editor_id").
+			Where("community_wiki_revisions.page_id = ?", pageID)
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count wiki revisions: %w", err)
+	}
+
+	err := query().
+		Select("community_wiki_revisions.id, community_wiki_revisions.revision, community_wiki_revisions.editor_id, " +
+			"community_wiki_revisions.action, community_wiki_revisions.title, community_wiki_revisions.summary, " +
+			"community_wiki_revisions.reverted_from, community_wiki_revisions.created_at, " +
+			"COALESCE(u.username, '') AS username, COALESCE(u.displayname, '') AS displayname, " +
+			"COALESCE(u.profile_picture, '') AS profile_picture").
+		Order("community_wiki_revisions.revision desc").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get wiki revisions: %w", err)
+	}
+
+	return rows, total, nil
+}
+
+func (r *CommunityWikiRepositoryImpl) GetRevision(ctx context.Context, pageID, revision int) (*model.CommunityWikiRevision, error) {
+	var rev model.CommunityWikiRevision
+	if err := r.db.Where(ctx, "page_id = ? AND revision = ?", pageID, revision).First(&rev).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wiki revision: %w", err)
+	}
+	return &rev, nil
+}
@@ -16,6 +16,8 @@ type ConversationRepository interface {
 	AddParticipant(ctx context.Context, participant *model.Participant) error
 	AddMessage(ctx context.Context, message *model.Message) error
 	GetMessagesByConversationID(ctx context.Context, conversationID int) ([]model.Message, error)
+	GetParticipantByID(ctx context.Context, id int) (*model.Participant, error)
+	GetParticipantsByConversationID(ctx context.Context, conversationID int) ([]model.Participant, error)
 }
 
 type ConversationRepositoryImpl struct {
@@ -38,7 +40,7 @@ func (r *ConversationRepositoryImpl) CreateConversation(ctx context.Context, con
 func (r *ConversationRepositoryImpl) GetConversationsByUserID(ctx context.Context, userID int) ([]model.Conversation, error) {
 	var conversations []model.Conversation
 
-	err := r.db.Where(ctx, "user_id = ?", userID).Find(&conversations)
+	err := r.db.Where(ctx, "user_id = ?", userID).Find(&conversations).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversations: %w", err)
 	}
@@ -81,10 +83,31 @@ func (r *ConversationRepositoryImpl) AddParticipant(ctx context.Context, partici
 func (r *ConversationRepositoryImpl) GetMessagesByConversationID(ctx context.Context, conversationID int) ([]model.Message, error) {
 	var messages []model.Message
 
-	err := r.db.Where(ctx, "conversation_id = ?", conversationID).Find(&messages)
+	err := r.db.Where(ctx, "conversation_id = ?", conversationID).Find(&messages).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get messages: %w", err)
 	}
 
 	return messages, nil
 }
+
+func (r *ConversationRepositoryImpl) GetParticipantByID(ctx context.Context, id int) (*model.Participant, error) {
+	var participant model.Participant
+
+	if err := r.db.First(ctx, &participant, id); err != nil {
+		return nil, fmt.Errorf("failed to get participant: %w", err)
+	}
+
+	return &participant, nil
+}
+
+func (r *ConversationRepositoryImpl) GetParticipantsByConversationID(ctx context.Context, conversationID int) ([]model.Participant, error) {
+	var participants []model.Participant
+
+	err := r.db.Where(ctx, "conversation_id = ?", conversationID).Find(&participants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get participants: %w", err)
+	}
+
+	return participants, nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrDuplicateRecord = errors.New("duplicate record")
+	// ErrStaleRecord is returned when a conditional update matched no row because the
+	// record changed since it was read.
+	ErrStaleRecord = errors.New("stale record")
+	// ErrLimitReached is returned when an insert would take a parent record over the
+	// number of children it may have.
+	ErrLimitReached = errors.New("limit reached")
+	// ErrMissingParent is returned when a record is inserted below a parent that does not exist.
+	ErrMissingParent = errors.New("missing parent record")
+	// ErrHasChildren is returned when a record cannot be deleted while others sit below it.
+	ErrHasChildren = errors.New("record has children")
+)
+
+// isUniqueViolation reports whether err comes from a Postgres unique constraint.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
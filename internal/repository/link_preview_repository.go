@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LinkPreviewRepository interface {
+	GetLinkPreviewByPostID(ctx context.Context, postID int) (*model.LinkPreview, error)
+	QueueLinkPreview(ctx context.Context, postID int, url string) error
+	GetPendingLinkPreviews(ctx context.Context, limit int) ([]model.LinkPreview, error)
+	UpdateLinkPreview(ctx context.Context, preview *model.LinkPreview) error
+	DeleteLinkPreviewByPostID(ctx context.Context, postID int) error
+}
+
+type LinkPreviewRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewLinkPreviewRepository(db database.PostgresWrapper) LinkPreviewRepository {
+	return &LinkPreviewRepositoryImpl{
+		db: db,
+	}
+}
+
+func (r *LinkPreviewRepositoryImpl) GetLinkPreviewByPostID(ctx context.Context, postID int) (*model.LinkPreview, error) {
+	var preview model.LinkPreview
+
+	err := r.db.Where(ctx, "post_id = ?", postID).First(&preview).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get link preview: %w", err)
+	}
+
+	return &preview, nil
+}
+
+// QueueLinkPreview (re)sets the preview of a post to a pending fetch of url,
+// discarding whatever was previously extracted for it.
+func (r *LinkPreviewRepositoryImpl) QueueLinkPreview(ctx context.Context, postID int, url string) error {
+	preview := model.LinkPreview{
+		PostID: postID,
+		URL:    url,
+		Status: constant.LinkPreviewStatusPending,
+	}
+
+	err := r.db.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "post_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"url":         url,
+			"title":       "",
+			"description": "",
+			"image_url":   "",
+			"site_name":   "",
+			"status":      constant.LinkPreviewStatusPending,
+			"attempts":    0,
+			"fetched_at":  nil,
+			"updated_at":  gorm.Expr("NOW()"),
+		}),
+	}).Create(&preview).Error
+	if err != nil {
+		return fmt.Errorf("failed to queue link preview: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LinkPreviewRepositoryImpl) GetPendingLinkPreviews(ctx context.Context, limit int) ([]model.LinkPreview, error) {
+	var previews []model.LinkPreview
+
+	q := r.db.Where(ctx, "status = ?", constant.LinkPreviewStatusPending).
+		Order("updated_at asc").
+		Limit(limit)
+
+	if err := q.Find(&previews).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pending link previews: %w", err)
+	}
+
+	return previews, nil
+}
+
+func (r *LinkPreviewRepositoryImpl) UpdateLinkPreview(ctx context.Context, preview *model.LinkPreview) error {
+	if err := r.db.Save(ctx, preview); err != nil {
+		return fmt.Errorf("failed to update link preview: %w", err)
+	}
+	return nil
+}
+
+func (r *LinkPreviewRepositoryImpl) DeleteLinkPreviewByPostID(ctx context.Context, postID int) error {
+	if err := r.db.Where(ctx, "post_id = ?", postID).Delete(&model.LinkPreview{}).Error; err != nil {
+		return fmt.Errorf("failed to delete link preview: %w", err)
+	}
+	return nil
+}
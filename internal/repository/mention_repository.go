@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm/clause"
+)
+
+type MentionCandidate struct {
+	ID             int    `json:"id"`
+	Username       string `json:"username"`
+	Displayname    string `json:"displayname"`
+	ProfilePicture string `json:"profile_picture"`
+	Score          int    `json:"score"`
+}
+
+type MentionRepository interface {
+	CreateMention(ctx context.Context, mention *model.Mention) (bool, error)
+	GetMentionCandidates(ctx context.Context, viewerID int, query string, limit int) ([]MentionCandidate, error)
+}
+
+type MentionRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewMentionRepository(db database.PostgresWrapper) MentionRepository {
+	return &MentionRepositoryImpl{db: db}
+}
+
+// CreateMention stores a mention and reports whether it is new; re-saving the same
+// source (e.g. on edit) does not mention the same user twice.
+func (r *MentionRepositoryImpl) CreateMention(ctx context.Context, mention *model.Mention) (bool, error) {
+	q := r.db.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(mention)
+	if q.Error != nil {
+		return false, fmt.Errorf("failed to create mention: %w", q.Error)
+	}
+	return q.RowsAffected > 0, nil
+}
+
+// GetMentionCandidates ranks users whose username or display name starts with query:
+// people the viewer follows first, then people following the viewer, then by the number
+// of communities they share. Users on either side of a block are never suggested.
+func (r *MentionRepositoryImpl) GetMentionCandidates(ctx context.Context, viewerID int, query string, limit int) ([]MentionCandidate, error) {
+	var candidates []MentionCandidate
+
+	rawQuery := `
+		SELECT u.id, u.username, u.displayname, u.profile_picture,
+			(CASE WHEN EXISTS (
+				SELECT 1 FROM user_follows f
+				WHERE f.follower_id = @viewer AND f.following_id = u.id AND f.deleted_at IS NULL
+			) THEN 4 ELSE 0 END)
+			+ (CASE WHEN EXISTS (
+				SELECT 1 FROM user_follows f
+				WHERE f.follower_id = u.id AND f.following_id = @viewer AND f.deleted_at IS NULL
+			) THEN 2 ELSE 0 END)
+			+ LEAST((
+				SELECT COUNT(*) FROM community_members mine
+				INNER JOIN community_members theirs ON theirs.community_id = mine.community_id
+				WHERE mine.user_id = @viewer AND theirs.user_id = u.id
+					AND mine.deleted_at IS NULL AND theirs.deleted_at IS NULL
+			), 3) AS score
+		FROM users u
+		WHERE u.deleted_at IS NULL
+			AND u.id <> @viewer
+			AND (LOWER(u.username) LIKE @prefix OR LOWER(u.displayname) LIKE @prefix)
+			AND NOT EXISTS (
+				SELECT 1 FROM user_blocks ub
+				WHERE (ub.blocker_id = u.id AND ub.blocked_id = @viewer)
+					OR (ub.blocker_id = @viewer AND ub.blocked_id = u.id)
+			)
+		ORDER BY score DESC, u.username ASC
+		LIMIT @limit
+	`
+
+	prefix := escapeLike(strings.ToLower(query)) + "%"
+	args := map[string]interface{}{
+		"viewer": viewerID,
+		"prefix": prefix,
+		"limit":  limit,
+	}
+
+	if err := r.db.DB.WithContext(ctx).Raw(rawQuery, args).Scan(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get mention candidates: %w", err)
+	}
+
+	return candidates, nil
+}
@@ -12,6 +12,7 @@ type ModeratorRepository interface {
 	CreateModerator(ctx context.Context, moderator *model.Moderator) error
 	GetModeratorsByCommunityID(ctx context.Context, communityId int) ([]model.Moderator, error)
 	DeleteModerator(ctx context.Context, id int) error
+	IsCommunityModerator(ctx context.Context, communityID, userID int) (bool, error)
 }
 
 type ModeratorRepositoryImpl struct {
@@ -34,7 +35,7 @@ func (r *ModeratorRepositoryImpl) CreateModerator(ctx context.Context, moderator
 func (r *ModeratorRepositoryImpl) GetModeratorsByCommunityID(ctx context.Context, communityId int) ([]model.Moderator, error) {
 	var moderators []model.Moderator
 
-	if err := r.db.Where(ctx, &moderators, "community_id = ?", communityId); err != nil {
+	if err := r.db.Where(ctx, "community_id = ?", communityId).Find(&moderators).Error; err != nil {
 		return nil, fmt.Errorf("failed to get moderators: %w", err)
 	}
 
@@ -47,3 +48,17 @@ func (r *ModeratorRepositoryImpl) DeleteModerator(ctx context.Context, id int) e
 	}
 	return nil
 }
+
+func (r *ModeratorRepositoryImpl) IsCommunityModerator(ctx context.Context, communityID, userID int) (bool, error) {
+	var count int64
+
+	err := r.db.Model(ctx, &model.Moderator{}).
+		Joins("JOIN community_members cm ON cm.id = moderators.communitymember_id AND cm.deleted_at IS NULL").
+		Where("moderators.community_id = ? AND cm.user_id = ?", communityID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check community moderator: %w", err)
+	}
+
+	return count > 0, nil
+}
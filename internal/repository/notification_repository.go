@@ -33,7 +33,8 @@ func (r *NotificationRepositoryImpl) CreateNotification(ctx context.Context, not
 func (r *NotificationRepositoryImpl) GetNotificationsByUserID(ctx context.Context, userId int) ([]model.Notification, error) {
 	var notifications []model.Notification
 
-	if err := r.db.Where(ctx, &notifications, "user_id = ?", userId); err != nil {
+	q := r.db.Where(ctx, "user_id = ?", userId).Order("created_at desc")
+	if err := q.Find(&notifications).Error; err != nil {
 		return nil, fmt.Errorf("failed to get notifications: %w", err)
 	}
 
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+)
+
+type PollRepository interface {
+	GetPollByID(ctx context.Context, id int) (*model.Poll, error)
+	GetPollByPostID(ctx context.Context, postID int) (*model.Poll, error)
+	GetBallot(ctx context.Context, pollID, userID int) (*model.PollBallot, error)
+	GetBallotsByPollID(ctx context.Context, pollID int) ([]model.PollBallot, error)
+	CastBallot(ctx context.Context, ballot *model.PollBallot, optionIDs []int) error
+}
+
+type PollRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewPollRepository(db database.PostgresWrapper) PollRepository {
+	return &PollRepositoryImpl{db: db}
+}
+
+func (r *PollRepositoryImpl) GetPollByID(ctx context.Context, id int) (*model.Poll, error) {
+	var poll model.Poll
+
+	q := r.db.Where(ctx, "id = ?", id).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position asc") })
+
+	if err := q.First(&poll).Error; err != nil {
+		return nil, fmt.Errorf("failed to get poll by id: %w", err)
+	}
+
+	return &poll, nil
+}
+
+func (r *PollRepositoryImpl) GetPollByPostID(ctx context.Context, postID int) (*model.Poll, error) {
+	var poll model.Poll
+
+	q := r.db.Where(ctx, "post_id = ?", postID).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position asc") })
+
+	if err := q.First(&poll).Error; err != nil {
+		return nil, fmt.Errorf("failed to get poll by post id: %w", err)
+	}
+
+	return &poll, nil
+}
+
+func (r *PollRepositoryImpl) GetBallot(ctx context.Context, pollID, userID int) (*model.PollBallot, error) {
+	var ballot model.PollBallot
+
+	err := r.db.Where(ctx, "poll_id = ? AND user_id = ?", pollID, userID).
+		Preload("Votes").
+		First(&ballot).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get poll ballot: %w", err)
+	}
+
+	return &ballot, nil
+}
+
+func (r *PollRepositoryImpl) GetBallotsByPollID(ctx context.Context, pollID int) ([]model.PollBallot, error) {
+	var ballots []model.PollBallot
+
+	q := r.db.Where(ctx, "poll_id = ?", pollID).Preload("Votes")
+
+	if err := q.Find(&ballots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get poll ballots: %w", err)
+	}
+
+	return ballots, nil
+}
+
+// CastBallot stores the ballot, its votes and the tally increments in one transaction.
+// A second ballot from the same user trips the unique index and yields ErrDuplicateRecord.
+func (r *PollRepositoryImpl) CastBallot(ctx context.Context, ballot *model.PollBallot, optionIDs []int) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(ballot).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrDuplicateRecord
+			}
+			return fmt.Errorf("failed to create poll ballot: %w", err)
+		}
+
+		for _, optionID := range optionIDs {
+			vote := model.PollVote{
+				BallotID: ballot.ID,
+				PollID:   ballot.PollID,
+				OptionID: optionID,
+			}
+			if err := tx.Create(&vote).Error; err != nil {
+				if isUniqueViolation(err) {
+					return ErrDuplicateRecord
+				}
+				return fmt.Errorf("failed to create poll vote: %w", err)
+			}
+
+			res := tx.Model(&model.PollOption{}).
+				Where("id = ? AND poll_id = ?", optionID, ballot.PollID).
+				Update("votes_count", gorm.Expr("votes_count + 1"))
+			if res.Error != nil {
+				return fmt.Errorf("failed to update poll option tally: %w", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("poll option %d does not belong to poll %d", optionID, ballot.PollID)
+			}
+		}
+
+		if err := tx.Model(&model.Poll{}).
+			Where("id = ?", ballot.PollID).
+			Update("voters_count", gorm.Expr("voters_count + 1")).Error; err != nil {
+			return fmt.Errorf("failed to update poll voters count: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -2,18 +2,41 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/model"
 	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
 )
 
 type PostRepository interface {
 	CreatePost(ctx context.Context, post *model.Post) error
+	CreatePostWithPoll(ctx context.Context, post *model.Post, poll *model.Poll) error
 	GetPostDetailByID(ctx context.Context, id int) (*model.Post, error)
-	GetPostsByUserID(ctx context.Context, userId int) ([]model.Post, error)
+	GetPostsByUserID(ctx context.Context, userId, viewerID int) ([]model.Post, error)
+	GetPostsByIDs(ctx context.Context, ids []int, viewerID int) ([]model.Post, error)
+	IsPostVisibleTo(ctx context.Context, postID, viewerID int) (bool, error)
+	CanInteractWithPost(ctx context.Context, postID, userID int) (bool, error)
+	GetRepostsByOriginalID(ctx context.Context, originalID int) ([]model.Post, error)
+	FindRepost(ctx context.Context, userID, originalID, communityID int) (*model.Post, error)
+	DeleteRepostsByOriginalID(ctx context.Context, originalID int) error
+	UpdatePostSharesCount(ctx context.Context, id int, delta int) error
+	AddPostLike(ctx context.Context, postID, userID int) (bool, error)
 	UpdatePost(ctx context.Context, id int, post *model.Post) error
 	DeletePost(ctx context.Context, id int) error
+	GetUnpublishedPostsByUserID(ctx context.Context, userId int) ([]model.Post, error)
+	GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]model.Post, error)
+	MarkPostPublished(ctx context.Context, id int, fromStatus string, publishedAt time.Time) (bool, error)
+	ReschedulePost(ctx context.Context, id int, status string, scheduledAt *time.Time) (bool, error)
+	SetPostLocked(ctx context.Context, id int, locked bool, entry *model.PostModerationLog) error
+	UpdateCommentSettings(ctx context.Context, id int, updates map[string]interface{}, entry *model.PostModerationLog) error
+	GetPostModerationLogs(ctx context.Context, postID int) ([]model.PostModerationLog, error)
+	PinPost(ctx context.Context, communityID, postID, maxPinned int, entry *model.PostModerationLog) error
+	UnpinPost(ctx context.Context, communityID, postID int, entry *model.PostModerationLog) (bool, error)
+	ReorderPinnedPosts(ctx context.Context, communityID int, postIDs []int) error
 }
 
 type PostRepositoryImpl struct {
@@ -24,17 +47,42 @@ func NewPostRepository(db database.PostgresWrapper) PostRepository {
 	return &PostRepositoryImpl{db: db}
 }
 
+// CreatePost returns ErrDuplicateRecord for a plain repost the user already made in the
+// same place, which the idx_posts_plain_repost index rejects.
 func (r *PostRepositoryImpl) CreatePost(ctx context.Context, post *model.Post) error {
 	if err := r.db.Create(ctx, post); err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateRecord
+		}
 		return fmt.Errorf("failed to create post: %w", err)
 	}
 	return nil
 }
 
+// CreatePostWithPoll creates post and the poll attached to it in one transaction, so a
+// post never exists without the poll it was submitted with.
+func (r *PostRepositoryImpl) CreatePostWithPoll(ctx context.Context, post *model.Post, poll *model.Poll) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return fmt.Errorf("failed to create post: %w", err)
+		}
+
+		poll.PostID = post.ID
+		if err := tx.Create(poll).Error; err != nil {
+			return fmt.Errorf("failed to create poll: %w", err)
+		}
+		return nil
+	})
+}
+
 func (r *PostRepositoryImpl) GetPostDetailByID(ctx context.Context, id int) (*model.Post, error) {
 	var post model.Post
 
-	if err := r.db.First(ctx, &post, id); err != nil {
+	q := r.db.Where(ctx, "id = ?", id).
+		Preload("LinkPreview", "status = ?", constant.LinkPreviewStatusReady).
+		Preload("Flair")
+
+	if err := q.First(&post).Error; err != nil {
 		return nil, fmt.Errorf("failed to get post detail: %w", err)
 	}
 
@@ -58,10 +106,15 @@ func (r *PostRepositoryImpl) UpdatePost(ctx context.Context, id int, post *model
 	return nil
 }
 
-func (r *PostRepositoryImpl) GetPostsByUserID(ctx context.Context, userId int) ([]model.Post, error) {
+// GetPostsByUserID returns the published posts of userId that viewerID is allowed to see.
+func (r *PostRepositoryImpl) GetPostsByUserID(ctx context.Context, userId, viewerID int) ([]model.Post, error) {
 	var posts []model.Post
 
-	q := r.db.Where(ctx, "user_id = ?", userId)
+	q := r.db.Where(ctx, "user_id = ? AND status = ?", userId, constant.PostStatusPublished).
+		Scopes(visiblePostsTo(viewerID)).
+		Preload("RepostOf", visiblePostsTo(viewerID)).
+		Preload("LinkPreview", "status = ?", constant.LinkPreviewStatusReady).
+		Preload("Flair")
 
 	if err := q.Find(&posts).Error; err != nil {
 		return nil, fmt.Errorf("failed to get posts by user id: %w", err)
@@ -69,3 +122,336 @@ func (r *PostRepositoryImpl) GetPostsByUserID(ctx context.Context, userId int) (
 
 	return posts, nil
 }
+
+func (r *PostRepositoryImpl) GetUnpublishedPostsByUserID(ctx context.Context, userId int) ([]model.Post, error) {
+	var posts []model.Post
+
+	q := r.db.Where(ctx, "user_id = ? AND status IN ?", userId, []string{constant.PostStatusDraft, constant.PostStatusScheduled}).
+		Order("updated_at desc")
+
+	if err := q.Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get unpublished posts by user id: %w", err)
+	}
+
+	return posts, nil
+}
+
+func (r *PostRepositoryImpl) GetDueScheduledPosts(ctx context.Context, now time.Time, limit int) ([]model.Post, error) {
+	var posts []model.Post
+
+	q := r.db.Where(ctx, "status = ? AND scheduled_at <= ?", constant.PostStatusScheduled, now).
+		Order("scheduled_at asc").
+		Limit(limit)
+
+	if err := q.Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get due scheduled posts: %w", err)
+	}
+
+	return posts, nil
+}
+
+// MarkPostPublished flips a post to published only if it is still in fromStatus,
+// so concurrent publishers can tell which one of them actually won.
+func (r *PostRepositoryImpl) MarkPostPublished(ctx context.Context, id int, fromStatus string, publishedAt time.Time) (bool, error) {
+	q := r.db.Model(ctx, &model.Post{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(map[string]interface{}{
+			"status":       constant.PostStatusPublished,
+			"published_at": publishedAt,
+		})
+
+	if q.Error != nil {
+		return false, fmt.Errorf("failed to mark post published: %w", q.Error)
+	}
+
+	return q.RowsAffected == 1, nil
+}
+
+// ReschedulePost moves an unpublished post between draft and scheduled. The columns go
+// through a map so a nil scheduledAt clears scheduled_at, and a post the scheduler has
+// published in the meantime is left alone.
+func (r *PostRepositoryImpl) ReschedulePost(ctx context.Context, id int, status string, scheduledAt *time.Time) (bool, error) {
+	q := r.db.Model(ctx, &model.Post{}).
+		Where("id = ? AND status <> ?", id, constant.PostStatusPublished).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"scheduled_at": scheduledAt,
+		})
+
+	if q.Error != nil {
+		return false, fmt.Errorf("failed to reschedule post: %w", q.Error)
+	}
+
+	return q.RowsAffected == 1, nil
+}
+
+func (r *PostRepositoryImpl) GetPostsByIDs(ctx context.Context, ids []int, viewerID int) ([]model.Post, error) {
+	var posts []model.Post
+
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	q := r.db.Where(ctx, "id IN ?", ids).
+		Scopes(visiblePostsTo(viewerID)).
+		Preload("LinkPreview", "status = ?", constant.LinkPreviewStatusReady).
+		Preload("Flair")
+
+	if err := q.Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get posts by ids: %w", err)
+	}
+
+	return posts, nil
+}
+
+func (r *PostRepositoryImpl) GetRepostsByOriginalID(ctx context.Context, originalID int) ([]model.Post, error) {
+	var posts []model.Post
+
+	if err := r.db.Where(ctx, "repost_of_id = ?", originalID).Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reposts: %w", err)
+	}
+
+	return posts, nil
+}
+
+// FindRepost returns the plain (non-quote) repost userID already made of originalID into communityID, if any.
+func (r *PostRepositoryImpl) FindRepost(ctx context.Context, userID, originalID, communityID int) (*model.Post, error) {
+	var post model.Post
+
+	err := r.db.Where(ctx, "user_id = ? AND repost_of_id = ? AND community_id = ? AND \"desc\" = ''", userID, originalID, communityID).
+		First(&post).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find repost: %w", err)
+	}
+
+	return &post, nil
+}
+
+func (r *PostRepositoryImpl) DeleteRepostsByOriginalID(ctx context.Context, originalID int) error {
+	if err := r.db.Where(ctx, "repost_of_id = ?", originalID).Delete(&model.Post{}).Error; err != nil {
+		return fmt.Errorf("failed to delete reposts: %w", err)
+	}
+	return nil
+}
+
+func (r *PostRepositoryImpl) UpdatePostSharesCount(ctx context.Context, id int, delta int) error {
+	if err := r.db.Model(ctx, &model.Post{}).
+		Where("id = ?", id).
+		Update("shares_count", gorm.Expr("GREATEST(shares_count + ?, 0)", delta)).Error; err != nil {
+		return fmt.Errorf("failed to update post shares count: %w", err)
+	}
+	return nil
+}
+
+// AddPostLike records that userID likes the post and reports whether the like is new.
+func (r *PostRepositoryImpl) AddPostLike(ctx context.Context, postID, userID int) (bool, error) {
+	q := r.db.DB.WithContext(ctx).Exec(
+		"INSERT INTO post_likes (post_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", postID, userID)
+	if q.Error != nil {
+		return false, fmt.Errorf("failed to add post like: %w", q.Error)
+	}
+	return q.RowsAffected > 0, nil
+}
+
+func (r *PostRepositoryImpl) IsPostVisibleTo(ctx context.Context, postID, viewerID int) (bool, error) {
+	var count int64
+
+	err := r.db.Model(ctx, &model.Post{}).
+		Where("posts.id = ?", postID).
+		Scopes(visiblePostsTo(viewerID)).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check post visibility: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// CanInteractWithPost reports whether userID may comment on, like, vote on or bookmark
+// the post: it must be visible to them and published, even for its author.
+func (r *PostRepositoryImpl) CanInteractWithPost(ctx context.Context, postID, userID int) (bool, error) {
+	var count int64
+
+	err := r.db.Model(ctx, &model.Post{}).
+		Where("posts.id = ? AND posts.status = ?", postID, constant.PostStatusPublished).
+		Scopes(visiblePostsTo(userID)).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check post visibility: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// SetPostLocked locks or unlocks a post and records entry in the same transaction.
+// ErrStaleRecord means the post was already in the requested state, so concurrent
+// requests record a single change.
+func (r *PostRepositoryImpl) SetPostLocked(ctx context.Context, id int, locked bool, entry *model.PostModerationLog) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(&model.Post{}).
+			Where("id = ? AND locked = ?", id, !locked).
+			Update("locked", locked)
+		if res.Error != nil {
+			return fmt.Errorf("failed to update post lock: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleRecord
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to create post moderation log: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *PostRepositoryImpl) UpdateCommentSettings(ctx context.Context, id int, updates map[string]interface{}, entry *model.PostModerationLog) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Post{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update comment settings: %w", err)
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to create post moderation log: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// PinPost pins a published post of a community after the posts already pinned there. The
+// community row is locked so concurrent pins cannot share a position or go over maxPinned,
+// which is reported as ErrLimitReached. ErrDuplicateRecord means the post is already pinned
+// and gorm.ErrRecordNotFound that it is not a published post of the community.
+func (r *PostRepositoryImpl) PinPost(ctx context.Context, communityID, postID, maxPinned int, entry *model.PostModerationLog) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := lockCommunity(tx, communityID); err != nil {
+			return err
+		}
+
+		var post model.Post
+		res := tx.Where("id = ? AND community_id = ? AND status = ?", postID, communityID, constant.PostStatusPublished).
+			Limit(1).Find(&post)
+		if res.Error != nil {
+			return fmt.Errorf("failed to get post: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if post.PinnedPosition != nil {
+			return ErrDuplicateRecord
+		}
+
+		var pinned int64
+		if err := tx.Model(&model.Post{}).
+			Where("community_id = ? AND pinned_position IS NOT NULL", communityID).
+			Count(&pinned).Error; err != nil {
+			return fmt.Errorf("failed to count pinned posts: %w", err)
+		}
+		if pinned >= int64(maxPinned) {
+			return ErrLimitReached
+		}
+
+		if err := tx.Model(&model.Post{}).Where("id = ?", postID).Updates(map[string]interface{}{
+			"pinned_position": pinned + 1,
+			"pinned_at":       time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to pin post: %w", err)
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to create post moderation log: %w", err)
+		}
+		return nil
+	})
+}
+
+// UnpinPost unpins a post and closes the gap it leaves among the pinned posts of its
+// community. It also finds deleted posts, so they can be unpinned on their way out. The
+// entry is optional; it reports false when the post was not pinned.
+func (r *PostRepositoryImpl) UnpinPost(ctx context.Context, communityID, postID int, entry *model.PostModerationLog) (bool, error) {
+	unpinned := false
+
+	err := r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := lockCommunity(tx, communityID); err != nil {
+			return err
+		}
+
+		var post model.Post
+		res := tx.Unscoped().
+			Where("id = ? AND community_id = ? AND pinned_position IS NOT NULL", postID, communityID).
+			Limit(1).Find(&post)
+		if res.Error != nil {
+			return fmt.Errorf("failed to get post: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Unscoped().Model(&model.Post{}).Where("id = ?", postID).Updates(map[string]interface{}{
+			"pinned_position": nil,
+			"pinned_at":       nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to unpin post: %w", err)
+		}
+		if err := tx.Unscoped().Model(&model.Post{}).
+			Where("community_id = ? AND pinned_position > ?", communityID, *post.PinnedPosition).
+			Update("pinned_position", gorm.Expr("pinned_position - 1")).Error; err != nil {
+			return fmt.Errorf("failed to renumber pinned posts: %w", err)
+		}
+
+		if entry != nil {
+			if err := tx.Create(entry).Error; err != nil {
+				return fmt.Errorf("failed to create post moderation log: %w", err)
+			}
+		}
+
+		unpinned = true
+		return nil
+	})
+
+	return unpinned, err
+}
+
+// ReorderPinnedPosts numbers the pinned posts of a community in the order of postIDs,
+// which must list every pinned post exactly once; ErrStaleRecord means it does not.
+func (r *PostRepositoryImpl) ReorderPinnedPosts(ctx context.Context, communityID int, postIDs []int) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := lockCommunity(tx, communityID); err != nil {
+			return err
+		}
+
+		var pinned []int
+		if err := tx.Model(&model.Post{}).
+			Where("community_id = ? AND pinned_position IS NOT NULL", communityID).
+			Pluck("id", &pinned).Error; err != nil {
+			return fmt.Errorf("failed to get pinned posts: %w", err)
+		}
+		if !sameIDs(pinned, postIDs) {
+			return ErrStaleRecord
+		}
+
+		for i, id := range postIDs {
+			if err := tx.Model(&model.Post{}).Where("id = ?", id).Update("pinned_position", i+1).Error; err != nil {
+				return fmt.Errorf("failed to reorder pinned posts: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostRepositoryImpl) GetPostModerationLogs(ctx context.Context, postID int) ([]model.PostModerationLog, error) {
+	var logs []model.PostModerationLog
+
+	if err := r.db.Where(ctx, "post_id = ?", postID).
+		Order("created_at DESC, id DESC").
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get post moderation logs: %w", err)
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostStatsTotals struct {
+	Views         int64 `json:"views"`
+	UniqueViewers int64 `json:"unique_viewers"`
+	Impressions   int64 `json:"impressions"`
+	Likes         int64 `json:"likes"`
+	Comments      int64 `json:"comments"`
+}
+
+type PostStatsRepository interface {
+	AddDailyStat(ctx context.Context, stat *model.PostDailyStat) error
+	IncrementPostCounters(ctx context.Context, postID int, views, impressions, uniqueViewers int64) error
+	GetDailyStats(ctx context.Context, postID int, from, to time.Time) ([]model.PostDailyStat, error)
+	GetPostStatsTotals(ctx context.Context, postID int) (*PostStatsTotals, error)
+}
+
+type PostStatsRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewPostStatsRepository(db database.PostgresWrapper) PostStatsRepository {
+	return &PostStatsRepositoryImpl{
+		db: db,
+	}
+}
+
+// AddDailyStat adds the counters of stat to the row of its post and day. UniqueViewers is
+// an estimate of the whole day rather than a delta, so the larger value is kept.
+func (r *PostStatsRepositoryImpl) AddDailyStat(ctx context.Context, stat *model.PostDailyStat) error {
+	err := r.db.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "post_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"views":          gorm.Expr("post_daily_stats.views + EXCLUDED.views"),
+			"impressions":    gorm.Expr("post_daily_stats.impressions + EXCLUDED.impressions"),
+			"likes":          gorm.Expr("post_daily_stats.likes + EXCLUDED.likes"),
+			"comments":       gorm.Expr("post_daily_stats.comments + EXCLUDED.comments"),
+			"unique_viewers": gorm.Expr("GREATEST(post_daily_stats.unique_viewers, EXCLUDED.unique_viewers)"),
+			"updated_at":     gorm.Expr("NOW()"),
+		}),
+	}).Create(stat).Error
+	if err != nil {
+		return fmt.Errorf("failed to add post daily stat: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostStatsRepositoryImpl) IncrementPostCounters(ctx context.Context, postID int, views, impressions, uniqueViewers int64) error {
+	err := r.db.Model(ctx, &model.Post{}).
+		Where("id = ?", postID).
+		UpdateColumns(map[string]interface{}{
+			"views_count":          gorm.Expr("views_count + ?", views),
+			"impressions_count":    gorm.Expr("impressions_count + ?", impressions),
+			"unique_viewers_count": gorm.Expr("GREATEST(unique_viewers_count, ?)", uniqueViewers),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to increment post counters: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostStatsRepositoryImpl) GetDailyStats(ctx context.Context, postID int, from, to time.Time) ([]model.PostDailyStat, error) {
+	var stats []model.PostDailyStat
+
+	q := r.db.Where(ctx, "post_id = ? AND date BETWEEN ? AND ?", postID, from, to).
+		Order("date asc")
+
+	if err := q.Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get post daily stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *PostStatsRepositoryImpl) GetPostStatsTotals(ctx context.Context, postID int) (*PostStatsTotals, error) {
+	var totals PostStatsTotals
+
+	err := r.db.DB.WithContext(ctx).Raw(`
+		SELECT
+			p.views_count AS views,
+			p.unique_viewers_count AS unique_viewers,
+			p.impressions_count AS impressions,
+			(SELECT COUNT(*) FROM post_likes pl WHERE pl.post_id = p.id) AS likes,
+			(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL) AS comments
+		FROM posts p
+		WHERE p.id = ?`, postID).Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post stats totals: %w", err)
+	}
+
+	return &totals, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"github.com/temuka-api-service/internal/constant"
+	"gorm.io/gorm"
+)
+
+// visiblePostsTo restricts a query on posts to the rows viewerID may read. Every read
+// path that lists or loads posts for a user goes through it so the visibility rules
+// live in one place. A viewerID of 0 stands for an anonymous viewer and only sees
+// public posts. Drafts and scheduled posts are only visible to their author, and
+// university-verified posts to users whose university membership has been verified.
+// Posts of a private community are only visible to its members, whatever their own
+// visibility, and posts of a deleted community to nobody.
+func visiblePostsTo(viewerID int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`(
+			posts.status = @published OR (@viewer <> 0 AND posts.user_id = @viewer)
+		) AND (
+			posts.visibility = @public
+			OR (@viewer <> 0 AND posts.user_id = @viewer)
+			OR (posts.visibility = @followers AND EXISTS (
+				SELECT 1 FROM user_follows uf
+				WHERE uf.follower_id = @viewer AND uf.following_id = posts.user_id AND uf.deleted_at IS NULL))
+			OR (posts.visibility = @community AND EXISTS (
+				SELECT 1 FROM community_members cm
+				WHERE cm.community_id = posts.community_id AND cm.user_id = @viewer
+					AND cm.banned = false AND cm.deleted_at IS NULL))
+			OR (posts.visibility = @verified AND EXISTS (
+				SELECT 1 FROM users u
+				WHERE u.id = @viewer AND u.university_verified_at IS NOT NULL AND u.deleted_at IS NULL))
+		) AND (
+			NOT EXISTS (
+				SELECT 1 FROM communities c
+				WHERE c.id = posts.community_id AND c.visibility = @private AND c.deleted_at IS NULL)
+			OR (@viewer <> 0 AND posts.user_id = @viewer)
+			OR EXISTS (
+				SELECT 1 FROM community_members cm
+				WHERE cm.community_id = posts.community_id AND cm.user_id = @viewer
+					AND cm.banned = false AND cm.deleted_at IS NULL)
+		) AND NOT EXISTS (
+			SELECT 1 FROM communities c
+			WHERE c.id = posts.community_id AND c.deleted_at IS NOT NULL
+		)`, map[string]interface{}{
+			"viewer":    viewerID,
+			"published": constant.PostStatusPublished,
+			"public":    constant.PostVisibilityPublic,
+			"followers": constant.PostVisibilityFollowers,
+			"community": constant.PostVisibilityCommunity,
+			"verified":  constant.PostVisibilityUniversityVerified,
+			"private":   constant.CommunityVisibilityPrivate,
+		})
+	}
+}
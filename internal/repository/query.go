@@ -0,0 +1,10 @@
+package repository
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the LIKE wildcards in user input so it is matched literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
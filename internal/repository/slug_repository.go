@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SlugRepository interface {
+	IsSlugAvailable(ctx context.Context, entityType, slug string, entityID int) (bool, error)
+	ChangeSlug(ctx context.Context, entityType string, entityID int, slug string) error
+	GetSlugRedirect(ctx context.Context, entityType, slug string) (*model.SlugHistory, error)
+}
+
+type SlugRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewSlugRepository(db database.PostgresWrapper) SlugRepository {
+	return &SlugRepositoryImpl{db: db}
+}
+
+// sluggedModel returns the model whose table holds the slugs of entityType.
+func sluggedModel(entityType string) (interface{}, error) {
+	switch entityType {
+	case constant.SlugEntityCommunity:
+		return &model.Community{}, nil
+	case constant.SlugEntityUniversity:
+		return &model.University{}, nil
+	default:
+		return nil, fmt.Errorf("unknown slug entity type %q", entityType)
+	}
+}
+
+// IsSlugAvailable reports whether slug is free for the entity entityID, which may be 0 for
+// one that does not exist yet. Slugs of deleted entities and retired slugs of other entities
+// count as taken.
+func (r *SlugRepositoryImpl) IsSlugAvailable(ctx context.Context, entityType, slug string, entityID int) (bool, error) {
+	table, err := sluggedModel(entityType)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := r.db.Model(ctx, table).Unscoped().
+		Where("slug = ? AND id <> ?", slug, entityID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check slug: %w", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if err := r.db.Model(ctx, &model.SlugHistory{}).
+		Where("entity_type = ? AND slug = ? AND entity_id <> ?", entityType, slug, entityID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check slug history: %w", err)
+	}
+
+	return count == 0, nil
+}
+
+// ChangeSlug gives an entity a new slug and keeps its previous one in the slug history.
+// ErrDuplicateRecord means another entity took slug in the meantime.
+func (r *SlugRepositoryImpl) ChangeSlug(ctx context.Context, entityType string, entityID int, slug string) error {
+	table, err := sluggedModel(entityType)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var previous string
+		if err := tx.Model(table).Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", entityID).
+			Pluck("slug", &previous).Error; err != nil {
+			return fmt.Errorf("failed to get slug: %w", err)
+		}
+		if previous == slug {
+			return nil
+		}
+
+		// An entity taking back one of its own retired slugs.
+		if err := tx.Where("entity_type = ? AND slug = ? AND entity_id = ?", entityType, slug, entityID).
+			Delete(&model.SlugHistory{}).Error; err != nil {
+			return fmt.Errorf("failed to reclaim slug: %w", err)
+		}
+
+		if err := tx.Model(table).Unscoped().Where("id = ?", entityID).Update("slug", slug).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrDuplicateRecord
+			}
+			return fmt.Errorf("failed to change slug: %w", err)
+		}
+
+		if previous == "" {
+			return nil
+		}
+		history := model.SlugHistory{EntityType: entityType, Slug: previous, EntityID: entityID}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "entity_type"}, {Name: "slug"}},
+			DoUpdates: clause.AssignmentColumns([]string{"entity_id"}),
+		}).Create(&history).Error; err != nil {
+			return fmt.Errorf("failed to record slug history: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *SlugRepositoryImpl) GetSlugRedirect(ctx context.Context, entityType, slug string) (*model.SlugHistory, error) {
+	var history model.SlugHistory
+	if err := r.db.Where(ctx, "entity_type = ? AND slug = ?", entityType, slug).First(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to get slug redirect: %w", err)
+	}
+	return &history, nil
+}
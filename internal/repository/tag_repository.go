@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TrendingTag struct {
+	Name       string `json:"name"`
+	UsageCount int64  `json:"usage_count"`
+}
+
+type TagRepository interface {
+	SyncPostTags(ctx context.Context, postID int, names []string) error
+	GetTagByName(ctx context.Context, name string) (*model.Tag, error)
+	GetTagNamesByPostID(ctx context.Context, postID int) ([]string, error)
+	GetPostsByTagID(ctx context.Context, tagID, viewerID, offset, limit int) ([]model.Post, int64, error)
+	GetTrendingTags(ctx context.Context, since time.Time, limit int) ([]TrendingTag, error)
+}
+
+type TagRepositoryImpl struct {
+	db database.PostgresWrapper
+}
+
+func NewTagRepository(db database.PostgresWrapper) TagRepository {
+	return &TagRepositoryImpl{db: db}
+}
+
+// SyncPostTags makes the post's tag set exactly names, creating missing tags on the fly.
+func (r *TagRepositoryImpl) SyncPostTags(ctx context.Context, postID int, names []string) error {
+	return r.db.Transaction(ctx, func(tx *gorm.DB) error {
+		var tagIDs []int
+
+		if len(names) > 0 {
+			tags := make([]model.Tag, 0, len(names))
+			for _, name := range names {
+				tags = append(tags, model.Tag{Name: name})
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+				return fmt.Errorf("failed to create tags: %w", err)
+			}
+
+			if err := tx.Model(&model.Tag{}).Where("name IN ?", names).Pluck("id", &tagIDs).Error; err != nil {
+				return fmt.Errorf("failed to get tag ids: %w", err)
+			}
+		}
+
+		del := tx.Where("post_id = ?", postID)
+		if len(tagIDs) > 0 {
+			del = del.Where("tag_id NOT IN ?", tagIDs)
+		}
+		if err := del.Delete(&model.PostTag{}).Error; err != nil {
+			return fmt.Errorf("failed to remove post tags: %w", err)
+		}
+
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		postTags := make([]model.PostTag, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			postTags = append(postTags, model.PostTag{PostID: postID, TagID: tagID})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&postTags).Error; err != nil {
+			return fmt.Errorf("failed to add post tags: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *TagRepositoryImpl) GetTagByName(ctx context.Context, name string) (*model.Tag, error) {
+	var tag model.Tag
+
+	if err := r.db.Where(ctx, "name = ?", name).First(&tag).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tag by name: %w", err)
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepositoryImpl) GetTagNamesByPostID(ctx context.Context, postID int) ([]string, error) {
+	var names []string
+
+	err := r.db.Model(ctx, &model.Tag{}).
+		Joins("INNER JOIN post_tags pt ON pt.tag_id = tags.id").
+		Where("pt.post_id = ?", postID).
+		Order("tags.name asc").
+		Pluck("tags.name", &names).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post tag names: %w", err)
+	}
+
+	return names, nil
+}
+
+func (r *TagRepositoryImpl) GetPostsByTagID(ctx context.Context, tagID, viewerID, offset, limit int) ([]model.Post, int64, error) {
+	var posts []model.Post
+	var total int64
+
+	query := func() *gorm.DB {
+		return r.db.Model(ctx, &model.Post{}).
+			Joins("INNER JOIN post_tags pt ON pt.post_id = posts.id").
+			Where("pt.tag_id = ? AND posts.status = ?", tagID, constant.PostStatusPublished).
+			Scopes(visiblePostsTo(viewerID))
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count posts by tag: %w", err)
+	}
+
+	if err := query().Order("posts.created_at desc").Offset(offset).Limit(limit).Find(&posts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get posts by tag: %w", err)
+	}
+
+	return posts, total, nil
+}
+
+// GetTrendingTags counts the tags of public posts published since the given time. Like
+// visiblePostsTo, it leaves out posts of private and deleted communities, so trending never
+// hints at what is posted behind a membership wall.
+func (r *TagRepositoryImpl) GetTrendingTags(ctx context.Context, since time.Time, limit int) ([]TrendingTag, error) {
+	var trending []TrendingTag
+
+	rawQuery := `
+		SELECT t.name AS name, COUNT(*) AS usage_count
+		FROM post_tags pt
+		INNER JOIN tags t ON t.id = pt.tag_id
+		INNER JOIN posts p ON p.id = pt.post_id
+		LEFT JOIN communities c ON c.id = p.community_id
+		WHERE p.published_at >= ? AND p.status = ? AND p.visibility = ? AND p.deleted_at IS NULL
+			AND (c.id IS NULL OR (c.visibility <> ? AND c.deleted_at IS NULL))
+		GROUP BY t.name
+		ORDER BY usage_count DESC, t.name ASC
+		LIMIT ?
+	`
+
+	if err := r.db.DB.WithContext(ctx).Raw(rawQuery, since, constant.PostStatusPublished, constant.PostVisibilityPublic, constant.CommunityVisibilityPrivate, limit).Scan(&trending).Error; err != nil {
+		return nil, fmt.Errorf("failed to get trending tags: %w", err)
+	}
+
+	return trending, nil
+}
@@ -15,6 +15,7 @@ type UniversityRepository interface {
 	DeleteUniversity(ctx context.Context, id int) error
 	GetUniversityByID(ctx context.Context, id int) (*model.University, error)
 	GetUniversityBySlug(ctx context.Context, slug string) (*model.University, error)
+	GetUniversitiesByIDs(ctx context.Context, ids []int) ([]model.University, error)
 }
 
 type UniversityRepositoryImpl struct {
@@ -27,8 +28,12 @@ func NewUniversityRepository(db database.PostgresWrapper) UniversityRepository {
 	}
 }
 
+// CreateUniversity stores a new university. ErrDuplicateRecord means its slug is taken.
 func (r *UniversityRepositoryImpl) CreateUniversity(ctx context.Context, university *model.University) error {
 	if err := r.db.Create(ctx, university); err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateRecord
+		}
 		return fmt.Errorf("failed to create university: %w", err)
 	}
 	return nil
@@ -81,3 +86,17 @@ func (r *UniversityRepositoryImpl) GetUniversityBySlug(ctx context.Context, slug
 
 	return &university, nil
 }
+
+func (r *UniversityRepositoryImpl) GetUniversitiesByIDs(ctx context.Context, ids []int) ([]model.University, error) {
+	var universities []model.University
+
+	if len(ids) == 0 {
+		return universities, nil
+	}
+
+	if err := r.db.Where(ctx, "id IN ?", ids).Find(&universities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get universities by ids: %w", err)
+	}
+
+	return universities, nil
+}
@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/temuka-api-service/internal/model"
 	database "github.com/temuka-api-service/util/database"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
@@ -13,10 +16,17 @@ type UserRepository interface {
 	GetUserByID(ctx context.Context, id int) (*model.User, error)
 	GetAllUsers(ctx context.Context) ([]model.User, error)
 	GetFollowers(ctx context.Context, userId int) ([]model.UserFollow, error)
+	GetFollowersByFollowingID(ctx context.Context, followingId int) ([]model.UserFollow, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	UpdateUser(ctx context.Context, userId int, user *model.User) error
 	DeleteUser(ctx context.Context, id int) error
 	CreateUserFollow(ctx context.Context, userFollow *model.UserFollow) error
+	GetUsersByUsernames(ctx context.Context, usernames []string) ([]model.User, error)
+	CreateUserBlock(ctx context.Context, userBlock *model.UserBlock) error
+	DeleteUserBlock(ctx context.Context, blockerId, blockedId int) error
+	IsBlocked(ctx context.Context, userId, otherUserId int) (bool, error)
+	IsFollowing(ctx context.Context, followerId, followingId int) (bool, error)
+	SetUniversityVerification(ctx context.Context, userId int, universityId *int, verifiedAt *time.Time) error
 }
 
 type UserRepositoryImpl struct {
@@ -99,3 +109,88 @@ func (r *UserRepositoryImpl) GetFollowers(ctx context.Context, userId int) ([]mo
 
 	return followers, nil
 }
+
+func (r *UserRepositoryImpl) GetFollowersByFollowingID(ctx context.Context, followingId int) ([]model.UserFollow, error) {
+	var followers []model.UserFollow
+
+	q := r.db.Where(ctx, "following_id = ?", followingId)
+	if err := q.Find(&followers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get followers by following id: %w", err)
+	}
+
+	return followers, nil
+}
+
+func (r *UserRepositoryImpl) GetUsersByUsernames(ctx context.Context, usernames []string) ([]model.User, error) {
+	var users []model.User
+
+	if len(usernames) == 0 {
+		return users, nil
+	}
+
+	lowered := make([]string, 0, len(usernames))
+	for _, u := range usernames {
+		lowered = append(lowered, strings.ToLower(u))
+	}
+
+	q := r.db.Where(ctx, "LOWER(username) IN ?", lowered)
+	if err := q.Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users by usernames: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepositoryImpl) CreateUserBlock(ctx context.Context, userBlock *model.UserBlock) error {
+	if err := r.db.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(userBlock).Error; err != nil {
+		return fmt.Errorf("failed to create user block: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) DeleteUserBlock(ctx context.Context, blockerId, blockedId int) error {
+	if err := r.db.Where(ctx, "blocker_id = ? AND blocked_id = ?", blockerId, blockedId).Delete(&model.UserBlock{}).Error; err != nil {
+		return fmt.Errorf("failed to delete user block: %w", err)
+	}
+	return nil
+}
+
+// IsBlocked reports whether either user has blocked the other.
+func (r *UserRepositoryImpl) IsBlocked(ctx context.Context, userId, otherUserId int) (bool, error) {
+	var count int64
+
+	err := r.db.Model(ctx, &model.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userId, otherUserId, otherUserId, userId).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check user block: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *UserRepositoryImpl) IsFollowing(ctx context.Context, followerId, followingId int) (bool, error) {
+	var count int64
+
+	err := r.db.Model(ctx, &model.UserFollow{}).
+		Where("follower_id = ? AND following_id = ?", followerId, followingId).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check user follow: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// SetUniversityVerification records the university a user has been verified at. Both
+// columns are written through a map so a nil university or time clears them.
+func (r *UserRepositoryImpl) SetUniversityVerification(ctx context.Context, userId int, universityId *int, verifiedAt *time.Time) error {
+	err := r.db.Model(ctx, &model.User{}).Where("id = ?", userId).Updates(map[string]interface{}{
+		"university_id":          universityId,
+		"university_verified_at": verifiedAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to set university verification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+)
+
+type BookmarkService interface {
+	CreateCollection(ctx context.Context, data dto.CreateBookmarkCollectionRequest) (*model.BookmarkCollection, error)
+	GetCollections(ctx context.Context, userID int) ([]model.BookmarkCollection, error)
+	DeleteCollection(ctx context.Context, collectionID int, data dto.DeleteBookmarkCollectionRequest) error
+	AddBookmark(ctx context.Context, collectionID int, data dto.BookmarkItemRequest) (*model.Bookmark, error)
+	RemoveBookmark(ctx context.Context, collectionID int, data dto.BookmarkItemRequest) error
+	GetCollectionBookmarks(ctx context.Context, collectionID, userID, page, limit int) ([]dto.BookmarkItemResponse, int64, error)
+}
+
+type BookmarkServiceImpl struct {
+	BookmarkRepository   repository.BookmarkRepository
+	PostRepository       repository.PostRepository
+	CommentRepository    repository.CommentRepository
+	UniversityRepository repository.UniversityRepository
+}
+
+func NewBookmarkService(
+	bookmarkRepo repository.BookmarkRepository,
+	postRepo repository.PostRepository,
+	commentRepo repository.CommentRepository,
+	universityRepo repository.UniversityRepository,
+) BookmarkService {
+	return &BookmarkServiceImpl{
+		BookmarkRepository:   bookmarkRepo,
+		PostRepository:       postRepo,
+		CommentRepository:    commentRepo,
+		UniversityRepository: universityRepo,
+	}
+}
+
+func (s *BookmarkServiceImpl) CreateCollection(ctx context.Context, data dto.CreateBookmarkCollectionRequest) (*model.BookmarkCollection, error) {
+	name := strings.TrimSpace(data.Name)
+	if name == "" {
+		return nil, errors.New("collection name is required")
+	}
+
+	collection := model.BookmarkCollection{
+		UserID: data.UserID,
+		Name:   name,
+	}
+
+	if err := s.BookmarkRepository.CreateCollection(ctx, &collection); err != nil {
+		return nil, errors.New("error creating bookmark collection")
+	}
+
+	return &collection, nil
+}
+
+func (s *BookmarkServiceImpl) GetCollections(ctx context.Context, userID int) ([]model.BookmarkCollection, error) {
+	collections, err := s.BookmarkRepository.GetCollectionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("error retrieving bookmark collections")
+	}
+	return collections, nil
+}
+
+func (s *BookmarkServiceImpl) DeleteCollection(ctx context.Context, collectionID int, data dto.DeleteBookmarkCollectionRequest) error {
+	if _, err := s.getOwnedCollection(ctx, collectionID, data.UserID); err != nil {
+		return err
+	}
+
+	if err := s.BookmarkRepository.DeleteCollection(ctx, collectionID); err != nil {
+		return errors.New("error deleting bookmark collection")
+	}
+	return nil
+}
+
+func (s *BookmarkServiceImpl) AddBookmark(ctx context.Context, collectionID int, data dto.BookmarkItemRequest) (*model.Bookmark, error) {
+	if _, err := s.getOwnedCollection(ctx, collectionID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTargetExists(ctx, data.TargetType, data.TargetID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	bookmark := model.Bookmark{
+		UserID:       data.UserID,
+		CollectionID: collectionID,
+		TargetType:   data.TargetType,
+		TargetID:     data.TargetID,
+	}
+
+	if err := s.BookmarkRepository.CreateBookmark(ctx, &bookmark); err != nil {
+		if errors.Is(err, repository.ErrDuplicateRecord) {
+			return nil, errors.New("item is already in this collection")
+		}
+		return nil, errors.New("error adding bookmark")
+	}
+
+	return &bookmark, nil
+}
+
+func (s *BookmarkServiceImpl) RemoveBookmark(ctx context.Context, collectionID int, data dto.BookmarkItemRequest) error {
+	if _, err := s.getOwnedCollection(ctx, collectionID, data.UserID); err != nil {
+		return err
+	}
+
+	removed, err := s.BookmarkRepository.DeleteBookmark(ctx, collectionID, data.TargetType, data.TargetID)
+	if err != nil {
+		return errors.New("error removing bookmark")
+	}
+	if !removed {
+		return errors.New("bookmark not found")
+	}
+	return nil
+}
+
+func (s *BookmarkServiceImpl) GetCollectionBookmarks(ctx context.Context, collectionID, userID, page, limit int) ([]dto.BookmarkItemResponse, int64, error) {
+	if _, err := s.getOwnedCollection(ctx, collectionID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	bookmarks, total, err := s.BookmarkRepository.GetBookmarksByCollectionID(ctx, collectionID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving bookmarks")
+	}
+
+	targets, err := s.loadTargets(ctx, bookmarks, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]dto.BookmarkItemResponse, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		item := dto.BookmarkItemResponse{
+			ID:         b.ID,
+			TargetType: b.TargetType,
+			TargetID:   b.TargetID,
+			Deleted:    b.TargetDeletedAt != nil,
+			CreatedAt:  b.CreatedAt,
+		}
+		if target, ok := targets[b.TargetType][b.TargetID]; ok && !item.Deleted {
+			item.Target = target
+		} else {
+			item.Deleted = true
+		}
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
+
+// getOwnedCollection loads a collection and makes sure it belongs to userID; collections are private.
+func (s *BookmarkServiceImpl) getOwnedCollection(ctx context.Context, collectionID, userID int) (*model.BookmarkCollection, error) {
+	collection, err := s.BookmarkRepository.GetCollectionByID(ctx, collectionID)
+	if err != nil {
+		return nil, errors.New("bookmark collection not found")
+	}
+	if collection.UserID != userID {
+		return nil, errors.New("bookmark collection not found")
+	}
+	return collection, nil
+}
+
+// checkTargetExists treats posts and comments the user may not see as missing, and so
+// are drafts and scheduled posts, even the user's own.
+func (s *BookmarkServiceImpl) checkTargetExists(ctx context.Context, targetType string, targetID, userID int) error {
+	var err error
+
+	switch targetType {
+	case constant.BookmarkTargetPost:
+		var visible bool
+		if visible, err = s.PostRepository.CanInteractWithPost(ctx, targetID, userID); err == nil && !visible {
+			err = errors.New("post not visible")
+		}
+	case constant.BookmarkTargetComment:
+		var comment *model.Comment
+		if comment, err = s.CommentRepository.GetCommentDetailByID(ctx, targetID); err == nil {
+			var visible bool
+			if visible, err = s.PostRepository.CanInteractWithPost(ctx, comment.PostID, userID); err == nil && !visible {
+				err = errors.New("post not visible")
+			}
+		}
+	case constant.BookmarkTargetUniversity:
+		_, err = s.UniversityRepository.GetUniversityByID(ctx, targetID)
+	default:
+		return errors.New("invalid bookmark target type")
+	}
+
+	if err != nil {
+		return errors.New("bookmark target not found")
+	}
+	return nil
+}
+
+// loadTargets fetches the bookmarked entities with one query per target type.
+// Posts and comments the user can no longer see are left out, like deleted ones.
+func (s *BookmarkServiceImpl) loadTargets(ctx context.Context, bookmarks []model.Bookmark, userID int) (map[string]map[int]interface{}, error) {
+	ids := make(map[string][]int)
+	for _, b := range bookmarks {
+		if b.TargetDeletedAt == nil {
+			ids[b.TargetType] = append(ids[b.TargetType], b.TargetID)
+		}
+	}
+
+	targets := map[string]map[int]interface{}{
+		constant.BookmarkTargetPost:       {},
+		constant.BookmarkTargetComment:    {},
+		constant.BookmarkTargetUniversity: {},
+	}
+
+	posts, err := s.PostRepository.GetPostsByIDs(ctx, ids[constant.BookmarkTargetPost], userID)
+	if err != nil {
+		return nil, errors.New("error retrieving bookmarked posts")
+	}
+	for i := range posts {
+		targets[constant.BookmarkTargetPost][posts[i].ID] = posts[i]
+	}
+
+	comments, err := s.CommentRepository.GetCommentsByIDs(ctx, ids[constant.BookmarkTargetComment], userID)
+	if err != nil {
+		return nil, errors.New("error retrieving bookmarked comments")
+	}
+	for i := range comments {
+		targets[constant.BookmarkTargetComment][comments[i].ID] = comments[i]
+	}
+
+	universities, err := s.UniversityRepository.GetUniversitiesByIDs(ctx, ids[constant.BookmarkTargetUniversity])
+	if err != nil {
+		return nil, errors.New("error retrieving bookmarked universities")
+	}
+	for i := range universities {
+		targets[constant.BookmarkTargetUniversity][universities[i].ID] = universities[i]
+	}
+
+	return targets, nil
+}
@@ -2,25 +2,46 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/dto"
 	"github.com/temuka-api-service/internal/model"
 	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/key_value_store"
+	"github.com/temuka-api-service/util/markdown"
+	"gorm.io/gorm"
 )
 
 type CommentService interface {
 	AddComment(ctx context.Context, data dto.AddCommentRequest) (*model.Comment, error)
-	ShowCommentsByPost(ctx context.Context, data dto.ShowCommentsRequest) ([]model.Comment, error)
-	DeleteComment(ctx context.Context, commentID int) error
-	ShowReplies(ctx context.Context, data dto.ShowRepliesRequest) ([]model.Comment, error)
+	ShowCommentsByPost(ctx context.Context, data dto.ShowCommentsRequest) ([]dto.CommentResponse, int64, error)
+	EditComment(ctx context.Context, commentID int, data dto.EditCommentRequest) (*model.Comment, error)
+	DeleteComment(ctx context.Context, commentID int, data dto.DeleteCommentRequest) error
+	GetCommentHistory(ctx context.Context, commentID, viewerID int) ([]model.CommentEdit, error)
+	ShowReplies(ctx context.Context, data dto.ShowRepliesRequest) (*dto.CommentRepliesResponse, error)
+	VoteComment(ctx context.Context, commentID int, data dto.VoteCommentRequest) (*dto.CommentVoteResponse, error)
 }
 
 type CommentServiceImpl struct {
-	CommentRepository      repository.CommentRepository
-	PostRepository         repository.PostRepository
-	NotificationRepository repository.NotificationRepository
-	ReportRepository       repository.ReportRepository
+	CommentRepository       repository.CommentRepository
+	PostRepository          repository.PostRepository
+	NotificationRepository  repository.NotificationRepository
+	ReportRepository        repository.ReportRepository
+	BookmarkRepository      repository.BookmarkRepository
+	ModeratorRepository     repository.ModeratorRepository
+	UserRepository          repository.UserRepository
+	CommunityRepository     repository.CommunityRepository
+	CommunityRuleRepository repository.CommunityRuleRepository
+	MentionService          MentionService
+	Redis                   key_value_store.RedisWrapper
 }
 
 func NewCommentService(
@@ -28,29 +49,72 @@ func NewCommentService(
 	postRepo repository.PostRepository,
 	notificationRepo repository.NotificationRepository,
 	reportRepo repository.ReportRepository,
+	bookmarkRepo repository.BookmarkRepository,
+	moderatorRepo repository.ModeratorRepository,
+	userRepo repository.UserRepository,
+	communityRepo repository.CommunityRepository,
+	ruleRepo repository.CommunityRuleRepository,
+	mentionService MentionService,
+	redis key_value_store.RedisWrapper,
 ) CommentService {
 	return &CommentServiceImpl{
-		CommentRepository:      commentRepo,
-		PostRepository:         postRepo,
-		NotificationRepository: notificationRepo,
-		ReportRepository:       reportRepo,
+		CommentRepository:       commentRepo,
+		PostRepository:          postRepo,
+		NotificationRepository:  notificationRepo,
+		ReportRepository:        reportRepo,
+		BookmarkRepository:      bookmarkRepo,
+		ModeratorRepository:     moderatorRepo,
+		UserRepository:          userRepo,
+		CommunityRepository:     communityRepo,
+		CommunityRuleRepository: ruleRepo,
+		MentionService:          mentionService,
+		Redis:                   redis,
 	}
 }
 
 func (s *CommentServiceImpl) AddComment(ctx context.Context, data dto.AddCommentRequest) (*model.Comment, error) {
+	if err := s.checkPostOpen(ctx, data.PostID, data.UserID); err != nil {
+		return nil, err
+	}
+
 	post, err := s.PostRepository.GetPostDetailByID(ctx, data.PostID)
 	if err != nil {
 		return nil, errors.New("post not found")
 	}
 
+	if data.ParentID != nil {
+		parent, err := s.CommentRepository.GetCommentDetailByID(ctx, *data.ParentID)
+		if err != nil || parent.PostID != data.PostID {
+			return nil, errors.New("parent comment not found")
+		}
+		if parent.Status != constant.CommentStatusActive {
+			return nil, errors.New("cannot reply to a deleted comment")
+		}
+	}
+
+	if post.CommunityID != 0 {
+		if err := checkCommunityParticipation(ctx, s.CommunityRepository, post.CommunityID, data.UserID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	slowModeKey, slowModeValue, err := s.checkCanComment(ctx, post, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
 	newComment := model.Comment{
 		UserID:   data.UserID,
 		PostID:   data.PostID,
 		ParentID: data.ParentID,
 		Content:  data.Content,
 	}
+	newComment.ContentHTML = markdown.Render(data.Content)
 
 	if err := s.CommentRepository.CreateComment(ctx, &newComment); err != nil {
+		if slowModeKey != "" {
+			_ = s.Redis.ReleaseLock(slowModeKey, slowModeValue)
+		}
 		return nil, errors.New("error creating comment")
 	}
 
@@ -62,7 +126,7 @@ func (s *CommentServiceImpl) AddComment(ctx context.Context, data dto.AddComment
 			PostID:    data.PostID,
 			CommentID: newComment.ID,
 			Type:      "comment",
-			Message:   "New comment on your post",
+			Message:   "New comment on your post: " + markdown.Excerpt(data.Content, constant.NotificationExcerptLength),
 			Read:      false,
 		}
 
@@ -71,41 +135,518 @@ func (s *CommentServiceImpl) AddComment(ctx context.Context, data dto.AddComment
 		}
 	}
 
+	s.processCommentMentions(ctx, &newComment, post)
+
 	return &newComment, nil
 }
 
-func (s *CommentServiceImpl) ShowCommentsByPost(ctx context.Context, data dto.ShowCommentsRequest) ([]model.Comment, error) {
-	comments, err := s.CommentRepository.GetCommentsByPostID(ctx, data.PostID)
+// checkCanComment enforces the lock, the comment restriction and the slow mode of a post.
+// Moderators of the post's community and admins are exempt from all of them, the author
+// from everything but the lock. When slow mode applies, the key and value holding the
+// user's interval are returned so they can be released if the comment is not created.
+func (s *CommentServiceImpl) checkCanComment(ctx context.Context, post *model.Post, userID int) (string, string, error) {
+	restricted := post.CommentRestriction != "" && post.CommentRestriction != constant.PostCommentRestrictionEveryone
+	if !post.Locked && post.UserID == userID {
+		return "", "", nil
+	}
+	if !post.Locked && !restricted && post.SlowModeSeconds <= 0 {
+		return "", "", nil
+	}
+
+	isModerator, err := s.canModeratePost(ctx, post, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if isModerator {
+		return "", "", nil
+	}
+
+	if post.Locked {
+		return "", "", newError(constant.CommentErrorPostLocked, "post is locked")
+	}
+	if post.UserID == userID {
+		return "", "", nil
+	}
+
+	switch post.CommentRestriction {
+	case constant.PostCommentRestrictionMembers:
+		member, err := s.CommunityRepository.CheckMembership(ctx, post.CommunityID, userID)
+		if err != nil {
+			return "", "", errors.New("error checking membership")
+		}
+		if member == nil || member.Banned {
+			return "", "", newError(constant.CommentErrorMembersOnly, "only community members can comment on this post")
+		}
+	case constant.PostCommentRestrictionFollowers:
+		following, err := s.UserRepository.IsFollowing(ctx, userID, post.UserID)
+		if err != nil {
+			return "", "", errors.New("error checking follow")
+		}
+		if !following {
+			return "", "", newError(constant.CommentErrorFollowersOnly, "only followers of the author can comment on this post")
+		}
+	}
+
+	if post.SlowModeSeconds <= 0 {
+		return "", "", nil
+	}
+
+	// The key lives for the slow mode interval from the user's last comment on the post.
+	key := fmt.Sprintf("comment_slow_mode_%d_%d", post.ID, userID)
+	value := uuid.NewString()
+	acquired, err := s.Redis.AcquireLock(key, value, time.Duration(post.SlowModeSeconds)*time.Second)
 	if err != nil {
-		return nil, errors.New("error retrieving comments")
+		log.Printf("Failed to check slow mode for post %d: %v", post.ID, err)
+		return "", "", nil
 	}
-	return comments, nil
+	if !acquired {
+		return "", "", newError(constant.CommentErrorSlowMode,
+			fmt.Sprintf("slow mode is on, you can comment once every %d seconds", post.SlowModeSeconds))
+	}
+
+	return key, value, nil
 }
 
-func (s *CommentServiceImpl) DeleteComment(ctx context.Context, commentID int) error {
-	if err := s.CommentRepository.DeleteComment(ctx, commentID); err != nil {
-		return errors.New("error deleting comment")
+// processCommentMentions notifies the users mentioned in a comment who can read its post.
+// Mentions already notified for the comment are skipped, so an edit only notifies new ones.
+func (s *CommentServiceImpl) processCommentMentions(ctx context.Context, comment *model.Comment, post *model.Post) {
+	source := MentionSource{
+		Type:      constant.MentionSourceComment,
+		ID:        comment.ID,
+		ActorID:   comment.UserID,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+	}
+	if post.Visibility != constant.PostVisibilityPublic {
+		source.CanSee = func(ctx context.Context, userID int) (bool, error) {
+			return s.PostRepository.IsPostVisibleTo(ctx, post.ID, userID)
+		}
+	}
+	if err := s.MentionService.ProcessMentions(ctx, source, comment.Content); err != nil {
+		log.Printf("Failed to process mentions for comment %d: %v", comment.ID, err)
+	}
+}
+
+// checkPostVisible makes comments of a post exactly as readable as the post itself.
+func (s *CommentServiceImpl) checkPostVisible(ctx context.Context, postID, viewerID int) error {
+	visible, err := s.PostRepository.IsPostVisibleTo(ctx, postID, viewerID)
+	if err != nil {
+		return errors.New("error checking post visibility")
+	}
+	if !visible {
+		return errors.New("post not found")
 	}
 	return nil
 }
 
-func (s *CommentServiceImpl) ShowReplies(ctx context.Context, data dto.ShowRepliesRequest) ([]model.Comment, error) {
-	var fetchReplies func(parentID int) ([]model.Comment, error)
-	fetchReplies = func(parentID int) ([]model.Comment, error) {
-		comments, err := s.CommentRepository.GetRepliesByParentID(ctx, parentID)
-		if err != nil {
+// checkPostOpen additionally rejects drafts and scheduled posts, which nobody can comment
+// on or vote in until they are published.
+func (s *CommentServiceImpl) checkPostOpen(ctx context.Context, postID, userID int) error {
+	open, err := s.PostRepository.CanInteractWithPost(ctx, postID, userID)
+	if err != nil {
+		return errors.New("error checking post visibility")
+	}
+	if !open {
+		return errors.New("post not found")
+	}
+	return nil
+}
+
+func (s *CommentServiceImpl) ShowCommentsByPost(ctx context.Context, data dto.ShowCommentsRequest) ([]dto.CommentResponse, int64, error) {
+	if err := s.checkPostVisible(ctx, data.PostID, data.UserID); err != nil {
+		return nil, 0, err
+	}
+
+	sort, err := resolveCommentSort(data.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.CommentRepository.CountReplies(ctx, data.PostID, nil)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving comments")
+	}
+
+	rows, err := s.CommentRepository.GetCommentTree(ctx, data.PostID, nil, sort, (data.Page-1)*data.Limit, data.Limit,
+		resolveCommentDepth(data.Depth), constant.CommentTreeMaxNodes)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving comments")
+	}
+
+	comments, err := s.buildCommentTree(ctx, rows, sort, data.UserID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// ShowReplies loads a page of the replies of a comment with their own replies below them.
+// It serves both the first page and "load more replies" continuations.
+func (s *CommentServiceImpl) ShowReplies(ctx context.Context, data dto.ShowRepliesRequest) (*dto.CommentRepliesResponse, error) {
+	parentID, offset, sort := data.ParentID, 0, data.Sort
+	if data.Cursor != "" {
+		var err error
+		if parentID, offset, sort, err = decodeCommentCursor(data.Cursor); err != nil {
 			return nil, err
 		}
+	}
+
+	sort, err := resolveCommentSort(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := s.CommentRepository.GetCommentDetailByID(ctx, parentID)
+	if err != nil {
+		return nil, errors.New("comment not found")
+	}
+	if err := s.checkPostVisible(ctx, parent.PostID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	total, err := s.CommentRepository.CountReplies(ctx, parent.PostID, &parent.ID)
+	if err != nil {
+		return nil, errors.New("error retrieving replies")
+	}
+
+	rows, err := s.CommentRepository.GetCommentTree(ctx, parent.PostID, &parent.ID, sort, offset, data.Limit,
+		resolveCommentDepth(data.Depth), constant.CommentTreeMaxNodes)
+	if err != nil {
+		return nil, errors.New("error retrieving replies")
+	}
+
+	replies, err := s.buildCommentTree(ctx, rows, sort, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.CommentRepliesResponse{Replies: replies, Total: total}
+	if next := offset + len(replies); int64(next) < total {
+		resp.NextCursor = encodeCommentCursor(parent.ID, next, sort)
+	}
+	return resp, nil
+}
+
+// buildCommentTree nests the rows of GetCommentTree under their parents. Rows arrive in
+// depth order, so a parent is always seen before its replies.
+func (s *CommentServiceImpl) buildCommentTree(ctx context.Context, rows []repository.CommentTreeRow, sort string, viewerID int) ([]dto.CommentResponse, error) {
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	votes, err := s.CommentRepository.GetUserCommentVotes(ctx, viewerID, ids)
+	if err != nil {
+		return nil, errors.New("error retrieving comment votes")
+	}
+
+	loaded := make(map[int]bool, len(rows))
+	children := make(map[int][]int)
+	byID := make(map[int]*repository.CommentTreeRow, len(rows))
+	var roots []int
+
+	for i := range rows {
+		row := &rows[i]
+		byID[row.ID] = row
+		loaded[row.ID] = true
+		if row.Depth == 0 || row.ParentID == nil || !loaded[*row.ParentID] {
+			roots = append(roots, row.ID)
+			continue
+		}
+		children[*row.ParentID] = append(children[*row.ParentID], row.ID)
+	}
 
-		for i := range comments {
-			replies, err := fetchReplies(comments[i].ID)
+	var build func(id int) dto.CommentResponse
+	build = func(id int) dto.CommentResponse {
+		row := byID[id]
+		node := dto.CommentResponse{
+			ID:          row.ID,
+			PostID:      row.PostID,
+			UserID:      row.UserID,
+			ParentID:    row.ParentID,
+			Content:     row.Content,
+			ContentHTML: row.ContentHTML,
+			Status:      row.Status,
+			EditedAt:    row.EditedAt,
+			Upvotes:     row.Upvotes,
+			Downvotes:   row.Downvotes,
+			Score:       row.Score,
+			MyVote:      votes[row.ID],
+			ReplyCount:  row.ReplyCount,
+			Replies:     make([]dto.CommentResponse, 0, len(children[id])),
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		}
+		if row.Status != constant.CommentStatusActive {
+			node.UserID = 0
+		}
+		for _, childID := range children[id] {
+			node.Replies = append(node.Replies, build(childID))
+		}
+		if len(node.Replies) < row.ReplyCount {
+			node.MoreReplies = encodeCommentCursor(row.ID, len(node.Replies), sort)
+		}
+		return node
+	}
+
+	tree := make([]dto.CommentResponse, 0, len(roots))
+	for _, id := range roots {
+		tree = append(tree, build(id))
+	}
+	return tree, nil
+}
+
+func resolveCommentSort(sort string) (string, error) {
+	switch sort {
+	case "":
+		return constant.CommentSortBest, nil
+	case constant.CommentSortBest, constant.CommentSortTop, constant.CommentSortNew, constant.CommentSortControversial:
+		return sort, nil
+	}
+	return "", errors.New("invalid comment sort")
+}
+
+func resolveCommentDepth(depth int) int {
+	if depth <= 0 {
+		return constant.CommentTreeDefaultDepth
+	}
+	if depth > constant.CommentTreeMaxDepth {
+		return constant.CommentTreeMaxDepth
+	}
+	return depth
+}
+
+// A continuation cursor pins the parent, the number of replies already shown and the sort
+// order, so following it with a different sort cannot skip or repeat replies.
+func encodeCommentCursor(parentID, offset int, sort string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d:%s", parentID, offset, sort)))
+}
+
+func decodeCommentCursor(cursor string) (int, int, string, error) {
+	invalid := errors.New("invalid comment cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, "", invalid
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 {
+		return 0, 0, "", invalid
+	}
+	parentID, err := strconv.Atoi(parts[0])
+	if err != nil || parentID <= 0 {
+		return 0, 0, "", invalid
+	}
+	offset, err := strconv.Atoi(parts[1])
+	if err != nil || offset < 0 {
+		return 0, 0, "", invalid
+	}
+	return parentID, offset, parts[2], nil
+}
+
+// VoteComment casts, changes or retracts the vote of a user on a comment and returns the
+// comment with its updated score.
+func (s *CommentServiceImpl) VoteComment(ctx context.Context, commentID int, data dto.VoteCommentRequest) (*dto.CommentVoteResponse, error) {
+	if data.Value < constant.CommentVoteDown || data.Value > constant.CommentVoteUp {
+		return nil, errors.New("vote must be 1, -1 or 0")
+	}
+
+	comment, err := s.CommentRepository.GetCommentDetailByID(ctx, commentID)
+	if err != nil {
+		return nil, errors.New("comment not found")
+	}
+	if comment.Status != constant.CommentStatusActive {
+		return nil, errors.New("cannot vote on a deleted comment")
+	}
+	if err := s.checkPostOpen(ctx, comment.PostID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	comment, err = s.CommentRepository.SetCommentVote(ctx, commentID, data.UserID, data.Value)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("comment not found")
+		}
+		return nil, errors.New("error voting on comment")
+	}
+
+	return &dto.CommentVoteResponse{
+		CommentID: comment.ID,
+		Upvotes:   comment.Upvotes,
+		Downvotes: comment.Downvotes,
+		Score:     comment.Score,
+		MyVote:    data.Value,
+	}, nil
+}
+
+// EditComment replaces the content of a comment by its author. The previous content is kept
+// in the edit history and the comment is marked as edited.
+func (s *CommentServiceImpl) EditComment(ctx context.Context, commentID int, data dto.EditCommentRequest) (*model.Comment, error) {
+	if strings.TrimSpace(data.Content) == "" {
+		return nil, errors.New("comment content cannot be empty")
+	}
+
+	comment, err := s.CommentRepository.GetCommentDetailByID(ctx, commentID)
+	if err != nil {
+		return nil, errors.New("comment not found")
+	}
+	if comment.UserID != data.UserID {
+		return nil, errors.New("only the author can edit this comment")
+	}
+	if comment.Status != constant.CommentStatusActive {
+		return nil, errors.New("a deleted comment cannot be edited")
+	}
+	if comment.Content == data.Content {
+		return comment, nil
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"content":      data.Content,
+		"content_html": markdown.Render(data.Content),
+		"edited_at":    now,
+	}
+	previous := model.CommentEdit{
+		CommentID: comment.ID,
+		EditorID:  data.UserID,
+		Action:    constant.CommentEditActionEdit,
+		Content:   comment.Content,
+	}
+
+	if err := s.CommentRepository.ReviseComment(ctx, comment.ID, updates, &previous); err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return nil, errors.New("a deleted comment cannot be edited")
+		}
+		return nil, errors.New("error editing comment")
+	}
+
+	comment.Content = data.Content
+	comment.ContentHTML = updates["content_html"].(string)
+	comment.EditedAt = &now
+
+	if post, err := s.PostRepository.GetPostDetailByID(ctx, comment.PostID); err == nil {
+		s.processCommentMentions(ctx, comment, post)
+	}
+
+	return comment, nil
+}
+
+// DeleteComment tombstones a comment instead of deleting the row, so its replies keep
+// their place in the thread. The author deletes their own comment; a moderator of the
+// post's community or an admin removes someone else's, optionally giving a reason and
+// citing the community rule it broke.
+func (s *CommentServiceImpl) DeleteComment(ctx context.Context, commentID int, data dto.DeleteCommentRequest) error {
+	comment, err := s.CommentRepository.GetCommentDetailByID(ctx, commentID)
+	if err != nil {
+		return errors.New("comment not found")
+	}
+	if comment.Status != constant.CommentStatusActive {
+		return errors.New("comment has already been deleted")
+	}
+
+	status, content := constant.CommentStatusDeleted, constant.CommentDeletedContent
+	if comment.UserID != data.UserID {
+		allowed, err := s.canModerateComment(ctx, comment, data.UserID)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return errors.New("not allowed to delete this comment")
+		}
+		status, content = constant.CommentStatusRemoved, constant.CommentRemovedContent
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":       status,
+		"content":      content,
+		"content_html": markdown.Render(content),
+		"removed_by":   data.UserID,
+		"removed_at":   now,
+	}
+	if status == constant.CommentStatusRemoved {
+		updates["removal_reason"] = strings.TrimSpace(data.Reason)
+		if data.RuleID != nil {
+			post, err := s.PostRepository.GetPostDetailByID(ctx, comment.PostID)
 			if err != nil {
-				return nil, err
+				return errors.New("post not found")
+			}
+			if _, err := citedRule(ctx, s.CommunityRuleRepository, post.CommunityID, *data.RuleID, constant.CommunityRuleAppliesToComments); err != nil {
+				return err
 			}
-			comments[i].Replies = replies
+			updates["removal_rule_id"] = *data.RuleID
+		}
+	}
+	previous := model.CommentEdit{
+		CommentID: comment.ID,
+		EditorID:  data.UserID,
+		Action:    status,
+		Content:   comment.Content,
+	}
+
+	if err := s.CommentRepository.ReviseComment(ctx, comment.ID, updates, &previous); err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return errors.New("comment has already been deleted")
 		}
-		return comments, nil
+		return errors.New("error deleting comment")
+	}
+
+	if err := s.BookmarkRepository.TombstoneBookmarksByTarget(ctx, constant.BookmarkTargetComment, commentID); err != nil {
+		log.Printf("Failed to tombstone bookmarks of comment %d: %v", commentID, err)
 	}
 
-	return fetchReplies(data.ParentID)
+	return nil
+}
+
+// GetCommentHistory lists the previous versions of a comment, newest first. Only the author,
+// moderators of the post's community and admins may read it.
+func (s *CommentServiceImpl) GetCommentHistory(ctx context.Context, commentID, viewerID int) ([]model.CommentEdit, error) {
+	comment, err := s.CommentRepository.GetCommentDetailByID(ctx, commentID)
+	if err != nil {
+		return nil, errors.New("comment not found")
+	}
+
+	if comment.UserID != viewerID {
+		allowed, err := s.canModerateComment(ctx, comment, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("not allowed to view the history of this comment")
+		}
+	}
+
+	edits, err := s.CommentRepository.GetCommentEdits(ctx, commentID)
+	if err != nil {
+		return nil, errors.New("error retrieving comment history")
+	}
+	return edits, nil
+}
+
+// canModerateComment reports whether userID is an admin or a moderator of the community
+// the comment's post belongs to.
+func (s *CommentServiceImpl) canModerateComment(ctx context.Context, comment *model.Comment, userID int) (bool, error) {
+	post, err := s.PostRepository.GetPostDetailByID(ctx, comment.PostID)
+	if err != nil {
+		return false, errors.New("post not found")
+	}
+	return s.canModeratePost(ctx, post, userID)
+}
+
+func (s *CommentServiceImpl) canModeratePost(ctx context.Context, post *model.Post, userID int) (bool, error) {
+	user, err := s.UserRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, errors.New("user not found")
+	}
+	if user.Role == constant.UserRoleAdmin {
+		return true, nil
+	}
+	if post.CommunityID == 0 {
+		return false, nil
+	}
+
+	isModerator, err := s.ModeratorRepository.IsCommunityModerator(ctx, post.CommunityID, userID)
+	if err != nil {
+		return false, errors.New("error checking moderator")
+	}
+	return isModerator, nil
 }
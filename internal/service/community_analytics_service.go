@@ -0,0 +1,257 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/key_value_store"
+)
+
+const (
+	communityAnalyticsLockKey = "lock_community_analytics_rollup"
+	communityAnalyticsLockTTL = 30 * time.Minute
+	// communityAnalyticsRolledUpKey holds the last day the rollup computed. Losing it only
+	// makes the next run backfill again.
+	communityAnalyticsRolledUpKey = "community_analytics_rolled_up_until"
+	communityAnalyticsDaysPerRun  = 31
+)
+
+var communityAnalyticsCSVHeader = []string{"date", "new_members", "leaves", "posts", "comments", "likes", "active_users", "reports"}
+
+// CommunityAnalyticsDay is the activity of a community on one UTC day.
+type CommunityAnalyticsDay struct {
+	Date        string `json:"date"`
+	NewMembers  int64  `json:"new_members"`
+	Leaves      int64  `json:"leaves"`
+	Posts       int64  `json:"posts"`
+	Comments    int64  `json:"comments"`
+	Likes       int64  `json:"likes"`
+	ActiveUsers int64  `json:"active_users"`
+	Reports     int64  `json:"reports"`
+}
+
+// CommunityAnalyticsTotals sums a date range. Active users are distinct per day and cannot
+// be added up, so only the busiest day is reported.
+type CommunityAnalyticsTotals struct {
+	NewMembers      int64 `json:"new_members"`
+	Leaves          int64 `json:"leaves"`
+	NetMembers      int64 `json:"net_members"`
+	Posts           int64 `json:"posts"`
+	Comments        int64 `json:"comments"`
+	Likes           int64 `json:"likes"`
+	PeakActiveUsers int64 `json:"peak_active_users"`
+	Reports         int64 `json:"reports"`
+}
+
+type CommunityAnalytics struct {
+	CommunityID int                      `json:"community_id"`
+	From        string                   `json:"from"`
+	To          string                   `json:"to"`
+	Totals      CommunityAnalyticsTotals `json:"totals"`
+	Daily       []CommunityAnalyticsDay  `json:"daily"`
+}
+
+type CommunityAnalyticsService interface {
+	RollupStats(ctx context.Context) error
+	GetCommunityAnalytics(ctx context.Context, communityID, requesterID int, from, to string) (*CommunityAnalytics, error)
+	ExportCommunityAnalytics(ctx context.Context, communityID, requesterID int, from, to string) ([]byte, error)
+}
+
+type CommunityAnalyticsServiceImpl struct {
+	CommunityAnalyticsRepository repository.CommunityAnalyticsRepository
+	CommunityRepository          repository.CommunityRepository
+	ModeratorRepository          repository.ModeratorRepository
+	UserRepository               repository.UserRepository
+	Redis                        key_value_store.RedisWrapper
+}
+
+func NewCommunityAnalyticsService(
+	analyticsRepo repository.CommunityAnalyticsRepository,
+	communityRepo repository.CommunityRepository,
+	moderatorRepo repository.ModeratorRepository,
+	userRepo repository.UserRepository,
+	redis key_value_store.RedisWrapper,
+) CommunityAnalyticsService {
+	return &CommunityAnalyticsServiceImpl{
+		CommunityAnalyticsRepository: analyticsRepo,
+		CommunityRepository:          communityRepo,
+		ModeratorRepository:          moderatorRepo,
+		UserRepository:               userRepo,
+		Redis:                        redis,
+	}
+}
+
+// RollupStats computes the daily stats of every community into community_daily_stats. Each
+// run recomputes the current day and the last finished ones, and the first run backfills
+// CommunityAnalyticsBackfillDay days a month at a time. A Redis lock keeps instances from
+// rolling up concurrently.
+func (s *CommunityAnalyticsServiceImpl) RollupStats(ctx context.Context) error {
+	lockValue := uuid.NewString()
+	acquired, err := s.Redis.AcquireLock(communityAnalyticsLockKey, lockValue, communityAnalyticsLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire community analytics lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := s.Redis.ReleaseLock(communityAnalyticsLockKey, lockValue); err != nil {
+			log.Printf("Failed to release community analytics lock: %v", err)
+		}
+	}()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	earliest := today.AddDate(0, 0, -constant.CommunityAnalyticsBackfillDay)
+
+	day := earliest
+	var rolledUp string
+	if err := s.Redis.Get(communityAnalyticsRolledUpKey, &rolledUp); err == nil {
+		if last, err := time.Parse(constant.PostStatsDateLayout, rolledUp); err == nil {
+			if last.After(today) {
+				last = today
+			}
+			day = last.AddDate(0, 0, -constant.CommunityAnalyticsRecomputeDay)
+		}
+	}
+	if day.Before(earliest) {
+		day = earliest
+	}
+
+	for i := 0; i < communityAnalyticsDaysPerRun && !day.After(today); i++ {
+		if err := s.CommunityAnalyticsRepository.RollupDay(ctx, day); err != nil {
+			return err
+		}
+		if err := s.Redis.Set(communityAnalyticsRolledUpKey, day.Format(constant.PostStatsDateLayout), 0); err != nil {
+			log.Printf("Failed to save community analytics progress: %v", err)
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return nil
+}
+
+// GetCommunityAnalytics returns the daily activity of a community between two dates in
+// YYYY-MM-DD form, the last 30 days by default. Days without activity are filled with
+// zeroes. Only moderators of the community and admins may see it.
+func (s *CommunityAnalyticsServiceImpl) GetCommunityAnalytics(ctx context.Context, communityID, requesterID int, from, to string) (*CommunityAnalytics, error) {
+	if _, err := s.CommunityRepository.GetCommunityDetailByID(ctx, communityID); err != nil {
+		return nil, errors.New("community not found")
+	}
+
+	allowed, err := canModerateCommunity(ctx, s.UserRepository, s.ModeratorRepository, communityID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("not allowed to view community analytics")
+	}
+
+	toDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		if toDate, err = time.Parse(constant.PostStatsDateLayout, to); err != nil {
+			return nil, errors.New("invalid to date")
+		}
+	}
+	fromDate := toDate.AddDate(0, 0, -(constant.CommunityAnalyticsDefaultRangeDay - 1))
+	if from != "" {
+		if fromDate, err = time.Parse(constant.PostStatsDateLayout, from); err != nil {
+			return nil, errors.New("invalid from date")
+		}
+	}
+	if fromDate.After(toDate) {
+		return nil, errors.New("from date must not be after to date")
+	}
+	if toDate.Sub(fromDate) >= constant.CommunityAnalyticsMaxRangeDay*24*time.Hour {
+		return nil, errors.New("date range is too large")
+	}
+
+	stats, err := s.CommunityAnalyticsRepository.GetDailyStats(ctx, communityID, fromDate, toDate)
+	if err != nil {
+		return nil, errors.New("error retrieving community analytics")
+	}
+
+	byDate := make(map[string]model.CommunityDailyStat, len(stats))
+	for _, stat := range stats {
+		byDate[stat.Date.UTC().Format(constant.PostStatsDateLayout)] = stat
+	}
+
+	analytics := &CommunityAnalytics{
+		CommunityID: communityID,
+		From:        fromDate.Format(constant.PostStatsDateLayout),
+		To:          toDate.Format(constant.PostStatsDateLayout),
+	}
+	for day := fromDate; !day.After(toDate); day = day.AddDate(0, 0, 1) {
+		date := day.Format(constant.PostStatsDateLayout)
+		stat := byDate[date]
+		analytics.Daily = append(analytics.Daily, CommunityAnalyticsDay{
+			Date:        date,
+			NewMembers:  stat.NewMembers,
+			Leaves:      stat.Leaves,
+			Posts:       stat.Posts,
+			Comments:    stat.Comments,
+			Likes:       stat.Likes,
+			ActiveUsers: stat.ActiveUsers,
+			Reports:     stat.Reports,
+		})
+
+		totals := &analytics.Totals
+		totals.NewMembers += stat.NewMembers
+		totals.Leaves += stat.Leaves
+		totals.Posts += stat.Posts
+		totals.Comments += stat.Comments
+		totals.Likes += stat.Likes
+		totals.Reports += stat.Reports
+		if stat.ActiveUsers > totals.PeakActiveUsers {
+			totals.PeakActiveUsers = stat.ActiveUsers
+		}
+	}
+	analytics.Totals.NetMembers = analytics.Totals.NewMembers - analytics.Totals.Leaves
+
+	return analytics, nil
+}
+
+// ExportCommunityAnalytics returns the same daily series as GetCommunityAnalytics as CSV,
+// one row per day.
+func (s *CommunityAnalyticsServiceImpl) ExportCommunityAnalytics(ctx context.Context, communityID, requesterID int, from, to string) ([]byte, error) {
+	analytics, err := s.GetCommunityAnalytics(ctx, communityID, requesterID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(communityAnalyticsCSVHeader); err != nil {
+		return nil, errors.New("error exporting community analytics")
+	}
+	for _, day := range analytics.Daily {
+		record := []string{
+			day.Date,
+			strconv.FormatInt(day.NewMembers, 10),
+			strconv.FormatInt(day.Leaves, 10),
+			strconv.FormatInt(day.Posts, 10),
+			strconv.FormatInt(day.Comments, 10),
+			strconv.FormatInt(day.Likes, 10),
+			strconv.FormatInt(day.ActiveUsers, 10),
+			strconv.FormatInt(day.Reports, 10),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, errors.New("error exporting community analytics")
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, errors.New("error exporting community analytics")
+	}
+
+	return buf.Bytes(), nil
+}
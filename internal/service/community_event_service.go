@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/ical"
+	"github.com/temuka-api-service/util/key_value_store"
+)
+
+type CommunityEventService interface {
+	CreateEvent(ctx context.Context, communityID int, data dto.CommunityEventRequest) (*model.CommunityEvent, error)
+	GetEvent(ctx context.Context, id, viewerID int) (*model.CommunityEvent, error)
+	GetCommunityEvents(ctx context.Context, communityID, viewerID int, from, to time.Time, page, limit int) ([]model.CommunityEvent, int64, error)
+	UpdateEvent(ctx context.Context, id int, data dto.CommunityEventRequest) (*model.CommunityEvent, error)
+	CancelEvent(ctx context.Context, id, userID int) error
+	RespondEvent(ctx context.Context, id int, data dto.CommunityEventRSVPRequest) (*model.CommunityEventRSVP, error)
+	GetEventAttendees(ctx context.Context, id, viewerID int, status string, page, limit int) ([]dto.CommunityEventAttendeeResponse, int64, error)
+	SendEventReminders(ctx context.Context) error
+	CreateCalendarToken(ctx context.Context, userID int) (*dto.CalendarTokenResponse, error)
+	GetCommunityCalendar(ctx context.Context, communityID int, token string) ([]byte, error)
+	GetUserCalendar(ctx context.Context, token string) ([]byte, error)
+}
+
+const (
+	communityEventRemindersLockKey = "lock_community_event_reminders"
+	communityEventRemindersLockTTL = 50 * time.Second
+	communityEventRemindersPerRun  = 100
+
+	// Calendar feeds include events that ended up to a month ago, so clients keep recent
+	// history, and at most this many events.
+	calendarFeedHistory   = 30 * 24 * time.Hour
+	calendarFeedMaxEvents = 500
+)
+
+type CommunityEventServiceImpl struct {
+	CommunityEventRepository repository.CommunityEventRepository
+	CommunityRepository      repository.CommunityRepository
+	ModeratorRepository      repository.ModeratorRepository
+	UserRepository           repository.UserRepository
+	NotificationRepository   repository.NotificationRepository
+	Redis                    key_value_store.RedisWrapper
+}
+
+func NewCommunityEventService(
+	eventRepo repository.CommunityEventRepository,
+	communityRepo repository.CommunityRepository,
+	moderatorRepo repository.ModeratorRepository,
+	userRepo repository.UserRepository,
+	notificationRepo repository.NotificationRepository,
+	redis key_value_store.RedisWrapper,
+) CommunityEventService {
+	return &CommunityEventServiceImpl{
+		CommunityEventRepository: eventRepo,
+		CommunityRepository:      communityRepo,
+		ModeratorRepository:      moderatorRepo,
+		UserRepository:           userRepo,
+		NotificationRepository:   notificationRepo,
+		Redis:                    redis,
+	}
+}
+
+// CreateEvent schedules an event in a community. Any member who may post there can
+// organise one.
+func (s *CommunityEventServiceImpl) CreateEvent(ctx context.Context, communityID int, data dto.CommunityEventRequest) (*model.CommunityEvent, error) {
+	if err := checkCommunityParticipation(ctx, s.CommunityRepository, communityID, data.UserID, true); err != nil {
+		return nil, err
+	}
+	member, err := s.CommunityRepository.CheckMembership(ctx, communityID, data.UserID)
+	if err != nil {
+		return nil, errors.New("error checking membership")
+	}
+	if member == nil || member.BanOnly {
+		return nil, newError(constant.CommunityErrorMembersOnly, "only members can organise events in this community")
+	}
+
+	event := model.CommunityEvent{
+		CommunityID: communityID,
+		OrganizerID: data.UserID,
+		Status:      constant.CommunityEventStatusScheduled,
+	}
+	if err := applyCommunityEventRequest(&event, data); err != nil {
+		return nil, err
+	}
+
+	if err := s.CommunityEventRepository.CreateEvent(ctx, &event); err != nil {
+		return nil, errors.New("error creating event")
+	}
+	return &event, nil
+}
+
+func (s *CommunityEventServiceImpl) GetEvent(ctx context.Context, id, viewerID int) (*model.CommunityEvent, error) {
+	event, err := s.CommunityEventRepository.GetEventByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("event not found")
+	}
+	if _, err := s.checkEventAccess(ctx, event.CommunityID, viewerID); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// GetCommunityEvents lists the events of a community that have not ended by from and start
+// before to, soonest first. A zero from means now and a zero to means no upper bound.
+func (s *CommunityEventServiceImpl) GetCommunityEvents(ctx context.Context, communityID, viewerID int, from, to time.Time, page, limit int) ([]model.CommunityEvent, int64, error) {
+	if _, err := s.checkEventAccess(ctx, communityID, viewerID); err != nil {
+		return nil, 0, err
+	}
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if !to.IsZero() && !to.After(from) {
+		return nil, 0, errors.New("to must be after from")
+	}
+
+	events, total, err := s.CommunityEventRepository.GetCommunityEvents(ctx, communityID, from, to, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving events")
+	}
+	return events, total, nil
+}
+
+// UpdateEvent changes the details of a scheduled event on behalf of its organiser or a
+// moderator. Attendees moved up from the waitlist by a larger capacity are notified.
+func (s *CommunityEventServiceImpl) UpdateEvent(ctx context.Context, id int, data dto.CommunityEventRequest) (*model.CommunityEvent, error) {
+	event, err := s.getManagedEvent(ctx, id, data.UserID, "only the organiser or a moderator can change this event")
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCommunityEventRequest(event, data); err != nil {
+		return nil, err
+	}
+
+	promoted, err := s.CommunityEventRepository.UpdateEvent(ctx, event)
+	if err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return nil, errors.New("this event has been cancelled")
+		}
+		return nil, errors.New("error updating event")
+	}
+	s.notifyPromoted(ctx, event, promoted)
+
+	return s.CommunityEventRepository.GetEventByID(ctx, id)
+}
+
+// CancelEvent cancels a scheduled event and tells everyone who planned to attend.
+func (s *CommunityEventServiceImpl) CancelEvent(ctx context.Context, id, userID int) error {
+	event, err := s.getManagedEvent(ctx, id, userID, "only the organiser or a moderator can cancel this event")
+	if err != nil {
+		return err
+	}
+
+	cancelled, err := s.CommunityEventRepository.CancelEvent(ctx, id)
+	if err != nil {
+		return errors.New("error cancelling event")
+	}
+	if !cancelled {
+		return errors.New("this event has already been cancelled")
+	}
+
+	template := model.Notification{
+		ActorID: userID,
+		Type:    constant.NotificationTypeCommunityEventCancelled,
+		Message: event.Title + " has been cancelled",
+		Read:    false,
+	}
+	statuses := []string{constant.CommunityEventRSVPGoing, constant.CommunityEventRSVPMaybe, constant.CommunityEventRSVPWaitlisted}
+	if err := s.CommunityEventRepository.NotifyAttendees(ctx, id, statuses, template); err != nil {
+		log.Printf("Failed to notify attendees of cancelled event %d: %v", id, err)
+	}
+	return nil
+}
+
+// RespondEvent records whether a user is going to an event. Going to a full event puts them
+// on the waitlist, which the returned RSVP shows.
+func (s *CommunityEventServiceImpl) RespondEvent(ctx context.Context, id int, data dto.CommunityEventRSVPRequest) (*model.CommunityEventRSVP, error) {
+	switch data.Status {
+	case constant.CommunityEventRSVPGoing, constant.CommunityEventRSVPMaybe, constant.CommunityEventRSVPNotGoing:
+	default:
+		return nil, errors.New("status must be going, maybe or not_going")
+	}
+
+	event, err := s.CommunityEventRepository.GetEventByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("event not found")
+	}
+	if _, err := s.checkEventAccess(ctx, event.CommunityID, data.UserID); err != nil {
+		return nil, err
+	}
+	if err := checkCommunityParticipation(ctx, s.CommunityRepository, event.CommunityID, data.UserID, false); err != nil {
+		return nil, err
+	}
+
+	rsvp, promoted, err := s.CommunityEventRepository.SetRSVP(ctx, id, data.UserID, data.Status)
+	if err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return nil, errors.New("this event has been cancelled or is over")
+		}
+		return nil, errors.New("error saving rsvp")
+	}
+	s.notifyPromoted(ctx, event, promoted)
+
+	return rsvp, nil
+}
+
+// GetEventAttendees lists the users who gave an event the answer status; the waitlist is
+// listed in the order places are handed out.
+func (s *CommunityEventServiceImpl) GetEventAttendees(ctx context.Context, id, viewerID int, status string, page, limit int) ([]dto.CommunityEventAttendeeResponse, int64, error) {
+	switch status {
+	case "":
+		status = constant.CommunityEventRSVPGoing
+	case constant.CommunityEventRSVPGoing, constant.CommunityEventRSVPMaybe, constant.CommunityEventRSVPNotGoing, constant.CommunityEventRSVPWaitlisted:
+	default:
+		return nil, 0, errors.New("invalid rsvp status")
+	}
+
+	event, err := s.CommunityEventRepository.GetEventByID(ctx, id)
+	if err != nil {
+		return nil, 0, errors.New("event not found")
+	}
+	if _, err := s.checkEventAccess(ctx, event.CommunityID, viewerID); err != nil {
+		return nil, 0, err
+	}
+
+	rows, total, err := s.CommunityEventRepository.GetAttendees(ctx, id, status, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving attendees")
+	}
+
+	attendees := make([]dto.CommunityEventAttendeeResponse, 0, len(rows))
+	for _, row := range rows {
+		attendees = append(attendees, dto.CommunityEventAttendeeResponse{
+			UserID:         row.UserID,
+			Username:       row.Username,
+			Displayname:    row.Displayname,
+			ProfilePicture: row.ProfilePicture,
+			Status:         row.Status,
+			RespondedAt:    row.UpdatedAt,
+		})
+	}
+
+	return attendees, total, nil
+}
+
+// SendEventReminders notifies the going and maybe attendees of events starting within the
+// reminder lead. It runs periodically; a Redis lock keeps instances from sending the same
+// reminders concurrently, and each event is marked as reminded as its notifications go out.
+func (s *CommunityEventServiceImpl) SendEventReminders(ctx context.Context) error {
+	lockValue := uuid.NewString()
+	acquired, err := s.Redis.AcquireLock(communityEventRemindersLockKey, lockValue, communityEventRemindersLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire event reminders lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := s.Redis.ReleaseLock(communityEventRemindersLockKey, lockValue); err != nil {
+			log.Printf("Failed to release event reminders lock: %v", err)
+		}
+	}()
+
+	events, err := s.CommunityEventRepository.GetEventsDueForReminder(ctx, time.Now().Add(constant.CommunityEventReminderLead), communityEventRemindersPerRun)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		template := model.Notification{
+			ActorID: event.OrganizerID,
+			Type:    constant.NotificationTypeCommunityEventReminder,
+			Message: event.Title + " starts at " + formatEventStart(&event),
+			Read:    false,
+		}
+		if _, err := s.CommunityEventRepository.SendEventReminder(ctx, event.ID, template); err != nil {
+			log.Printf("Failed to send reminders for event %d: %v", event.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// CreateCalendarToken issues a new secret for the personal calendar feed of a user,
+// replacing the previous one.
+func (s *CommunityEventServiceImpl) CreateCalendarToken(ctx context.Context, userID int) (*dto.CalendarTokenResponse, error) {
+	if _, err := s.UserRepository.GetUserByID(ctx, userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	secret := make([]byte, constant.CommunityCalendarTokenLength/2)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.New("error creating calendar token")
+	}
+	token := hex.EncodeToString(secret)
+
+	if err := s.CommunityEventRepository.SaveCalendarToken(ctx, userID, token); err != nil {
+		return nil, errors.New("error creating calendar token")
+	}
+	return &dto.CalendarTokenResponse{Token: token}, nil
+}
+
+// GetCommunityCalendar returns the iCalendar feed of a community. Feeds of private
+// communities need the calendar token of a member, since calendar apps cannot log in.
+func (s *CommunityEventServiceImpl) GetCommunityCalendar(ctx context.Context, communityID int, token string) ([]byte, error) {
+	viewerID := 0
+	if token != "" {
+		userID, err := s.CommunityEventRepository.GetUserIDByCalendarToken(ctx, token)
+		if err != nil {
+			return nil, errors.New("invalid calendar token")
+		}
+		viewerID = userID
+	}
+	community, err := s.checkEventAccess(ctx, communityID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, _, err := s.CommunityEventRepository.GetCommunityEvents(ctx, communityID, time.Now().Add(-calendarFeedHistory), time.Time{}, 0, calendarFeedMaxEvents)
+	if err != nil {
+		return nil, errors.New("error retrieving events")
+	}
+
+	return buildCalendar(community.Name+" events", events).Encode(), nil
+}
+
+// GetUserCalendar returns the iCalendar feed of the events the owner of token is going to,
+// might go to or is waitlisted for.
+func (s *CommunityEventServiceImpl) GetUserCalendar(ctx context.Context, token string) ([]byte, error) {
+	userID, err := s.CommunityEventRepository.GetUserIDByCalendarToken(ctx, token)
+	if err != nil {
+		return nil, errors.New("invalid calendar token")
+	}
+
+	events, err := s.CommunityEventRepository.GetUserCalendarEvents(ctx, userID, time.Now().Add(-calendarFeedHistory), calendarFeedMaxEvents)
+	if err != nil {
+		return nil, errors.New("error retrieving events")
+	}
+
+	return buildCalendar("My events", events).Encode(), nil
+}
+
+// checkEventAccess returns the community of an event once viewerID may see its events.
+func (s *CommunityEventServiceImpl) checkEventAccess(ctx context.Context, communityID, viewerID int) (*model.Community, error) {
+	return checkCommunityReadAccess(ctx, s.CommunityRepository, s.UserRepository, s.ModeratorRepository, communityID, viewerID)
+}
+
+func (s *CommunityEventServiceImpl) getManagedEvent(ctx context.Context, id, userID int, deniedMessage string) (*model.CommunityEvent, error) {
+	event, err := s.CommunityEventRepository.GetEventByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("event not found")
+	}
+	if event.OrganizerID == userID {
+		return event, nil
+	}
+
+	allowed, err := canModerateCommunity(ctx, s.UserRepository, s.ModeratorRepository, event.CommunityID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New(deniedMessage)
+	}
+	return event, nil
+}
+
+// notifyPromoted tells users moved up from the waitlist that they have a place. A failed
+// notification does not undo the promotion.
+func (s *CommunityEventServiceImpl) notifyPromoted(ctx context.Context, event *model.CommunityEvent, userIDs []int) {
+	for _, userID := range userIDs {
+		notification := model.Notification{
+			UserID:  userID,
+			ActorID: event.OrganizerID,
+			Type:    constant.NotificationTypeCommunityEventPromoted,
+			Message: "A place opened up for you at " + event.Title,
+			Read:    false,
+		}
+		if err := s.NotificationRepository.CreateNotification(ctx, &notification); err != nil {
+			log.Printf("Failed to notify user %d of waitlist promotion for event %d: %v", userID, event.ID, err)
+		}
+	}
+}
+
+// applyCommunityEventRequest validates the details in data and copies them onto event.
+func applyCommunityEventRequest(event *model.CommunityEvent, data dto.CommunityEventRequest) error {
+	title := strings.TrimSpace(data.Title)
+	description := strings.TrimSpace(data.Description)
+	location := strings.TrimSpace(data.Location)
+	onlineURL := strings.TrimSpace(data.OnlineURL)
+
+	if title == "" {
+		return errors.New("event title is required")
+	}
+	if utf8.RuneCountInString(title) > constant.CommunityEventTitleMaxLength {
+		return fmt.Errorf("event title can be at most %d characters", constant.CommunityEventTitleMaxLength)
+	}
+	if utf8.RuneCountInString(description) > constant.CommunityEventDescriptionMaxLength {
+		return fmt.Errorf("event description can be at most %d characters", constant.CommunityEventDescriptionMaxLength)
+	}
+	if utf8.RuneCountInString(location) > constant.CommunityEventLocationMaxLength {
+		return fmt.Errorf("event location can be at most %d characters", constant.CommunityEventLocationMaxLength)
+	}
+	if onlineURL == "" && location == "" {
+		return errors.New("an online link or a location is required")
+	}
+	if onlineURL != "" {
+		u, err := url.Parse(onlineURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("online link must be an http or https URL")
+		}
+	}
+
+	if data.StartsAt.IsZero() || data.EndsAt.IsZero() {
+		return errors.New("event start and end are required")
+	}
+	if !data.StartsAt.After(time.Now()) {
+		return errors.New("event must start in the future")
+	}
+	if !data.EndsAt.After(data.StartsAt) {
+		return errors.New("event must end after it starts")
+	}
+	if data.EndsAt.Sub(data.StartsAt) > constant.CommunityEventMaxDuration {
+		return fmt.Errorf("event can last at most %d days", constant.CommunityEventMaxDuration/(24*time.Hour))
+	}
+
+	timeZone := strings.TrimSpace(data.TimeZone)
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return errors.New("unknown time zone")
+	}
+
+	if data.Capacity < 0 {
+		return errors.New("capacity cannot be negative")
+	}
+
+	event.Title = title
+	event.Description = description
+	event.StartsAt = data.StartsAt.UTC()
+	event.EndsAt = data.EndsAt.UTC()
+	event.TimeZone = timeZone
+	event.OnlineURL = onlineURL
+	event.Location = location
+	event.Capacity = data.Capacity
+	return nil
+}
+
+// formatEventStart renders the start of an event in the zone it was planned in.
+func formatEventStart(event *model.CommunityEvent) string {
+	loc, err := time.LoadLocation(event.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return event.StartsAt.In(loc).Format("Mon 2 Jan 15:04 MST")
+}
+
+func buildCalendar(name string, events []model.CommunityEvent) ical.Calendar {
+	calendar := ical.Calendar{Name: name, Events: make([]ical.Event, 0, len(events))}
+	for _, event := range events {
+		location := event.Location
+		if location == "" {
+			location = event.OnlineURL
+		}
+		calendar.Events = append(calendar.Events, ical.Event{
+			UID:          fmt.Sprintf("community-event-%d@temuka", event.ID),
+			Sequence:     event.Sequence,
+			Summary:      event.Title,
+			Description:  event.Description,
+			Location:     location,
+			URL:          event.OnlineURL,
+			Start:        event.StartsAt,
+			End:          event.EndsAt,
+			TimeZone:     event.TimeZone,
+			Cancelled:    event.Status == constant.CommunityEventStatusCancelled,
+			Created:      event.CreatedAt,
+			LastModified: event.UpdatedAt,
+		})
+	}
+	return calendar
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+)
+
+type CommunityFlairService interface {
+	GetFlairs(ctx context.Context, communityID int) ([]model.CommunityFlair, error)
+	CreateFlair(ctx context.Context, communityID int, data dto.CommunityFlairRequest) (*model.CommunityFlair, error)
+	UpdateFlair(ctx context.Context, communityID, flairID int, data dto.CommunityFlairRequest) (*model.CommunityFlair, error)
+	DeleteFlair(ctx context.Context, communityID, flairID, moderatorID int) error
+}
+
+var flairColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type CommunityFlairServiceImpl struct {
+	CommunityFlairRepository repository.CommunityFlairRepository
+	ModeratorRepository      repository.ModeratorRepository
+	UserRepository           repository.UserRepository
+}
+
+func NewCommunityFlairService(
+	flairRepo repository.CommunityFlairRepository,
+	moderatorRepo repository.ModeratorRepository,
+	userRepo repository.UserRepository,
+) CommunityFlairService {
+	return &CommunityFlairServiceImpl{
+		CommunityFlairRepository: flairRepo,
+		ModeratorRepository:      moderatorRepo,
+		UserRepository:           userRepo,
+	}
+}
+
+func (s *CommunityFlairServiceImpl) GetFlairs(ctx context.Context, communityID int) ([]model.CommunityFlair, error) {
+	flairs, err := s.CommunityFlairRepository.GetFlairs(ctx, communityID)
+	if err != nil {
+		return nil, errors.New("error retrieving community flairs")
+	}
+	return flairs, nil
+}
+
+func (s *CommunityFlairServiceImpl) CreateFlair(ctx context.Context, communityID int, data dto.CommunityFlairRequest) (*model.CommunityFlair, error) {
+	if err := s.checkModerator(ctx, communityID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	flair := model.CommunityFlair{
+		CommunityID:     communityID,
+		Name:            strings.TrimSpace(data.Name),
+		BackgroundColor: constant.CommunityFlairDefaultBackground,
+		TextColor:       constant.CommunityFlairDefaultTextColor,
+	}
+	if data.BackgroundColor != "" {
+		flair.BackgroundColor = data.BackgroundColor
+	}
+	if data.TextColor != "" {
+		flair.TextColor = data.TextColor
+	}
+	if flair.Name == "" {
+		return nil, errors.New("flair name is required")
+	}
+	if err := validateFlair(&flair); err != nil {
+		return nil, err
+	}
+
+	if err := s.CommunityFlairRepository.CreateFlair(ctx, &flair, constant.CommunityMaxFlairs); err != nil {
+		if errors.Is(err, repository.ErrDuplicateRecord) {
+			return nil, errors.New("a flair with the same name already exists")
+		}
+		if errors.Is(err, repository.ErrLimitReached) {
+			return nil, fmt.Errorf("a community can have at most %d flairs", constant.CommunityMaxFlairs)
+		}
+		return nil, errors.New("error creating community flair")
+	}
+	return &flair, nil
+}
+
+func (s *CommunityFlairServiceImpl) UpdateFlair(ctx context.Context, communityID, flairID int, data dto.CommunityFlairRequest) (*model.CommunityFlair, error) {
+	if err := s.checkModerator(ctx, communityID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	flair, err := s.CommunityFlairRepository.GetFlairByID(ctx, communityID, flairID)
+	if err != nil {
+		return nil, errors.New("flair not found")
+	}
+
+	updates := map[string]interface{}{}
+	if name := strings.TrimSpace(data.Name); name != "" {
+		flair.Name = name
+		updates["name"] = name
+	}
+	if data.BackgroundColor != "" {
+		flair.BackgroundColor = data.BackgroundColor
+		updates["background_color"] = data.BackgroundColor
+	}
+	if data.TextColor != "" {
+		flair.TextColor = data.TextColor
+		updates["text_color"] = data.TextColor
+	}
+	if len(updates) == 0 {
+		return nil, errors.New("no flair changes to update")
+	}
+	if err := validateFlair(flair); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.CommunityFlairRepository.UpdateFlair(ctx, communityID, flairID, updates)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateRecord) {
+			return nil, errors.New("a flair with the same name already exists")
+		}
+		return nil, errors.New("error updating community flair")
+	}
+	if !updated {
+		return nil, errors.New("flair not found")
+	}
+	return flair, nil
+}
+
+// DeleteFlair removes a flair and takes it off every post that carried it.
+func (s *CommunityFlairServiceImpl) DeleteFlair(ctx context.Context, communityID, flairID, moderatorID int) error {
+	if err := s.checkModerator(ctx, communityID, moderatorID); err != nil {
+		return err
+	}
+
+	deleted, err := s.CommunityFlairRepository.DeleteFlair(ctx, communityID, flairID)
+	if err != nil {
+		return errors.New("error deleting community flair")
+	}
+	if !deleted {
+		return errors.New("flair not found")
+	}
+	return nil
+}
+
+func (s *CommunityFlairServiceImpl) checkModerator(ctx context.Context, communityID, userID int) error {
+	allowed, err := canModerateCommunity(ctx, s.UserRepository, s.ModeratorRepository, communityID, userID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("only moderators can manage the flairs of this community")
+	}
+	return nil
+}
+
+func validateFlair(flair *model.CommunityFlair) error {
+	if utf8.RuneCountInString(flair.Name) > constant.CommunityFlairNameMaxLength {
+		return fmt.Errorf("flair name cannot be longer than %d characters", constant.CommunityFlairNameMaxLength)
+	}
+	if !flairColor.MatchString(flair.BackgroundColor) || !flairColor.MatchString(flair.TextColor) {
+		return errors.New("flair colours must be hex colours such as #1d9bf0")
+	}
+	return nil
+}
+
+// checkPostFlair validates the flair of a post in communityID: it must be one of the
+// community's flairs, and may only be left out when the community does not require one.
+func checkPostFlair(ctx context.Context, communityRepo repository.CommunityRepository, flairRepo repository.CommunityFlairRepository, communityID int, flairID *int) error {
+	if communityID == 0 {
+		if flairID != nil {
+			return errors.New("only community posts can have a flair")
+		}
+		return nil
+	}
+
+	if flairID == nil {
+		community, err := communityRepo.GetCommunityDetailByID(ctx, communityID)
+		if err != nil {
+			return errors.New("community not found")
+		}
+		if community.FlairRequired {
+			return errors.New("posts in this community need a flair")
+		}
+		return nil
+	}
+
+	if _, err := flairRepo.GetFlairByID(ctx, communityID, *flairID); err != nil {
+		return errors.New("flair not found in this community")
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+)
+
+type CommunityRuleService interface {
+	GetRules(ctx context.Context, communityID int) ([]model.CommunityRule, error)
+	CreateRule(ctx context.Context, communityID int, data dto.CommunityRuleRequest) (*model.CommunityRule, error)
+	UpdateRule(ctx context.Context, communityID, ruleID int, data dto.CommunityRuleRequest) (*model.CommunityRule, error)
+	DeleteRule(ctx context.Context, communityID, ruleID, moderatorID int) error
+	ReorderRules(ctx context.Context, communityID int, data dto.ReorderCommunityRulesRequest) ([]model.CommunityRule, error)
+}
+
+type CommunityRuleServiceImpl struct {
+	CommunityRuleRepository repository.CommunityRuleRepository
+	ModeratorRepository     repository.ModeratorRepository
+	UserRepository          repository.UserRepository
+}
+
+func NewCommunityRuleService(
+	ruleRepo repository.CommunityRuleRepository,
+	moderatorRepo repository.ModeratorRepository,
+	userRepo repository.UserRepository,
+) CommunityRuleService {
+	return &CommunityRuleServiceImpl{
+		CommunityRuleRepository: ruleRepo,
+		ModeratorRepository:     moderatorRepo,
+		UserRepository:          userRepo,
+	}
+}
+
+func (s *CommunityRuleServiceImpl) GetRules(ctx context.Context, communityID int) ([]model.CommunityRule, error) {
+	rules, err := s.CommunityRuleRepository.GetRules(ctx, communityID)
+	if err != nil {
+		return nil, errors.New("error retrieving community rules")
+	}
+	return rules, nil
+}
+
+func (s *CommunityRuleServiceImpl) CreateRule(ctx context.Context, communityID int, data dto.CommunityRuleRequest) (*model.CommunityRule, error) {
+	if err := s.checkModerator(ctx, communityID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	rule := model.CommunityRule{
+		CommunityID: communityID,
+		Title:       strings.TrimSpace(data.Title),
+		Description: strings.TrimSpace(data.Description),
+		AppliesTo:   data.AppliesTo,
+	}
+	if rule.AppliesTo == "" {
+		rule.AppliesTo = constant.CommunityRuleAppliesToBoth
+	}
+	if rule.Title == "" {
+		return nil, errors.New("rule title is required")
+	}
+	if err := validateRule(&rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.CommunityRuleRepository.CreateRule(ctx, &rule, constant.CommunityMaxRules); err != nil {
+		if errors.Is(err, repository.ErrLimitReached) {
+			return nil, fmt.Errorf("a community can have at most %d rules", constant.CommunityMaxRules)
+		}
+		return nil, errors.New("error creating community rule")
+	}
+	return &rule, nil
+}
+
+func (s *CommunityRuleServiceImpl) UpdateRule(ctx context.Context, communityID, ruleID int, data dto.CommunityRuleRequest) (*model.CommunityRule, error) {
+	if err := s.checkModerator(ctx, communityID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.CommunityRuleRepository.GetRuleByID(ctx, communityID, ruleID)
+	if err != nil {
+		return nil, errors.New("rule not found")
+	}
+
+	updates := map[string]interface{}{}
+	if title := strings.TrimSpace(data.Title); title != "" {
+		rule.Title = title
+		updates["title"] = title
+	}
+	if description := strings.TrimSpace(data.Description); description != "" {
+		rule.Description = description
+		updates["description"] = description
+	}
+	if data.AppliesTo != "" {
+		rule.AppliesTo = data.AppliesTo
+		updates["applies_to"] = data.AppliesTo
+	}
+	if len(updates) == 0 {
+		return nil, errors.New("no rule changes to update")
+	}
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.CommunityRuleRepository.UpdateRule(ctx, communityID, ruleID, updates)
+	if err != nil {
+		return nil, errors.New("error updating community rule")
+	}
+	if !updated {
+		return nil, errors.New("rule not found")
+	}
+	return rule, nil
+}
+
+func (s *CommunityRuleServiceImpl) DeleteRule(ctx context.Context, communityID, ruleID, moderatorID int) error {
+	if err := s.checkModerator(ctx, communityID, moderatorID); err != nil {
+		return err
+	}
+
+	deleted, err := s.CommunityRuleRepository.DeleteRule(ctx, communityID, ruleID)
+	if err != nil {
+		return errors.New("error deleting community rule")
+	}
+	if !deleted {
+		return errors.New("rule not found")
+	}
+	return nil
+}
+
+func (s *CommunityRuleServiceImpl) ReorderRules(ctx context.Context, communityID int, data dto.ReorderCommunityRulesRequest) ([]model.CommunityRule, error) {
+	if err := s.checkModerator(ctx, communityID, data.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.CommunityRuleRepository.ReorderRules(ctx, communityID, data.RuleIDs); err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return nil, errors.New("rule_ids must list every rule of the community exactly once")
+		}
+		return nil, errors.New("error reordering community rules")
+	}
+
+	return s.GetRules(ctx, communityID)
+}
+
+func (s *CommunityRuleServiceImpl) checkModerator(ctx context.Context, communityID, userID int) error {
+	allowed, err := canModerateCommunity(ctx, s.UserRepository, s.ModeratorRepository, communityID, userID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("only moderators can manage the rules of this community")
+	}
+	return nil
+}
+
+func validateRule(rule *model.CommunityRule) error {
+	if utf8.RuneCountInString(rule.Title) > constant.CommunityRuleTitleMaxLength {
+		return fmt.Errorf("rule title cannot be longer than %d characters", constant.CommunityRuleTitleMaxLength)
+	}
+	if utf8.RuneCountInString(rule.Description) > constant.CommunityRuleDescriptionMaxLength {
+		return fmt.Errorf("rule description cannot be longer than %d characters", constant.CommunityRuleDescriptionMaxLength)
+	}
+	switch rule.AppliesTo {
+	case constant.CommunityRuleAppliesToPosts, constant.CommunityRuleAppliesToComments, constant.CommunityRuleAppliesToBoth:
+		return nil
+	default:
+		return errors.New("applies_to must be posts, comments or both")
+	}
+}
+
+// citedRule loads the rule a report or removal cites and checks that it belongs to the
+// community of the reported content and covers target, a post or a comment.
+func citedRule(ctx context.Context, ruleRepo repository.CommunityRuleRepository, communityID, ruleID int, target string) (*model.CommunityRule, error) {
+	if communityID == 0 {
+		return nil, errors.New("only content in a community can cite a community rule")
+	}
+
+	rule, err := ruleRepo.GetRuleByID(ctx, communityID, ruleID)
+	if err != nil {
+		return nil, errors.New("rule not found in this community")
+	}
+	if rule.AppliesTo != constant.CommunityRuleAppliesToBoth && rule.AppliesTo != target {
+		return nil, fmt.Errorf("rule %d does not apply to %s", rule.Position, target)
+	}
+	return rule, nil
+}
@@ -2,100 +2,733 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"log"
+	"math/big"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/dto"
 	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/publisher"
 	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/key_value_store"
+	"github.com/temuka-api-service/util/text"
 )
 
 type CommunityService interface {
 	CreateCommunity(ctx context.Context, data dto.CreateCommunityRequest) (*model.Community, error)
-	GetCommunities(ctx context.Context) ([]model.Community, error)
+	GetCommunities(ctx context.Context, data dto.SearchCommunitiesRequest, page, limit int) ([]model.Community, int64, error)
+	GetTrendingCommunities(ctx context.Context, window string, page, limit int) ([]model.Community, int64, error)
+	GetSimilarCommunities(ctx context.Context, id, page, limit int) ([]model.Community, int64, error)
+	GetCommunityCategories(ctx context.Context) ([]repository.CommunityCategoryCount, error)
 	UpdateCommunity(ctx context.Context, id int, data dto.UpdateCommunityRequest) (*model.Community, error)
-	DeleteCommunity(ctx context.Context, id int) error
-	JoinCommunity(ctx context.Context, id int, data dto.JoinCommunityRequest) error
-	GetCommunityPosts(ctx context.Context, id int, filters map[string]interface{}) ([]model.CommunityPost, error)
+	DeleteCommunity(ctx context.Context, id, userID int) (time.Time, error)
+	ArchiveCommunity(ctx context.Context, id, userID int) error
+	RestoreCommunity(ctx context.Context, id, userID int) (*model.Community, error)
+	PurgeDeletedCommunities(ctx context.Context) error
+	TransferOwnership(ctx context.Context, id int, data dto.TransferOwnershipRequest) error
+	RespondOwnershipTransfer(ctx context.Context, id int, accept bool, userID int) error
+	CancelOwnershipTransfer(ctx context.Context, id, userID int) error
+	JoinCommunity(ctx context.Context, id int, data dto.JoinCommunityRequest) (string, error)
+	CancelJoinRequest(ctx context.Context, id, userID int) error
+	GetJoinRequests(ctx context.Context, id, moderatorID, page, limit int) ([]dto.CommunityJoinRequestResponse, int64, error)
+	ReviewJoinRequest(ctx context.Context, id, requestID int, approve bool, data dto.ReviewJoinRequest) error
+	CreateInvite(ctx context.Context, id int, data dto.CreateCommunityInviteRequest) (*model.CommunityInvite, error)
+	InviteUser(ctx context.Context, id int, data dto.InviteUserRequest) error
+	GetInvites(ctx context.Context, id, moderatorID, page, limit int) ([]model.CommunityInvite, int64, error)
+	RevokeInvite(ctx context.Context, id, inviteID, moderatorID int) error
+	LeaveCommunity(ctx context.Context, id, userID int) error
+	GetCommunityMembers(ctx context.Context, id, viewerID int, search string, page, limit int) ([]dto.CommunityMemberResponse, int64, error)
+	GetCommunityPosts(ctx context.Context, id, viewerID int, filters map[string]interface{}) ([]model.Post, error)
 	GetCommunityDetail(ctx context.Context, slug string) (*model.Community, error)
 	GetUserJoinedCommunities(ctx context.Context, data dto.GetUserJoinedCommunitiesRequest) ([]model.Community, error)
+	ReconcileCounts(ctx context.Context) error
+	BanMember(ctx context.Context, id int, data dto.CommunitySanctionRequest) error
+	UnbanMember(ctx context.Context, id int, data dto.CommunitySanctionRequest) error
+	MuteMember(ctx context.Context, id int, data dto.CommunitySanctionRequest) error
+	UnmuteMember(ctx context.Context, id int, data dto.CommunitySanctionRequest) error
+	GetCommunitySanctions(ctx context.Context, id, moderatorID, page, limit int) ([]model.CommunitySanction, int64, error)
+	ExpireSanctions(ctx context.Context) error
 }
 
+const (
+	communityCountsLockKey = "lock_community_counts_reconciler"
+	communityCountsLockTTL = 10 * time.Minute
+
+	communitySanctionsLockKey   = "lock_community_sanctions_expirer"
+	communitySanctionsLockTTL   = 50 * time.Second
+	communitySanctionsBatchSize = 100
+
+	communitySearchResyncBatchSize = 500
+
+	communityPurgeLockKey   = "lock_community_deletion_purger"
+	communityPurgeLockTTL   = 30 * time.Minute
+	communityPurgeBatchSize = 20
+
+	communityTrendingCacheTTL = 5 * time.Minute
+)
+
 type CommunityServiceImpl struct {
-	CommunityRepository repository.CommunityRepository
+	CommunityRepository    repository.CommunityRepository
+	ModeratorRepository    repository.ModeratorRepository
+	UserRepository         repository.UserRepository
+	UniversityRepository   repository.UniversityRepository
+	NotificationRepository repository.NotificationRepository
+	WikiRepository         repository.CommunityWikiRepository
+	SlugService            SlugService
+	Redis                  key_value_store.RedisWrapper
+	SearchIndexPublisher   publisher.SearchIndexPublisher
 }
 
-func NewCommunityService(repo repository.CommunityRepository) CommunityService {
+func NewCommunityService(
+	repo repository.CommunityRepository,
+	moderatorRepo repository.ModeratorRepository,
+	userRepo repository.UserRepository,
+	universityRepo repository.UniversityRepository,
+	notificationRepo repository.NotificationRepository,
+	wikiRepo repository.CommunityWikiRepository,
+	slugService SlugService,
+	redis key_value_store.RedisWrapper,
+	searchIndexPublisher publisher.SearchIndexPublisher,
+) CommunityService {
 	return &CommunityServiceImpl{
-		CommunityRepository: repo,
+		CommunityRepository:    repo,
+		ModeratorRepository:    moderatorRepo,
+		UserRepository:         userRepo,
+		UniversityRepository:   universityRepo,
+		NotificationRepository: notificationRepo,
+		WikiRepository:         wikiRepo,
+		SlugService:            slugService,
+		Redis:                  redis,
+		SearchIndexPublisher:   searchIndexPublisher,
 	}
 }
 
+// CreateCommunity creates a community owned by data.UserID, who also becomes its first
+// member and moderator.
 func (s *CommunityServiceImpl) CreateCommunity(ctx context.Context, data dto.CreateCommunityRequest) (*model.Community, error) {
+	if data.UserID == 0 {
+		return nil, errors.New("community owner is required")
+	}
 	if !s.CommunityRepository.CheckCommunityNameAvailability(ctx, data.Name) {
 		return nil, errors.New("community with the same name already exists")
 	}
 
+	visibility, err := resolveCommunityVisibility(data.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCommunityCategory(data.Category); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeCommunityTags(data.Tags)
+	if err != nil {
+		return nil, err
+	}
+	universityID, err := s.resolveCommunityUniversity(ctx, data.UniversityID)
+	if err != nil {
+		return nil, err
+	}
+
 	newCommunity := model.Community{
 		Name:         data.Name,
-		Slug:         strings.ReplaceAll(strings.ToLower(data.Name), " ", "_"),
 		Description:  data.Description,
 		LogoPicture:  data.LogoPicture,
 		CoverPicture: data.CoverPicture,
+		Visibility:   visibility,
+		OwnerID:      &data.UserID,
+		Status:       constant.CommunityStatusActive,
+		Category:     data.Category,
+		UniversityID: universityID,
+	}
+	for _, name := range tags {
+		newCommunity.Tags = append(newCommunity.Tags, model.CommunityTag{Name: name})
 	}
 
-	if err := s.CommunityRepository.CreateCommunity(ctx, &newCommunity); err != nil {
+	err = s.SlugService.CreateWithSlug(ctx, constant.SlugEntityCommunity, data.Name, func(slug string) error {
+		newCommunity.Slug = slug
+		return s.CommunityRepository.CreateCommunity(ctx, &newCommunity)
+	})
+	if err != nil {
 		return nil, errors.New("error creating community")
 	}
 
 	return &newCommunity, nil
 }
 
-func (s *CommunityServiceImpl) GetCommunities(ctx context.Context) ([]model.Community, error) {
-	communities, err := s.CommunityRepository.GetCommunities(ctx)
+// GetCommunities lists the community directory, optionally searched and filtered. Archived
+// communities are left out.
+func (s *CommunityServiceImpl) GetCommunities(ctx context.Context, data dto.SearchCommunitiesRequest, page, limit int) ([]model.Community, int64, error) {
+	if err := checkCommunityCategory(data.Category); err != nil {
+		return nil, 0, err
+	}
+
+	filter := repository.CommunityFilter{
+		Query:        strings.TrimSpace(data.Query),
+		Category:     data.Category,
+		UniversityID: data.UniversityID,
+	}
+	if data.Tag != "" {
+		if filter.Tag = text.NormalizeHashtag(data.Tag); filter.Tag == "" {
+			return nil, 0, errors.New("invalid community tag")
+		}
+	}
+
+	communities, total, err := s.CommunityRepository.SearchCommunities(ctx, filter, (page-1)*limit, limit)
 	if err != nil {
-		return nil, errors.New("error retrieving communities")
+		return nil, 0, errors.New("error retrieving communities")
+	}
+	return communities, total, nil
+}
+
+type trendingCommunitiesPage struct {
+	Communities []model.Community `json:"communities"`
+	Total       int64             `json:"total"`
+}
+
+// GetTrendingCommunities ranks communities by their recent joins and posts over window.
+// Pages are cached for a few minutes.
+func (s *CommunityServiceImpl) GetTrendingCommunities(ctx context.Context, window string, page, limit int) ([]model.Community, int64, error) {
+	if window == "" {
+		window = constant.DefaultCommunityTrendingWindow
+	}
+	duration, ok := constant.CommunityTrendingWindows[window]
+	if !ok {
+		return nil, 0, errors.New("invalid trending window")
+	}
+
+	cacheKey := fmt.Sprintf("trending_communities_%s_%d_%d", window, page, limit)
+
+	var cached trendingCommunitiesPage
+	if err := s.Redis.Get(cacheKey, &cached); err == nil {
+		return cached.Communities, cached.Total, nil
+	}
+
+	communities, total, err := s.CommunityRepository.GetTrendingCommunities(ctx, time.Now().Add(-duration), (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving trending communities")
+	}
+
+	_ = s.Redis.Set(cacheKey, trendingCommunitiesPage{Communities: communities, Total: total}, communityTrendingCacheTTL)
+
+	return communities, total, nil
+}
+
+// GetSimilarCommunities lists the communities whose members overlap most with those of the
+// given one.
+func (s *CommunityServiceImpl) GetSimilarCommunities(ctx context.Context, id, page, limit int) ([]model.Community, int64, error) {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return nil, 0, errors.New("community not found")
+	}
+
+	communities, total, err := s.CommunityRepository.GetSimilarCommunities(ctx, community, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving similar communities")
+	}
+	return communities, total, nil
+}
+
+// GetCommunityCategories lists every category with the number of communities filed under it.
+func (s *CommunityServiceImpl) GetCommunityCategories(ctx context.Context) ([]repository.CommunityCategoryCount, error) {
+	counts, err := s.CommunityRepository.GetCategoryCounts(ctx)
+	if err != nil {
+		return nil, errors.New("error retrieving community categories")
+	}
+
+	byCategory := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		byCategory[count.Category] = count.CommunitiesCount
+	}
+
+	categories := make([]repository.CommunityCategoryCount, len(constant.CommunityCategories))
+	for i, category := range constant.CommunityCategories {
+		categories[i] = repository.CommunityCategoryCount{Category: category, CommunitiesCount: byCategory[category]}
+	}
+	return categories, nil
+}
+
+func checkCommunityCategory(category string) error {
+	if category == "" {
+		return nil
+	}
+	for _, c := range constant.CommunityCategories {
+		if c == category {
+			return nil
+		}
+	}
+	return errors.New("invalid community category")
+}
+
+// normalizeCommunityTags normalises tags like post hashtags and drops duplicates.
+func normalizeCommunityTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+	for _, tag := range tags {
+		name := text.NormalizeHashtag(tag)
+		if name == "" {
+			return nil, fmt.Errorf("invalid community tag %q", tag)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	if len(normalized) > constant.CommunityMaxTags {
+		return nil, fmt.Errorf("a community can have at most %d tags", constant.CommunityMaxTags)
+	}
+	return normalized, nil
+}
+
+// resolveCommunityUniversity checks that a linked university exists. Nil and 0 mean no
+// university.
+func (s *CommunityServiceImpl) resolveCommunityUniversity(ctx context.Context, universityID *int) (*int, error) {
+	if universityID == nil || *universityID == 0 {
+		return nil, nil
+	}
+	if _, err := s.UniversityRepository.GetUniversityByID(ctx, *universityID); err != nil {
+		return nil, errors.New("university not found")
+	}
+	return universityID, nil
+}
+
+func resolveCommunityVisibility(visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return constant.CommunityVisibilityPublic, nil
+	case constant.CommunityVisibilityPublic, constant.CommunityVisibilityRestricted, constant.CommunityVisibilityPrivate:
+		return visibility, nil
+	default:
+		return "", errors.New("invalid community visibility")
 	}
-	return communities, nil
 }
 
+// UpdateCommunity changes the profile of a community. Only moderators and admins may change
+// its visibility, discovery settings or whether posts need a flair; making a community
+// private or public again resyncs its posts in search. Renaming it moves it to a new slug
+// and keeps the old one as a redirect. Archived communities cannot be changed until restored.
 func (s *CommunityServiceImpl) UpdateCommunity(ctx context.Context, id int, data dto.UpdateCommunityRequest) (*model.Community, error) {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("community not found")
+	}
+	if community.Status == constant.CommunityStatusArchived {
+		return nil, newError(constant.CommunityErrorArchived, "this community is archived")
+	}
+
+	renamed := data.Name != "" && data.Name != community.Name
+	if renamed && !s.CommunityRepository.CheckCommunityNameAvailability(ctx, data.Name) {
+		return nil, errors.New("community with the same name already exists")
+	}
+
 	updated := model.Community{
 		Name:         data.Name,
-		Slug:         data.Slug,
 		Description:  data.Description,
 		LogoPicture:  data.LogoPicture,
 		CoverPicture: data.CoverPicture,
 	}
 
+	var previousVisibility string
+	var tags []string
+	var universityID *int
+	settings := data.Visibility != "" || data.FlairRequired != nil ||
+		data.Category != "" || data.Tags != nil || data.UniversityID != nil
+	if settings {
+		allowed, err := s.canModerateCommunity(ctx, id, data.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("only moderators can change the settings of this community")
+		}
+		if data.Visibility != "" {
+			if updated.Visibility, err = resolveCommunityVisibility(data.Visibility); err != nil {
+				return nil, err
+			}
+			previousVisibility = community.Visibility
+		}
+		if err := checkCommunityCategory(data.Category); err != nil {
+			return nil, err
+		}
+		updated.Category = data.Category
+		if data.Tags != nil {
+			if tags, err = normalizeCommunityTags(*data.Tags); err != nil {
+				return nil, err
+			}
+		}
+		if universityID, err = s.resolveCommunityUniversity(ctx, data.UniversityID); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.CommunityRepository.UpdateCommunity(ctx, id, &updated); err != nil {
 		return nil, errors.New("error updating community")
 	}
+
+	if renamed {
+		if updated.Slug, err = s.SlugService.ChangeSlug(ctx, constant.SlugEntityCommunity, id, data.Name); err != nil {
+			return nil, errors.New("error updating community slug")
+		}
+	}
+
+	if data.FlairRequired != nil {
+		if err := s.CommunityRepository.SetCommunityFlairRequired(ctx, id, *data.FlairRequired); err != nil {
+			return nil, errors.New("error updating community")
+		}
+		updated.FlairRequired = *data.FlairRequired
+	}
+
+	if data.Tags != nil {
+		if err := s.CommunityRepository.SetCommunityTags(ctx, id, tags); err != nil {
+			return nil, errors.New("error updating community tags")
+		}
+		for _, name := range tags {
+			updated.Tags = append(updated.Tags, model.CommunityTag{CommunityID: id, Name: name})
+		}
+	}
+
+	if data.UniversityID != nil {
+		if err := s.CommunityRepository.SetCommunityUniversity(ctx, id, universityID); err != nil {
+			return nil, errors.New("error updating community")
+		}
+		updated.UniversityID = universityID
+	}
+
+	wasPrivate := previousVisibility == constant.CommunityVisibilityPrivate
+	isPrivate := updated.Visibility == constant.CommunityVisibilityPrivate
+	if previousVisibility != "" && wasPrivate != isPrivate {
+		go s.resyncCommunitySearch(id, isPrivate)
+	}
+
 	return &updated, nil
 }
 
-func (s *CommunityServiceImpl) DeleteCommunity(ctx context.Context, id int) error {
+// resyncCommunitySearch removes the public posts and wiki pages of a community that just
+// became private from the search index, or adds them back once it is no longer private.
+func (s *CommunityServiceImpl) resyncCommunitySearch(id int, private bool) {
+	s.resyncCommunityPostsSearch(id, private)
+	s.resyncCommunityWikiSearch(id, private)
+}
+
+func (s *CommunityServiceImpl) resyncCommunityPostsSearch(id int, private bool) {
+	ctx := context.Background()
+
+	afterID := 0
+	for {
+		posts, err := s.CommunityRepository.GetPublicPostsAfter(ctx, id, afterID, communitySearchResyncBatchSize)
+		if err != nil {
+			log.Printf("Failed to resync search index of community %d: %v", id, err)
+			return
+		}
+
+		for i := range posts {
+			postID := fmt.Sprintf("%d", posts[i].ID)
+			if private {
+				s.SearchIndexPublisher.PublishSyncEvent(constant.EventOperationDelete, constant.EventEntityTypePost, postID, nil)
+			} else {
+				s.SearchIndexPublisher.PublishSyncEvent(constant.EventOperationCreate, constant.EventEntityTypePost, postID, postSearchPayload(&posts[i]))
+			}
+		}
+
+		if len(posts) < communitySearchResyncBatchSize {
+			return
+		}
+		afterID = posts[len(posts)-1].ID
+	}
+}
+
+func (s *CommunityServiceImpl) resyncCommunityWikiSearch(id int, private bool) {
+	ctx := context.Background()
+
+	afterID := 0
+	for {
+		pages, err := s.WikiRepository.GetPagesAfter(ctx, id, afterID, communitySearchResyncBatchSize)
+		if err != nil {
+			log.Printf("Failed to resync wiki search index of community %d: %v", id, err)
+			return
+		}
+
+		for i := range pages {
+			pageID := fmt.Sprintf("%d", pages[i].ID)
+			if private {
+				s.SearchIndexPublisher.PublishSyncEvent(constant.EventOperationDelete, constant.EventEntityTypeWikiPage, pageID, nil)
+			} else {
+				s.SearchIndexPublisher.PublishSyncEvent(constant.EventOperationCreate, constant.EventEntityTypeWikiPage, pageID, wikiPageSearchPayload(&pages[i]))
+			}
+		}
+
+		if len(pages) < communitySearchResyncBatchSize {
+			return
+		}
+		afterID = pages[len(pages)-1].ID
+	}
+}
+
+// DeleteCommunity deletes a community on behalf of its owner or an admin. The community and
+// its posts disappear right away, but it can be restored until the returned time, after
+// which PurgeDeletedCommunities removes it for good.
+func (s *CommunityServiceImpl) DeleteCommunity(ctx context.Context, id, userID int) (time.Time, error) {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return time.Time{}, errors.New("community not found")
+	}
+	if err := s.checkCommunityOwner(ctx, community, userID, "only the owner can delete this community"); err != nil {
+		return time.Time{}, err
+	}
+
 	if err := s.CommunityRepository.DeleteCommunity(ctx, id); err != nil {
-		return errors.New("error deleting community")
+		return time.Time{}, errors.New("error deleting community")
+	}
+	if community.Visibility != constant.CommunityVisibilityPrivate {
+		go s.resyncCommunitySearch(id, true)
+	}
+
+	return time.Now().Add(constant.CommunityDeletionRecoveryWindow), nil
+}
+
+// ArchiveCommunity makes a community read-only and hides it from discovery. Its posts stay
+// readable; RestoreCommunity reopens it.
+func (s *CommunityServiceImpl) ArchiveCommunity(ctx context.Context, id, userID int) error {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return errors.New("community not found")
+	}
+	if err := s.checkCommunityOwner(ctx, community, userID, "only the owner can archive this community"); err != nil {
+		return err
+	}
+
+	if err := s.CommunityRepository.SetCommunityArchived(ctx, id, true); err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return errors.New("community is already archived")
+		}
+		return errors.New("error archiving community")
 	}
 	return nil
 }
 
-func (s *CommunityServiceImpl) JoinCommunity(ctx context.Context, id int, data dto.JoinCommunityRequest) error {
+// RestoreCommunity reopens an archived community, or brings back a deleted one while it is
+// still inside its recovery window.
+func (s *CommunityServiceImpl) RestoreCommunity(ctx context.Context, id, userID int) (*model.Community, error) {
 	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err == nil {
+		if err := s.checkCommunityOwner(ctx, community, userID, "only the owner can restore this community"); err != nil {
+			return nil, err
+		}
+		if err := s.CommunityRepository.SetCommunityArchived(ctx, id, false); err != nil {
+			if errors.Is(err, repository.ErrStaleRecord) {
+				return nil, errors.New("community is not archived")
+			}
+			return nil, errors.New("error restoring community")
+		}
+		community.Status = constant.CommunityStatusActive
+		community.ArchivedAt = nil
+		return community, nil
+	}
+
+	community, err = s.CommunityRepository.GetDeletedCommunity(ctx, id)
 	if err != nil {
-		return errors.New("error retrieving community")
+		return nil, errors.New("community not found")
 	}
-	if community == nil {
+	if err := s.checkCommunityOwner(ctx, community, userID, "only the owner can restore this community"); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.CommunityRepository.RestoreCommunity(ctx, id, time.Now().Add(-constant.CommunityDeletionRecoveryWindow))
+	if err != nil {
+		return nil, errors.New("error restoring community")
+	}
+	if !restored {
+		return nil, errors.New("the recovery window of this community has passed")
+	}
+	if community.Visibility != constant.CommunityVisibilityPrivate {
+		go s.resyncCommunitySearch(id, false)
+	}
+
+	return s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+}
+
+// PurgeDeletedCommunities removes communities whose recovery window has passed, together
+// with their members, moderators and posts.
+func (s *CommunityServiceImpl) PurgeDeletedCommunities(ctx context.Context) error {
+	lockValue := uuid.NewString()
+
+	acquired, err := s.Redis.AcquireLock(communityPurgeLockKey, lockValue, communityPurgeLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire community purge lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := s.Redis.ReleaseLock(communityPurgeLockKey, lockValue); err != nil {
+			log.Printf("Failed to release community purge lock: %v", err)
+		}
+	}()
+
+	communities, err := s.CommunityRepository.GetCommunitiesDeletedBefore(ctx, time.Now().Add(-constant.CommunityDeletionRecoveryWindow), communityPurgeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, community := range communities {
+		postIDs, err := s.CommunityRepository.PurgeCommunity(ctx, community.ID)
+		if err != nil {
+			log.Printf("Failed to purge community %d: %v", community.ID, err)
+			continue
+		}
+		for _, postID := range postIDs {
+			s.SearchIndexPublisher.PublishSyncEvent(constant.EventOperationDelete, constant.EventEntityTypePost, fmt.Sprintf("%d", postID), nil)
+		}
+	}
+
+	return nil
+}
+
+// TransferOwnership offers the ownership of a community to one of its members, who becomes
+// the owner once they accept it.
+func (s *CommunityServiceImpl) TransferOwnership(ctx context.Context, id int, data dto.TransferOwnershipRequest) error {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
 		return errors.New("community not found")
 	}
+	if community.OwnerID == nil || *community.OwnerID != data.UserID {
+		return errors.New("only the owner can transfer this community")
+	}
+	if data.NewOwnerID == data.UserID {
+		return errors.New("user already owns the community")
+	}
 
-	existingMember, err := s.CommunityRepository.CheckMembership(ctx, id, data.UserID)
+	member, err := s.CommunityRepository.CheckMembership(ctx, id, data.NewOwnerID)
 	if err != nil {
 		return errors.New("error checking membership")
 	}
+	if member == nil || member.Banned {
+		return errors.New("ownership can only be transferred to a member of the community")
+	}
+
+	transfer := model.CommunityOwnershipTransfer{
+		CommunityID: id,
+		FromUserID:  data.UserID,
+		ToUserID:    data.NewOwnerID,
+		Status:      constant.CommunityOwnershipTransferPending,
+		ExpiresAt:   time.Now().Add(constant.CommunityOwnershipTransferTTL),
+	}
+	if err := s.CommunityRepository.CreateOwnershipTransfer(ctx, &transfer); err != nil {
+		if errors.Is(err, repository.ErrDuplicateRecord) {
+			return errors.New("an ownership transfer is already pending")
+		}
+		return errors.New("error creating ownership transfer")
+	}
+
+	s.notifyOwnershipTransfer(ctx, data.NewOwnerID, data.UserID, constant.NotificationTypeCommunityOwnershipOffer,
+		"You have been offered the ownership of "+community.Name)
+	return nil
+}
+
+// RespondOwnershipTransfer accepts or declines the ownership transfer offered to userID
+// and lets the current owner know.
+func (s *CommunityServiceImpl) RespondOwnershipTransfer(ctx context.Context, id int, accept bool, userID int) error {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return errors.New("community not found")
+	}
+
+	transfer, err := s.CommunityRepository.RespondOwnershipTransfer(ctx, id, userID, accept)
+	if err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return errors.New("no pending ownership transfer for this user")
+		}
+		return errors.New("error responding to ownership transfer")
+	}
+
+	if accept {
+		s.notifyOwnershipTransfer(ctx, transfer.FromUserID, userID, constant.NotificationTypeCommunityOwnershipAccepted,
+			"The ownership transfer of "+community.Name+" has been accepted")
+	} else {
+		s.notifyOwnershipTransfer(ctx, transfer.FromUserID, userID, constant.NotificationTypeCommunityOwnershipDeclined,
+			"The ownership transfer of "+community.Name+" has been declined")
+	}
+	return nil
+}
+
+func (s *CommunityServiceImpl) CancelOwnershipTransfer(ctx context.Context, id, userID int) error {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return errors.New("community not found")
+	}
+	if err := s.checkCommunityOwner(ctx, community, userID, "only the owner can cancel the ownership transfer"); err != nil {
+		return err
+	}
+
+	cancelled, err := s.CommunityRepository.CancelOwnershipTransfer(ctx, id)
+	if err != nil {
+		return errors.New("error cancelling ownership transfer")
+	}
+	if !cancelled {
+		return errors.New("no pending ownership transfer")
+	}
+	return nil
+}
+
+// checkCommunityOwner returns an error carrying message unless userID owns the community or
+// is an admin.
+func (s *CommunityServiceImpl) checkCommunityOwner(ctx context.Context, community *model.Community, userID int, message string) error {
+	if community.OwnerID != nil && *community.OwnerID == userID {
+		return nil
+	}
+	user, err := s.UserRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.Role != constant.UserRoleAdmin {
+		return errors.New(message)
+	}
+	return nil
+}
+
+func (s *CommunityServiceImpl) notifyOwnershipTransfer(ctx context.Context, userID, actorID int, notificationType, message string) {
+	notification := model.Notification{
+		UserID:  userID,
+		ActorID: actorID,
+		Type:    notificationType,
+		Message: message,
+		Read:    false,
+	}
+	if err := s.NotificationRepository.CreateNotification(ctx, &notification); err != nil {
+		log.Printf("Failed to notify user %d of community ownership transfer: %v", userID, err)
+	}
+}
+
+// JoinCommunity adds the user to a public community, or to any community with a valid invite
+// code. Joining a restricted or private community without one files a join request for the
+// moderators instead. It returns whether the user joined or is waiting for approval.
+func (s *CommunityServiceImpl) JoinCommunity(ctx context.Context, id int, data dto.JoinCommunityRequest) (string, error) {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return "", errors.New("error retrieving community")
+	}
+	if community == nil {
+		return "", errors.New("community not found")
+	}
+	if community.Status == constant.CommunityStatusArchived {
+		return "", newError(constant.CommunityErrorArchived, "this community is archived")
+	}
+
+	existingMember, err := s.CommunityRepository.CheckMembership(ctx, id, data.UserID)
+	if err != nil {
+		return "", errors.New("error checking membership")
+	}
 	if existingMember != nil {
-		return errors.New("user already a member of the community")
+		if err := memberSanctionError(existingMember, false); err != nil {
+			return "", err
+		}
+		return "", errors.New("user already a member of the community")
 	}
 
 	newMember := model.CommunityMember{
@@ -103,32 +736,415 @@ func (s *CommunityServiceImpl) JoinCommunity(ctx context.Context, id int, data d
 		CommunityID: id,
 	}
 
+	if data.InviteCode != "" {
+		if err := s.CommunityRepository.JoinWithInvite(ctx, &newMember, data.InviteCode); err != nil {
+			if errors.Is(err, repository.ErrStaleRecord) {
+				return "", errors.New("invite is invalid or has expired")
+			}
+			if errors.Is(err, repository.ErrDuplicateRecord) {
+				return "", errors.New("user already a member of the community")
+			}
+			return "", errors.New("error adding community member")
+		}
+		return constant.CommunityJoinStatusJoined, nil
+	}
+
+	if community.Visibility == constant.CommunityVisibilityRestricted || community.Visibility == constant.CommunityVisibilityPrivate {
+		request := model.CommunityJoinRequest{
+			CommunityID: id,
+			UserID:      data.UserID,
+			Message:     data.Message,
+			Status:      constant.CommunityJoinRequestPending,
+		}
+		if err := s.CommunityRepository.CreateJoinRequest(ctx, &request); err != nil {
+			if errors.Is(err, repository.ErrDuplicateRecord) {
+				return "", errors.New("a join request is already pending")
+			}
+			return "", errors.New("error creating join request")
+		}
+		return constant.CommunityJoinStatusRequested, nil
+	}
+
 	if err := s.CommunityRepository.AddCommunityMember(ctx, &newMember); err != nil {
-		return errors.New("error adding community member")
+		if errors.Is(err, repository.ErrDuplicateRecord) {
+			return "", errors.New("user already a member of the community")
+		}
+		return "", errors.New("error adding community member")
+	}
+
+	return constant.CommunityJoinStatusJoined, nil
+}
+
+func (s *CommunityServiceImpl) CancelJoinRequest(ctx context.Context, id, userID int) error {
+	cancelled, err := s.CommunityRepository.CancelJoinRequest(ctx, id, userID)
+	if err != nil {
+		return errors.New("error cancelling join request")
+	}
+	if !cancelled {
+		return errors.New("no pending join request")
+	}
+	return nil
+}
+
+// GetJoinRequests lists the pending join requests of a community for its moderators.
+func (s *CommunityServiceImpl) GetJoinRequests(ctx context.Context, id, moderatorID, page, limit int) ([]dto.CommunityJoinRequestResponse, int64, error) {
+	allowed, err := s.canModerateCommunity(ctx, id, moderatorID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !allowed {
+		return nil, 0, errors.New("only moderators can view the join requests of this community")
+	}
+
+	rows, total, err := s.CommunityRepository.GetPendingJoinRequests(ctx, id, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving join requests")
+	}
+
+	requests := make([]dto.CommunityJoinRequestResponse, 0, len(rows))
+	for _, row := range rows {
+		requests = append(requests, dto.CommunityJoinRequestResponse{
+			ID:             row.ID,
+			UserID:         row.UserID,
+			Username:       row.Username,
+			Displayname:    row.Displayname,
+			ProfilePicture: row.ProfilePicture,
+			Message:        row.Message,
+			CreatedAt:      row.CreatedAt,
+		})
+	}
+
+	return requests, total, nil
+}
+
+// ReviewJoinRequest approves or rejects a pending join request and notifies the user.
+func (s *CommunityServiceImpl) ReviewJoinRequest(ctx context.Context, id, requestID int, approve bool, data dto.ReviewJoinRequest) error {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return errors.New("community not found")
+	}
+
+	allowed, err := s.canModerateCommunity(ctx, id, data.UserID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("only moderators can review join requests of this community")
+	}
+
+	request, err := s.CommunityRepository.ReviewJoinRequest(ctx, id, requestID, data.UserID, approve, data.Reason)
+	if err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return errors.New("join request is not pending")
+		}
+		return errors.New("error reviewing join request")
+	}
+
+	notification := model.Notification{
+		UserID:  request.UserID,
+		ActorID: data.UserID,
+		Type:    constant.NotificationTypeCommunityJoinApproved,
+		Message: "Your request to join " + community.Name + " has been approved",
+		Read:    false,
+	}
+	if !approve {
+		notification.Type = constant.NotificationTypeCommunityJoinRejected
+		notification.Message = "Your request to join " + community.Name + " has been declined"
+		if data.Reason != "" {
+			notification.Message += ": " + data.Reason
+		}
+	}
+	if err := s.NotificationRepository.CreateNotification(ctx, &notification); err != nil {
+		log.Printf("Failed to notify user %d of join request %d: %v", request.UserID, request.ID, err)
+	}
+
+	return nil
+}
+
+// CreateInvite creates an invite link for a community. Only moderators and admins may.
+func (s *CommunityServiceImpl) CreateInvite(ctx context.Context, id int, data dto.CreateCommunityInviteRequest) (*model.CommunityInvite, error) {
+	if _, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id); err != nil {
+		return nil, errors.New("community not found")
+	}
+
+	allowed, err := s.canModerateCommunity(ctx, id, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("only moderators can create invite links for this community")
+	}
+
+	if data.MaxUses < 0 {
+		return nil, errors.New("max_uses cannot be negative")
+	}
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
 	}
 
-	community.MembersCount++
-	if err := s.CommunityRepository.UpdateCommunity(ctx, id, community); err != nil {
-		return errors.New("error updating community member count")
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, errors.New("error generating invite code")
+	}
+
+	invite := model.CommunityInvite{
+		CommunityID: id,
+		Code:        code,
+		CreatedBy:   data.UserID,
+		MaxUses:     data.MaxUses,
+		ExpiresAt:   data.ExpiresAt,
+	}
+	if err := s.CommunityRepository.CreateInvite(ctx, &invite); err != nil {
+		return nil, errors.New("error creating invite")
+	}
+
+	return &invite, nil
+}
+
+// InviteUser sends a single-use invite to one user as a notification. Members may invite to a
+// public community; restricted and private communities only take invites from moderators.
+func (s *CommunityServiceImpl) InviteUser(ctx context.Context, id int, data dto.InviteUserRequest) error {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return errors.New("community not found")
+	}
+
+	allowed, err := s.canModerateCommunity(ctx, id, data.UserID)
+	if err != nil {
+		return err
+	}
+	if !allowed && community.Visibility == constant.CommunityVisibilityPublic {
+		member, err := s.CommunityRepository.CheckMembership(ctx, id, data.UserID)
+		if err != nil {
+			return errors.New("error checking membership")
+		}
+		allowed = member != nil && !member.Banned
+	}
+	if !allowed {
+		return errors.New("not allowed to invite users to this community")
+	}
+
+	inviter, err := s.UserRepository.GetUserByID(ctx, data.UserID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if _, err := s.UserRepository.GetUserByID(ctx, data.InviteeID); err != nil {
+		return errors.New("invited user not found")
+	}
+
+	existing, err := s.CommunityRepository.CheckMembership(ctx, id, data.InviteeID)
+	if err != nil {
+		return errors.New("error checking membership")
+	}
+	if existing != nil {
+		if existing.Banned {
+			return errors.New("user is banned from the community")
+		}
+		return errors.New("user already a member of the community")
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return errors.New("error generating invite code")
+	}
+
+	expiresAt := time.Now().Add(constant.CommunityDirectInviteTTL)
+	invite := model.CommunityInvite{
+		CommunityID:   id,
+		Code:          code,
+		CreatedBy:     data.UserID,
+		InvitedUserID: &data.InviteeID,
+		MaxUses:       1,
+		ExpiresAt:     &expiresAt,
+	}
+	if err := s.CommunityRepository.CreateInvite(ctx, &invite); err != nil {
+		return errors.New("error creating invite")
+	}
+
+	notification := model.Notification{
+		UserID:  data.InviteeID,
+		ActorID: data.UserID,
+		Type:    constant.NotificationTypeCommunityInvite,
+		Message: inviter.Username + " invited you to join " + community.Name + " with invite code " + code,
+		Read:    false,
+	}
+	if err := s.NotificationRepository.CreateNotification(ctx, &notification); err != nil {
+		return errors.New("error creating notification")
+	}
+
+	return nil
+}
+
+func (s *CommunityServiceImpl) GetInvites(ctx context.Context, id, moderatorID, page, limit int) ([]model.CommunityInvite, int64, error) {
+	allowed, err := s.canModerateCommunity(ctx, id, moderatorID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !allowed {
+		return nil, 0, errors.New("only moderators can view the invites of this community")
+	}
+
+	invites, total, err := s.CommunityRepository.GetInvites(ctx, id, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving invites")
+	}
+	return invites, total, nil
+}
+
+func (s *CommunityServiceImpl) RevokeInvite(ctx context.Context, id, inviteID, moderatorID int) error {
+	allowed, err := s.canModerateCommunity(ctx, id, moderatorID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("only moderators can revoke invites of this community")
+	}
+
+	revoked, err := s.CommunityRepository.RevokeInvite(ctx, id, inviteID)
+	if err != nil {
+		return errors.New("error revoking invite")
+	}
+	if !revoked {
+		return errors.New("invite not found")
+	}
+	return nil
+}
+
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
+
+func newInviteCode() (string, error) {
+	code := make([]byte, constant.CommunityInviteCodeLength)
+	max := big.NewInt(int64(len(inviteCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// LeaveCommunity removes the membership of a user. Muted members stay until the mute ends,
+// otherwise leaving and joining again would lift it, and the owner has to transfer the
+// community first.
+func (s *CommunityServiceImpl) LeaveCommunity(ctx context.Context, id, userID int) error {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return errors.New("community not found")
+	}
+	if community.OwnerID != nil && *community.OwnerID == userID {
+		return errors.New("the owner cannot leave the community before transferring it")
+	}
+
+	member, err := s.CommunityRepository.CheckMembership(ctx, id, userID)
+	if err != nil {
+		return errors.New("error checking membership")
+	}
+	if member != nil && !member.Banned && sanctionActive(member.Muted, member.MutedUntil) {
+		return errors.New("muted members cannot leave the community until the mute ends")
+	}
+
+	removed, err := s.CommunityRepository.RemoveCommunityMember(ctx, id, userID)
+	if err != nil {
+		return errors.New("error leaving community")
+	}
+	if !removed {
+		return errors.New("user is not a member of the community")
+	}
+	return nil
+}
+
+// GetCommunityMembers lists the members of a community. Members of a private community are
+// only listed to other members, moderators and admins.
+func (s *CommunityServiceImpl) GetCommunityMembers(ctx context.Context, id, viewerID int, search string, page, limit int) ([]dto.CommunityMemberResponse, int64, error) {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return nil, 0, errors.New("community not found")
+	}
+	if community.Visibility == constant.CommunityVisibilityPrivate {
+		if err := s.checkPrivateCommunityAccess(ctx, id, viewerID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	rows, total, err := s.CommunityRepository.GetCommunityMembers(ctx, id, strings.TrimSpace(search), (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving community members")
+	}
+
+	members := make([]dto.CommunityMemberResponse, 0, len(rows))
+	for _, row := range rows {
+		role := constant.CommunityRoleMember
+		if row.IsModerator {
+			role = constant.CommunityRoleModerator
+		}
+		members = append(members, dto.CommunityMemberResponse{
+			UserID:         row.UserID,
+			Username:       row.Username,
+			Displayname:    row.Displayname,
+			ProfilePicture: row.ProfilePicture,
+			Role:           role,
+			JoinedAt:       row.CreatedAt,
+		})
+	}
+
+	return members, total, nil
+}
+
+// ReconcileCounts repairs members_count and posts_count of communities whose counters no
+// longer match their member and post tables, e.g. after a failed request or a manual fix.
+func (s *CommunityServiceImpl) ReconcileCounts(ctx context.Context) error {
+	lockValue := uuid.NewString()
+
+	acquired, err := s.Redis.AcquireLock(communityCountsLockKey, lockValue, communityCountsLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire community counts lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := s.Redis.ReleaseLock(communityCountsLockKey, lockValue); err != nil {
+			log.Printf("Failed to release community counts lock: %v", err)
+		}
+	}()
+
+	repaired, err := s.CommunityRepository.ReconcileCommunityCounts(ctx)
+	if err != nil {
+		return err
+	}
+	if repaired > 0 {
+		log.Printf("Repaired the counters of %d communities", repaired)
 	}
 
 	return nil
 }
 
-func (s *CommunityServiceImpl) GetCommunityPosts(ctx context.Context, id int, filters map[string]interface{}) ([]model.CommunityPost, error) {
-	posts, err := s.CommunityRepository.GetCommunityPosts(ctx, id, filters)
+func (s *CommunityServiceImpl) GetCommunityPosts(ctx context.Context, id, viewerID int, filters map[string]interface{}) ([]model.Post, error) {
+	posts, err := s.CommunityRepository.GetCommunityPosts(ctx, id, viewerID, filters)
 	if err != nil {
 		return nil, errors.New("error retrieving community posts")
 	}
 	return posts, nil
 }
 
+// GetCommunityDetail returns the community with the given slug. A slug the community used
+// to have yields a SlugMovedError carrying its current one.
 func (s *CommunityServiceImpl) GetCommunityDetail(ctx context.Context, slug string) (*model.Community, error) {
 	community, err := s.CommunityRepository.GetCommunityDetailBySlug(ctx, slug)
-	if err != nil {
-		return nil, errors.New("error retrieving community detail")
+	if err == nil {
+		return community, nil
 	}
-	return community, nil
+
+	id, redirectErr := s.SlugService.GetRedirect(ctx, constant.SlugEntityCommunity, slug)
+	if redirectErr != nil {
+		return nil, errors.New("community not found")
+	}
+	if community, err = s.CommunityRepository.GetCommunityDetailByID(ctx, id); err != nil {
+		return nil, errors.New("community not found")
+	}
+	return nil, &SlugMovedError{Slug: community.Slug}
 }
 
 func (s *CommunityServiceImpl) GetUserJoinedCommunities(ctx context.Context, data dto.GetUserJoinedCommunitiesRequest) ([]model.Community, error) {
@@ -138,3 +1154,391 @@ func (s *CommunityServiceImpl) GetUserJoinedCommunities(ctx context.Context, dat
 	}
 	return communities, nil
 }
+
+func (s *CommunityServiceImpl) checkPrivateCommunityAccess(ctx context.Context, id, viewerID int) error {
+	if viewerID != 0 {
+		member, err := s.CommunityRepository.CheckMembership(ctx, id, viewerID)
+		if err != nil {
+			return errors.New("error checking membership")
+		}
+		if member != nil && !member.Banned {
+			return nil
+		}
+		allowed, err := s.canModerateCommunity(ctx, id, viewerID)
+		if err != nil {
+			return err
+		}
+		if allowed {
+			return nil
+		}
+	}
+	return errors.New("this community is private")
+}
+
+// checkCommunityReadAccess returns a community once viewerID may read its content: anyone
+// for public and restricted communities, members and moderators for private ones.
+func checkCommunityReadAccess(ctx context.Context, communityRepo repository.CommunityRepository, userRepo repository.UserRepository, moderatorRepo repository.ModeratorRepository, communityID, viewerID int) (*model.Community, error) {
+	community, err := communityRepo.GetCommunityDetailByID(ctx, communityID)
+	if err != nil {
+		return nil, errors.New("community not found")
+	}
+	if community.Visibility != constant.CommunityVisibilityPrivate {
+		return community, nil
+	}
+
+	if viewerID != 0 {
+		member, err := communityRepo.CheckMembership(ctx, communityID, viewerID)
+		if err != nil {
+			return nil, errors.New("error checking membership")
+		}
+		if member != nil && !member.Banned {
+			return community, nil
+		}
+		allowed, err := canModerateCommunity(ctx, userRepo, moderatorRepo, communityID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			return community, nil
+		}
+	}
+	return nil, errors.New("this community is private")
+}
+
+// checkCommunityParticipation returns a coded error when userID is banned from a community,
+// or, while writing is set, muted in it, not a member of a restricted or private one, or the
+// community is archived. Timed sanctions count as lifted once they end, even before
+// ExpireSanctions gets to them.
+func checkCommunityParticipation(ctx context.Context, communityRepo repository.CommunityRepository, communityID, userID int, writing bool) error {
+	member, err := communityRepo.CheckMembership(ctx, communityID, userID)
+	if err != nil {
+		return errors.New("error checking membership")
+	}
+	if member != nil {
+		if err := memberSanctionError(member, writing); err != nil {
+			return err
+		}
+	}
+	if !writing {
+		return nil
+	}
+
+	community, err := communityRepo.GetCommunityDetailByID(ctx, communityID)
+	if err != nil {
+		return errors.New("community not found")
+	}
+	if community.Status == constant.CommunityStatusArchived {
+		return newError(constant.CommunityErrorArchived, "this community is archived")
+	}
+	if member != nil && !member.BanOnly {
+		return nil
+	}
+	if community.Visibility == constant.CommunityVisibilityRestricted || community.Visibility == constant.CommunityVisibilityPrivate {
+		return newError(constant.CommunityErrorMembersOnly, "only members can post in this community")
+	}
+	return nil
+}
+
+func memberSanctionError(member *model.CommunityMember, writing bool) error {
+	if sanctionActive(member.Banned, member.BannedUntil) {
+		if member.BannedUntil != nil {
+			return newError(constant.CommunityErrorBanned, "you are banned from this community until "+member.BannedUntil.Format(time.RFC3339))
+		}
+		return newError(constant.CommunityErrorBanned, "you are banned from this community")
+	}
+	if writing && sanctionActive(member.Muted, member.MutedUntil) {
+		if member.MutedUntil != nil {
+			return newError(constant.CommunityErrorMuted, "you are muted in this community until "+member.MutedUntil.Format(time.RFC3339))
+		}
+		return newError(constant.CommunityErrorMuted, "you are muted in this community")
+	}
+	return nil
+}
+
+func sanctionActive(flag bool, until *time.Time) bool {
+	return flag && (until == nil || until.After(time.Now()))
+}
+
+// BanMember bans a user from a community, for good or until data.ExpiresAt. Banned users
+// cannot join, post or comment, and drop out of the member list and count.
+func (s *CommunityServiceImpl) BanMember(ctx context.Context, id int, data dto.CommunitySanctionRequest) error {
+	community, err := s.checkSanctionTarget(ctx, id, data)
+	if err != nil {
+		return err
+	}
+
+	entry := model.CommunitySanction{
+		CommunityID: id,
+		UserID:      data.UserID,
+		ModeratorID: data.ModeratorID,
+		Action:      constant.CommunitySanctionBan,
+		Reason:      data.Reason,
+		ExpiresAt:   data.ExpiresAt,
+	}
+	if err := s.CommunityRepository.BanCommunityMember(ctx, id, data.UserID, data.ExpiresAt, &entry); err != nil {
+		return errors.New("error banning member")
+	}
+
+	s.notifySanction(ctx, community, &entry)
+	return nil
+}
+
+func (s *CommunityServiceImpl) UnbanMember(ctx context.Context, id int, data dto.CommunitySanctionRequest) error {
+	community, err := s.checkSanctionTarget(ctx, id, data)
+	if err != nil {
+		return err
+	}
+
+	entry := model.CommunitySanction{
+		CommunityID: id,
+		UserID:      data.UserID,
+		ModeratorID: data.ModeratorID,
+		Action:      constant.CommunitySanctionUnban,
+		Reason:      data.Reason,
+	}
+	lifted, err := s.CommunityRepository.UnbanCommunityMember(ctx, id, data.UserID, nil, &entry)
+	if err != nil {
+		return errors.New("error unbanning member")
+	}
+	if !lifted {
+		return errors.New("user is not banned from the community")
+	}
+
+	s.notifySanction(ctx, community, &entry)
+	return nil
+}
+
+// MuteMember makes a member read-only, for good or until data.ExpiresAt.
+func (s *CommunityServiceImpl) MuteMember(ctx context.Context, id int, data dto.CommunitySanctionRequest) error {
+	community, err := s.checkSanctionTarget(ctx, id, data)
+	if err != nil {
+		return err
+	}
+
+	entry := model.CommunitySanction{
+		CommunityID: id,
+		UserID:      data.UserID,
+		ModeratorID: data.ModeratorID,
+		Action:      constant.CommunitySanctionMute,
+		Reason:      data.Reason,
+		ExpiresAt:   data.ExpiresAt,
+	}
+	muted, err := s.CommunityRepository.MuteCommunityMember(ctx, id, data.UserID, data.ExpiresAt, &entry)
+	if err != nil {
+		return errors.New("error muting member")
+	}
+	if !muted {
+		return errors.New("user is not a member of the community")
+	}
+
+	s.notifySanction(ctx, community, &entry)
+	return nil
+}
+
+func (s *CommunityServiceImpl) UnmuteMember(ctx context.Context, id int, data dto.CommunitySanctionRequest) error {
+	community, err := s.checkSanctionTarget(ctx, id, data)
+	if err != nil {
+		return err
+	}
+
+	entry := model.CommunitySanction{
+		CommunityID: id,
+		UserID:      data.UserID,
+		ModeratorID: data.ModeratorID,
+		Action:      constant.CommunitySanctionUnmute,
+		Reason:      data.Reason,
+	}
+	lifted, err := s.CommunityRepository.UnmuteCommunityMember(ctx, id, data.UserID, nil, &entry)
+	if err != nil {
+		return errors.New("error unmuting member")
+	}
+	if !lifted {
+		return errors.New("user is not muted in the community")
+	}
+
+	s.notifySanction(ctx, community, &entry)
+	return nil
+}
+
+// checkSanctionTarget makes sure the moderator may sanction data.UserID in the community.
+// Moderators and admins cannot be sanctioned; a moderator has to be removed first.
+func (s *CommunityServiceImpl) checkSanctionTarget(ctx context.Context, id int, data dto.CommunitySanctionRequest) (*model.Community, error) {
+	community, err := s.CommunityRepository.GetCommunityDetailByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("community not found")
+	}
+
+	allowed, err := s.canModerateCommunity(ctx, id, data.ModeratorID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("only moderators can sanction members of this community")
+	}
+
+	if data.UserID == data.ModeratorID {
+		return nil, errors.New("cannot sanction yourself")
+	}
+	if data.ExpiresAt != nil && !data.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	target, err := s.UserRepository.GetUserByID(ctx, data.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if target.Role == constant.UserRoleAdmin {
+		return nil, errors.New("admins cannot be sanctioned")
+	}
+	isModerator, err := s.ModeratorRepository.IsCommunityModerator(ctx, id, data.UserID)
+	if err != nil {
+		return nil, errors.New("error checking moderator")
+	}
+	if isModerator {
+		return nil, errors.New("moderators cannot be sanctioned")
+	}
+
+	return community, nil
+}
+
+// canModerateCommunity reports whether userID is an admin or a moderator of the community.
+func (s *CommunityServiceImpl) canModerateCommunity(ctx context.Context, id, userID int) (bool, error) {
+	return canModerateCommunity(ctx, s.UserRepository, s.ModeratorRepository, id, userID)
+}
+
+// canModerateCommunity reports whether userID is an admin or a moderator of the community.
+func canModerateCommunity(ctx context.Context, userRepo repository.UserRepository, moderatorRepo repository.ModeratorRepository, communityID, userID int) (bool, error) {
+	user, err := userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, errors.New("user not found")
+	}
+	if user.Role == constant.UserRoleAdmin {
+		return true, nil
+	}
+
+	isModerator, err := moderatorRepo.IsCommunityModerator(ctx, communityID, userID)
+	if err != nil {
+		return false, errors.New("error checking moderator")
+	}
+	return isModerator, nil
+}
+
+// notifySanction tells the sanctioned user what happened, including the reason and when a
+// timed sanction ends. A failed notification does not undo the sanction.
+func (s *CommunityServiceImpl) notifySanction(ctx context.Context, community *model.Community, entry *model.CommunitySanction) {
+	var notificationType, message string
+	switch entry.Action {
+	case constant.CommunitySanctionBan:
+		notificationType, message = constant.NotificationTypeCommunityBan, "You have been banned from "+community.Name
+	case constant.CommunitySanctionUnban:
+		notificationType, message = constant.NotificationTypeCommunityUnban, "Your ban from "+community.Name+" has been lifted"
+	case constant.CommunitySanctionMute:
+		notificationType, message = constant.NotificationTypeCommunityMute, "You have been muted in "+community.Name
+	case constant.CommunitySanctionUnmute:
+		notificationType, message = constant.NotificationTypeCommunityUnmute, "Your mute in "+community.Name+" has been lifted"
+	default:
+		return
+	}
+	if entry.ExpiresAt != nil {
+		message += " until " + entry.ExpiresAt.Format(time.RFC3339)
+	}
+	if entry.Reason != "" {
+		message += ": " + entry.Reason
+	}
+
+	notification := model.Notification{
+		UserID:  entry.UserID,
+		ActorID: entry.ModeratorID,
+		Type:    notificationType,
+		Message: message,
+		Read:    false,
+	}
+	if err := s.NotificationRepository.CreateNotification(ctx, &notification); err != nil {
+		log.Printf("Failed to notify user %d of community sanction %d: %v", entry.UserID, entry.ID, err)
+	}
+}
+
+func (s *CommunityServiceImpl) GetCommunitySanctions(ctx context.Context, id, moderatorID, page, limit int) ([]model.CommunitySanction, int64, error) {
+	allowed, err := s.canModerateCommunity(ctx, id, moderatorID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !allowed {
+		return nil, 0, errors.New("only moderators can view the sanctions of this community")
+	}
+
+	sanctions, total, err := s.CommunityRepository.GetCommunitySanctions(ctx, id, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving community sanctions")
+	}
+	return sanctions, total, nil
+}
+
+// ExpireSanctions lifts timed bans and mutes that have run out and notifies their users.
+func (s *CommunityServiceImpl) ExpireSanctions(ctx context.Context) error {
+	lockValue := uuid.NewString()
+
+	acquired, err := s.Redis.AcquireLock(communitySanctionsLockKey, lockValue, communitySanctionsLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire community sanctions lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := s.Redis.ReleaseLock(communitySanctionsLockKey, lockValue); err != nil {
+			log.Printf("Failed to release community sanctions lock: %v", err)
+		}
+	}()
+
+	now := time.Now()
+	members, err := s.CommunityRepository.GetExpiredSanctions(ctx, now, communitySanctionsBatchSize)
+	if err != nil {
+		return err
+	}
+
+	communities := map[int]*model.Community{}
+	for _, member := range members {
+		community, ok := communities[member.CommunityID]
+		if !ok {
+			if community, err = s.CommunityRepository.GetCommunityDetailByID(ctx, member.CommunityID); err != nil {
+				log.Printf("Failed to get community %d: %v", member.CommunityID, err)
+				continue
+			}
+			communities[member.CommunityID] = community
+		}
+
+		if member.Banned && member.BannedUntil != nil && !member.BannedUntil.After(now) {
+			s.expireSanction(ctx, community, member.UserID, constant.CommunitySanctionUnban, now)
+		}
+		if member.Muted && member.MutedUntil != nil && !member.MutedUntil.After(now) {
+			s.expireSanction(ctx, community, member.UserID, constant.CommunitySanctionUnmute, now)
+		}
+	}
+
+	return nil
+}
+
+func (s *CommunityServiceImpl) expireSanction(ctx context.Context, community *model.Community, userID int, action string, now time.Time) {
+	entry := model.CommunitySanction{
+		CommunityID: community.ID,
+		UserID:      userID,
+		Action:      action,
+		Reason:      constant.CommunitySanctionExpiredReason,
+	}
+
+	var lifted bool
+	var err error
+	if action == constant.CommunitySanctionUnban {
+		lifted, err = s.CommunityRepository.UnbanCommunityMember(ctx, community.ID, userID, &now, &entry)
+	} else {
+		lifted, err = s.CommunityRepository.UnmuteCommunityMember(ctx, community.ID, userID, &now, &entry)
+	}
+	if err != nil {
+		log.Printf("Failed to lift %s of user %d in community %d: %v", action, userID, community.ID, err)
+		return
+	}
+	if lifted {
+		s.notifySanction(ctx, community, &entry)
+	}
+}
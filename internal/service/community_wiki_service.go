@@ -0,0 +1,506 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/publisher"
+	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/markdown"
+	"github.com/temuka-api-service/util/text"
+)
+
+type CommunityWikiService interface {
+	CreatePage(ctx context.Context, communityID int, data dto.CreateWikiPageRequest) (*model.CommunityWikiPage, error)
+	GetPages(ctx context.Context, communityID, viewerID int, prefix string) ([]model.CommunityWikiPage, error)
+	GetPageByPath(ctx context.Context, communityID, viewerID int, path string) (*model.CommunityWikiPage, error)
+	GetPage(ctx context.Context, id, viewerID int) (*model.CommunityWikiPage, error)
+	UpdatePage(ctx context.Context, id int, data dto.UpdateWikiPageRequest) (*model.CommunityWikiPage, error)
+	RevertPage(ctx context.Context, id int, data dto.RevertWikiPageRequest) (*model.CommunityWikiPage, error)
+	SetEditPermission(ctx context.Context, id int, data dto.WikiPagePermissionRequest) (*model.CommunityWikiPage, error)
+	DeletePage(ctx context.Context, id, userID int) error
+	GetRevisions(ctx context.Context, id, viewerID, page, limit int) ([]dto.WikiRevisionResponse, int64, error)
+	GetRevision(ctx context.Context, id, revision, viewerID int) (*model.CommunityWikiRevision, error)
+	DiffRevisions(ctx context.Context, id, from, to, viewerID int) (*dto.WikiDiffResponse, error)
+}
+
+type CommunityWikiServiceImpl struct {
+	CommunityWikiRepository repository.CommunityWikiRepository
+	CommunityRepository     repository.CommunityRepository
+	ModeratorRepository     repository.ModeratorRepository
+	UserRepository          repository.UserRepository
+	SearchIndexPublisher    publisher.SearchIndexPublisher
+}
+
+func NewCommunityWikiService(
+	wikiRepo repository.CommunityWikiRepository,
+	communityRepo repository.CommunityRepository,
+	moderatorRepo repository.ModeratorRepository,
+	userRepo repository.UserRepository,
+	searchIndexPublisher publisher.SearchIndexPublisher,
+) CommunityWikiService {
+	return &CommunityWikiServiceImpl{
+		CommunityWikiRepository: wikiRepo,
+		CommunityRepository:     communityRepo,
+		ModeratorRepository:     moderatorRepo,
+		UserRepository:          userRepo,
+		SearchIndexPublisher:    searchIndexPublisher,
+	}
+}
+
+// CreatePage creates a wiki page. A nested page can only be created by someone who may
+// edit its parent.
+func (s *CommunityWikiServiceImpl) CreatePage(ctx context.Context, communityID int, data dto.CreateWikiPageRequest) (*model.CommunityWikiPage, error) {
+	path, err := normalizeWikiPath(data.Path)
+	if err != nil {
+		return nil, err
+	}
+	title, content, summary, err := validateWikiPage(data.Title, data.Content, data.Summary)
+	if err != nil {
+		return nil, err
+	}
+
+	community, err := checkCommunityReadAccess(ctx, s.CommunityRepository, s.UserRepository, s.ModeratorRepository, communityID, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	permission := data.EditPermission
+	if permission == "" {
+		permission = constant.CommunityWikiEditMembers
+	}
+	if permission != constant.CommunityWikiEditMembers && permission != constant.CommunityWikiEditModerators {
+		return nil, errors.New("edit permission must be members or moderators")
+	}
+
+	isModerator, err := s.checkWikiEditor(ctx, communityID, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if permission == constant.CommunityWikiEditModerators && !isModerator {
+		return nil, errors.New("only moderators can create pages only moderators may edit")
+	}
+	if parentPath, nested := parentWikiPath(path); nested && !isModerator {
+		parent, err := s.CommunityWikiRepository.GetPageByPath(ctx, communityID, parentPath)
+		if err != nil {
+			return nil, fmt.Errorf("parent page %s does not exist", parentPath)
+		}
+		if parent.EditPermission != constant.CommunityWikiEditMembers {
+			return nil, errors.New("only moderators can add pages below " + parentPath)
+		}
+	}
+
+	page := model.CommunityWikiPage{
+		CommunityID:    communityID,
+		Path:           path,
+		Title:          title,
+		Content:        content,
+		ContentHTML:    markdown.Render(content),
+		EditPermission: permission,
+		CreatedByID:    data.UserID,
+		LastEditorID:   data.UserID,
+	}
+	revision := model.CommunityWikiRevision{
+		EditorID: data.UserID,
+		Action:   constant.CommunityWikiRevisionCreate,
+		Title:    title,
+		Content:  content,
+		Summary:  summary,
+	}
+
+	if err := s.CommunityWikiRepository.CreatePage(ctx, &page, &revision); err != nil {
+		if errors.Is(err, repository.ErrDuplicateRecord) {
+			return nil, errors.New("a page already exists at this path")
+		}
+		if errors.Is(err, repository.ErrMissingParent) {
+			parentPath, _ := parentWikiPath(path)
+			return nil, fmt.Errorf("parent page %s does not exist", parentPath)
+		}
+		return nil, errors.New("error creating wiki page")
+	}
+
+	s.syncSearch(community, &page, constant.EventOperationCreate)
+	return &page, nil
+}
+
+// GetPages lists the pages of a community without their content, in path order. A prefix
+// limits the list to one page and the pages below it.
+func (s *CommunityWikiServiceImpl) GetPages(ctx context.Context, communityID, viewerID int, prefix string) ([]model.CommunityWikiPage, error) {
+	if _, err := checkCommunityReadAccess(ctx, s.CommunityRepository, s.UserRepository, s.ModeratorRepository, communityID, viewerID); err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		var err error
+		if prefix, err = normalizeWikiPath(prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	pages, err := s.CommunityWikiRepository.GetPages(ctx, communityID, prefix)
+	if err != nil {
+		return nil, errors.New("error retrieving wiki pages")
+	}
+	return pages, nil
+}
+
+func (s *CommunityWikiServiceImpl) GetPageByPath(ctx context.Context, communityID, viewerID int, path string) (*model.CommunityWikiPage, error) {
+	path, err := normalizeWikiPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkCommunityReadAccess(ctx, s.CommunityRepository, s.UserRepository, s.ModeratorRepository, communityID, viewerID); err != nil {
+		return nil, err
+	}
+
+	page, err := s.CommunityWikiRepository.GetPageByPath(ctx, communityID, path)
+	if err != nil {
+		return nil, errors.New("wiki page not found")
+	}
+	return page, nil
+}
+
+func (s *CommunityWikiServiceImpl) GetPage(ctx context.Context, id, viewerID int) (*model.CommunityWikiPage, error) {
+	page, _, err := s.getReadablePage(ctx, id, viewerID)
+	return page, err
+}
+
+// UpdatePage saves a new version of a page as a new revision.
+func (s *CommunityWikiServiceImpl) UpdatePage(ctx context.Context, id int, data dto.UpdateWikiPageRequest) (*model.CommunityWikiPage, error) {
+	title, content, summary, err := validateWikiPage(data.Title, data.Content, data.Summary)
+	if err != nil {
+		return nil, err
+	}
+	if data.BaseRevision <= 0 {
+		return nil, errors.New("base revision is required")
+	}
+
+	page, community, err := s.getEditablePage(ctx, id, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if title == page.Title && content == page.Content {
+		return nil, errors.New("no wiki page changes to save")
+	}
+
+	revision := model.CommunityWikiRevision{
+		EditorID: data.UserID,
+		Action:   constant.CommunityWikiRevisionEdit,
+		Title:    title,
+		Content:  content,
+		Summary:  summary,
+	}
+	return s.savePage(ctx, community, page, &revision, data.BaseRevision)
+}
+
+// RevertPage restores an earlier revision of a page. The restored content becomes a new
+// revision, so the history stays complete.
+func (s *CommunityWikiServiceImpl) RevertPage(ctx context.Context, id int, data dto.RevertWikiPageRequest) (*model.CommunityWikiPage, error) {
+	summary := strings.TrimSpace(data.Summary)
+	if utf8.RuneCountInString(summary) > constant.CommunityWikiEditSummaryMaxLength {
+		return nil, fmt.Errorf("edit summary can be at most %d characters", constant.CommunityWikiEditSummaryMaxLength)
+	}
+
+	page, community, err := s.getEditablePage(ctx, id, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if data.Revision == page.Revision {
+		return nil, errors.New("this is already the current revision")
+	}
+
+	target, err := s.CommunityWikiRepository.GetRevision(ctx, id, data.Revision)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+	if summary == "" {
+		summary = fmt.Sprintf("Reverted to revision %d", target.Revision)
+	}
+
+	revision := model.CommunityWikiRevision{
+		EditorID:     data.UserID,
+		Action:       constant.CommunityWikiRevisionRevert,
+		Title:        target.Title,
+		Content:      target.Content,
+		Summary:      summary,
+		RevertedFrom: &target.Revision,
+	}
+	return s.savePage(ctx, community, page, &revision, page.Revision)
+}
+
+// SetEditPermission decides whether all members or only moderators may edit a page.
+func (s *CommunityWikiServiceImpl) SetEditPermission(ctx context.Context, id int, data dto.WikiPagePermissionRequest) (*model.CommunityWikiPage, error) {
+	if data.EditPermission != constant.CommunityWikiEditMembers && data.EditPermission != constant.CommunityWikiEditModerators {
+		return nil, errors.New("edit permission must be members or moderators")
+	}
+
+	page, err := s.getModeratedPage(ctx, id, data.UserID, "only moderators can change who may edit this page")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.CommunityWikiRepository.SetEditPermission(ctx, id, data.EditPermission); err != nil {
+		return nil, errors.New("error updating wiki page permission")
+	}
+	page.EditPermission = data.EditPermission
+	return page, nil
+}
+
+// DeletePage deletes a page on behalf of a moderator. Pages below it have to be deleted
+// first; the revisions are kept.
+func (s *CommunityWikiServiceImpl) DeletePage(ctx context.Context, id, userID int) error {
+	page, err := s.getModeratedPage(ctx, id, userID, "only moderators can delete wiki pages")
+	if err != nil {
+		return err
+	}
+
+	if err := s.CommunityWikiRepository.DeletePage(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrHasChildren) {
+			return errors.New("delete the pages below this page first")
+		}
+		return errors.New("error deleting wiki page")
+	}
+
+	go s.SearchIndexPublisher.PublishSyncEvent(constant.EventOperationDelete, constant.EventEntityTypeWikiPage, fmt.Sprintf("%d", page.ID), nil)
+	return nil
+}
+
+// GetRevisions lists the revisions of a page, newest first.
+func (s *CommunityWikiServiceImpl) GetRevisions(ctx context.Context, id, viewerID, page, limit int) ([]dto.WikiRevisionResponse, int64, error) {
+	if _, _, err := s.getReadablePage(ctx, id, viewerID); err != nil {
+		return nil, 0, err
+	}
+
+	rows, total, err := s.CommunityWikiRepository.GetRevisions(ctx, id, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving wiki revisions")
+	}
+
+	revisions := make([]dto.WikiRevisionResponse, 0, len(rows))
+	for _, row := range rows {
+		revisions = append(revisions, dto.WikiRevisionResponse{
+			Revision:       row.Revision,
+			Action:         row.Action,
+			Title:          row.Title,
+			Summary:        row.Summary,
+			RevertedFrom:   row.RevertedFrom,
+			EditorID:       row.EditorID,
+			Username:       row.Username,
+			Displayname:    row.Displayname,
+			ProfilePicture: row.ProfilePicture,
+			CreatedAt:      row.CreatedAt,
+		})
+	}
+
+	return revisions, total, nil
+}
+
+func (s *CommunityWikiServiceImpl) GetRevision(ctx context.Context, id, revision, viewerID int) (*model.CommunityWikiRevision, error) {
+	if _, _, err := s.getReadablePage(ctx, id, viewerID); err != nil {
+		return nil, err
+	}
+
+	rev, err := s.CommunityWikiRepository.GetRevision(ctx, id, revision)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+	return rev, nil
+}
+
+// DiffRevisions returns the line diff that turns revision from into revision to. A to of 0
+// means the current revision.
+func (s *CommunityWikiServiceImpl) DiffRevisions(ctx context.Context, id, from, to, viewerID int) (*dto.WikiDiffResponse, error) {
+	page, _, err := s.getReadablePage(ctx, id, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if to == 0 {
+		to = page.Revision
+	}
+
+	fromRevision, err := s.CommunityWikiRepository.GetRevision(ctx, id, from)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d not found", from)
+	}
+	toRevision, err := s.CommunityWikiRepository.GetRevision(ctx, id, to)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d not found", to)
+	}
+
+	diff := &dto.WikiDiffResponse{
+		From:      from,
+		To:        to,
+		FromTitle: fromRevision.Title,
+		ToTitle:   toRevision.Title,
+		Lines:     text.DiffLines(fromRevision.Content, toRevision.Content),
+	}
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case text.DiffInsert:
+			diff.Added++
+		case text.DiffDelete:
+			diff.Removed++
+		}
+	}
+	return diff, nil
+}
+
+func (s *CommunityWikiServiceImpl) savePage(ctx context.Context, community *model.Community, page *model.CommunityWikiPage, revision *model.CommunityWikiRevision, baseRevision int) (*model.CommunityWikiPage, error) {
+	page.Title = revision.Title
+	page.Content = revision.Content
+	page.ContentHTML = markdown.Render(revision.Content)
+	page.LastEditorID = revision.EditorID
+
+	if err := s.CommunityWikiRepository.SavePage(ctx, page, revision, baseRevision); err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return nil, errors.New("this page was changed since you started editing, reload it and try again")
+		}
+		return nil, errors.New("error saving wiki page")
+	}
+
+	s.syncSearch(community, page, constant.EventOperationUpdate)
+	return page, nil
+}
+
+func (s *CommunityWikiServiceImpl) getReadablePage(ctx context.Context, id, viewerID int) (*model.CommunityWikiPage, *model.Community, error) {
+	page, err := s.CommunityWikiRepository.GetPageByID(ctx, id)
+	if err != nil {
+		return nil, nil, errors.New("wiki page not found")
+	}
+	community, err := checkCommunityReadAccess(ctx, s.CommunityRepository, s.UserRepository, s.ModeratorRepository, page.CommunityID, viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return page, community, nil
+}
+
+func (s *CommunityWikiServiceImpl) getEditablePage(ctx context.Context, id, userID int) (*model.CommunityWikiPage, *model.Community, error) {
+	page, community, err := s.getReadablePage(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isModerator, err := s.checkWikiEditor(ctx, page.CommunityID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isModerator && page.EditPermission != constant.CommunityWikiEditMembers {
+		return nil, nil, errors.New("only moderators can edit this page")
+	}
+	return page, community, nil
+}
+
+func (s *CommunityWikiServiceImpl) getModeratedPage(ctx context.Context, id, userID int, deniedMessage string) (*model.CommunityWikiPage, error) {
+	page, err := s.CommunityWikiRepository.GetPageByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("wiki page not found")
+	}
+
+	allowed, err := canModerateCommunity(ctx, s.UserRepository, s.ModeratorRepository, page.CommunityID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New(deniedMessage)
+	}
+	return page, nil
+}
+
+// checkWikiEditor returns an error unless userID may edit the wiki of a community at all:
+// moderators may, and members who are allowed to post. It reports whether userID moderates
+// the community, which lifts per-page restrictions.
+func (s *CommunityWikiServiceImpl) checkWikiEditor(ctx context.Context, communityID, userID int) (bool, error) {
+	if err := checkCommunityParticipation(ctx, s.CommunityRepository, communityID, userID, true); err != nil {
+		return false, err
+	}
+
+	isModerator, err := canModerateCommunity(ctx, s.UserRepository, s.ModeratorRepository, communityID, userID)
+	if err != nil {
+		return false, err
+	}
+	if isModerator {
+		return true, nil
+	}
+
+	member, err := s.CommunityRepository.CheckMembership(ctx, communityID, userID)
+	if err != nil {
+		return false, errors.New("error checking membership")
+	}
+	if member == nil || member.BanOnly {
+		return false, newError(constant.CommunityErrorMembersOnly, "only members can edit the wiki of this community")
+	}
+	return false, nil
+}
+
+// syncSearch indexes a page of a community that is not private. Pages of private
+// communities are kept out of search, as their posts are.
+func (s *CommunityWikiServiceImpl) syncSearch(community *model.Community, page *model.CommunityWikiPage, op string) {
+	if community.Visibility == constant.CommunityVisibilityPrivate {
+		return
+	}
+	go s.SearchIndexPublisher.PublishSyncEvent(op, constant.EventEntityTypeWikiPage, fmt.Sprintf("%d", page.ID), wikiPageSearchPayload(page))
+}
+
+func wikiPageSearchPayload(page *model.CommunityWikiPage) map[string]interface{} {
+	return map[string]interface{}{
+		"community_id": page.CommunityID,
+		"path":         page.Path,
+		"title":        page.Title,
+		"content":      markdown.PlainText(page.Content),
+	}
+}
+
+// normalizeWikiPath turns a user supplied path into its canonical form: slash separated
+// segments slugified, e.g. "Courses/CS 101/" becomes "courses/cs-101".
+func normalizeWikiPath(raw string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(strings.TrimSpace(raw), "/"), "/") {
+		slug := text.Slugify(segment)
+		if slug == "" {
+			return "", errors.New("wiki path segments must contain letters or digits")
+		}
+		segments = append(segments, slug)
+	}
+	if len(segments) > constant.CommunityWikiMaxPathDepth {
+		return "", fmt.Errorf("wiki pages can be nested at most %d levels deep", constant.CommunityWikiMaxPathDepth)
+	}
+
+	path := strings.Join(segments, "/")
+	if utf8.RuneCountInString(path) > constant.CommunityWikiMaxPathLength {
+		return "", fmt.Errorf("wiki path can be at most %d characters", constant.CommunityWikiMaxPathLength)
+	}
+	return path, nil
+}
+
+// parentWikiPath returns the path of the parent of a page, or false for a top-level page.
+func parentWikiPath(path string) (string, bool) {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return "", false
+	}
+	return path[:i], true
+}
+
+func validateWikiPage(title, content, summary string) (string, string, string, error) {
+	title = strings.TrimSpace(title)
+	summary = strings.TrimSpace(summary)
+
+	if title == "" {
+		return "", "", "", errors.New("wiki page title is required")
+	}
+	if utf8.RuneCountInString(title) > constant.CommunityWikiTitleMaxLength {
+		return "", "", "", fmt.Errorf("wiki page title can be at most %d characters", constant.CommunityWikiTitleMaxLength)
+	}
+	if utf8.RuneCountInString(content) > constant.CommunityWikiContentMaxLength {
+		return "", "", "", fmt.Errorf("wiki page content can be at most %d characters", constant.CommunityWikiContentMaxLength)
+	}
+	if utf8.RuneCountInString(summary) > constant.CommunityWikiEditSummaryMaxLength {
+		return "", "", "", fmt.Errorf("edit summary can be at most %d characters", constant.CommunityWikiEditSummaryMaxLength)
+	}
+	return title, content, summary, nil
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log"
 
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/dto"
 	"github.com/temuka-api-service/internal/model"
 	"github.com/temuka-api-service/internal/repository"
@@ -21,12 +24,14 @@ type ConversationService interface {
 type ConversationServiceImpl struct {
 	ConversationRepository repository.ConversationRepository
 	UserRepository         repository.UserRepository
+	MentionService         MentionService
 }
 
-func NewConversationService(conversationRepo repository.ConversationRepository, userRepo repository.UserRepository) ConversationService {
+func NewConversationService(conversationRepo repository.ConversationRepository, userRepo repository.UserRepository, mentionService MentionService) ConversationService {
 	return &ConversationServiceImpl{
 		ConversationRepository: conversationRepo,
 		UserRepository:         userRepo,
+		MentionService:         mentionService,
 	}
 }
 
@@ -42,6 +47,11 @@ func (s *ConversationServiceImpl) AddConversation(ctx context.Context, req dto.A
 }
 
 func (s *ConversationServiceImpl) AddMessage(ctx context.Context, req dto.AddMessageRequest) (*model.Message, error) {
+	sender, err := s.ConversationRepository.GetParticipantByID(ctx, req.ParticipantID)
+	if err != nil {
+		return nil, errors.New("participant not found")
+	}
+
 	message := model.Message{
 		ParticipantID: req.ParticipantID,
 		Text:          req.Text,
@@ -49,9 +59,37 @@ func (s *ConversationServiceImpl) AddMessage(ctx context.Context, req dto.AddMes
 	if err := s.ConversationRepository.AddMessage(ctx, &message); err != nil {
 		return nil, err
 	}
+
+	s.processMessageMentions(ctx, sender, &message)
+
 	return &message, nil
 }
 
+// processMessageMentions only notifies users taking part in the conversation,
+// so a mention never leaks a private message to an outsider.
+func (s *ConversationServiceImpl) processMessageMentions(ctx context.Context, sender *model.Participant, message *model.Message) {
+	participants, err := s.ConversationRepository.GetParticipantsByConversationID(ctx, sender.ConversationID)
+	if err != nil {
+		log.Printf("Failed to load participants for message %d: %v", message.ID, err)
+		return
+	}
+
+	audience := make([]int, 0, len(participants))
+	for _, p := range participants {
+		audience = append(audience, p.UserID)
+	}
+
+	source := MentionSource{
+		Type:     constant.MentionSourceMessage,
+		ID:       message.ID,
+		ActorID:  sender.UserID,
+		Audience: audience,
+	}
+	if err := s.MentionService.ProcessMentions(ctx, source, message.Text); err != nil {
+		log.Printf("Failed to process mentions for message %d: %v", message.ID, err)
+	}
+}
+
 func (s *ConversationServiceImpl) AddParticipant(ctx context.Context, req dto.AddParticipantRequest) error {
 	participant := model.Participant{
 		UserID:         req.UserID,
@@ -0,0 +1,16 @@
+package service
+
+// Error is a service error carrying a stable code that clients can branch on, next to the
+// human readable message.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
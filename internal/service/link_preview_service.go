@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/key_value_store"
+	"github.com/temuka-api-service/util/link_preview"
+	"github.com/temuka-api-service/util/text"
+)
+
+const (
+	linkPreviewsLockKey   = "lock_link_preview_fetcher"
+	linkPreviewsBatchSize = 20
+	// linkPreviewsLockTTL outlasts a batch in which every fetch runs into the fetcher's
+	// timeout, so another instance cannot take the lock while a slow batch is still running.
+	linkPreviewsLockTTL = linkPreviewsBatchSize*link_preview.DefaultTimeout + 30*time.Second
+)
+
+type LinkPreviewService interface {
+	QueuePostPreview(ctx context.Context, post *model.Post) error
+	RemovePostPreview(ctx context.Context, postID int) error
+	ProcessPendingPreviews(ctx context.Context) error
+}
+
+type LinkPreviewServiceImpl struct {
+	LinkPreviewRepository repository.LinkPreviewRepository
+	Fetcher               *link_preview.Fetcher
+	Redis                 key_value_store.RedisWrapper
+}
+
+func NewLinkPreviewService(linkPreviewRepo repository.LinkPreviewRepository, fetcher *link_preview.Fetcher, redis key_value_store.RedisWrapper) LinkPreviewService {
+	return &LinkPreviewServiceImpl{
+		LinkPreviewRepository: linkPreviewRepo,
+		Fetcher:               fetcher,
+		Redis:                 redis,
+	}
+}
+
+// QueuePostPreview schedules a preview for the first URL in the post description.
+// The fetch itself happens in ProcessPendingPreviews so publishing never waits on a remote site.
+func (s *LinkPreviewServiceImpl) QueuePostPreview(ctx context.Context, post *model.Post) error {
+	urls := text.ExtractURLs(post.Description)
+
+	existing, err := s.LinkPreviewRepository.GetLinkPreviewByPostID(ctx, post.ID)
+	if err != nil {
+		return errors.New("error retrieving link preview")
+	}
+
+	if len(urls) == 0 {
+		if existing != nil {
+			return s.RemovePostPreview(ctx, post.ID)
+		}
+		return nil
+	}
+
+	if existing != nil && existing.URL == urls[0] {
+		return nil
+	}
+
+	if err := s.LinkPreviewRepository.QueueLinkPreview(ctx, post.ID, urls[0]); err != nil {
+		return errors.New("error queueing link preview")
+	}
+
+	return nil
+}
+
+func (s *LinkPreviewServiceImpl) RemovePostPreview(ctx context.Context, postID int) error {
+	if err := s.LinkPreviewRepository.DeleteLinkPreviewByPostID(ctx, postID); err != nil {
+		return errors.New("error deleting link preview")
+	}
+	return nil
+}
+
+// ProcessPendingPreviews fetches a batch of pending previews. A Redis lock keeps
+// several instances from fetching the same batch, and fetched metadata is cached
+// per URL so a link shared in many posts is only downloaded once.
+func (s *LinkPreviewServiceImpl) ProcessPendingPreviews(ctx context.Context) error {
+	lockValue := uuid.NewString()
+
+	acquired, err := s.Redis.AcquireLock(linkPreviewsLockKey, lockValue, linkPreviewsLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire link preview lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := s.Redis.ReleaseLock(linkPreviewsLockKey, lockValue); err != nil {
+			log.Printf("Failed to release link preview lock: %v", err)
+		}
+	}()
+
+	previews, err := s.LinkPreviewRepository.GetPendingLinkPreviews(ctx, linkPreviewsBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range previews {
+		s.processPreview(ctx, &previews[i])
+	}
+
+	return nil
+}
+
+func (s *LinkPreviewServiceImpl) processPreview(ctx context.Context, preview *model.LinkPreview) {
+	meta, err := s.fetchMetadata(ctx, preview.URL)
+	if err != nil {
+		preview.Attempts++
+		if preview.Attempts >= constant.LinkPreviewMaxAttempts || errors.Is(err, link_preview.ErrBlockedAddress) {
+			preview.Status = constant.LinkPreviewStatusFailed
+		}
+		log.Printf("Failed to fetch link preview for post %d: %v", preview.PostID, err)
+	} else {
+		now := time.Now()
+		preview.Title = meta.Title
+		preview.Description = meta.Description
+		preview.ImageURL = meta.ImageURL
+		preview.SiteName = meta.SiteName
+		preview.FetchedAt = &now
+		preview.Status = constant.LinkPreviewStatusReady
+		if meta.Empty() {
+			preview.Status = constant.LinkPreviewStatusFailed
+		}
+	}
+
+	if err := s.LinkPreviewRepository.UpdateLinkPreview(ctx, preview); err != nil {
+		log.Printf("Failed to save link preview for post %d: %v", preview.PostID, err)
+	}
+}
+
+func (s *LinkPreviewServiceImpl) fetchMetadata(ctx context.Context, url string) (*link_preview.Metadata, error) {
+	cacheKey := linkPreviewCacheKey(url)
+
+	var cached link_preview.Metadata
+	if err := s.Redis.Get(cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	meta, err := s.Fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Redis.Set(cacheKey, meta, constant.LinkPreviewCacheTTL); err != nil {
+		log.Printf("Failed to cache link preview for %s: %v", url, err)
+	}
+
+	return meta, nil
+}
+
+func linkPreviewCacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "link_preview_url_" + hex.EncodeToString(sum[:])
+}
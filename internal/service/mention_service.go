@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/text"
+)
+
+const (
+	defaultMentionAutocompleteLimit = 10
+	maxMentionAutocompleteLimit     = 25
+)
+
+// MentionSource describes the content a set of mentions was found in.
+// Audience, when non-nil, restricts notifications to the listed user IDs
+// (e.g. the participants of a conversation). CanSee, when set, is asked for
+// every other mentioned user, e.g. whether they may read a restricted post.
+type MentionSource struct {
+	Type      string
+	ID        int
+	ActorID   int
+	PostID    int
+	CommentID int
+	Audience  []int
+	CanSee    func(ctx context.Context, userID int) (bool, error)
+}
+
+type MentionService interface {
+	ProcessMentions(ctx context.Context, source MentionSource, texts ...string) error
+	AutocompleteMentions(ctx context.Context, viewerID int, query string, limit int) ([]repository.MentionCandidate, error)
+}
+
+type MentionServiceImpl struct {
+	MentionRepository      repository.MentionRepository
+	UserRepository         repository.UserRepository
+	NotificationRepository repository.NotificationRepository
+}
+
+func NewMentionService(mentionRepo repository.MentionRepository, userRepo repository.UserRepository, notificationRepo repository.NotificationRepository) MentionService {
+	return &MentionServiceImpl{
+		MentionRepository:      mentionRepo,
+		UserRepository:         userRepo,
+		NotificationRepository: notificationRepo,
+	}
+}
+
+// ProcessMentions resolves @username mentions in texts, records them and
+// notifies each mentioned user once per source. Mentions of the author,
+// of users in a block relationship with the author and of users outside
+// the source audience are ignored.
+func (s *MentionServiceImpl) ProcessMentions(ctx context.Context, source MentionSource, texts ...string) error {
+	usernames := text.ExtractMentions(texts...)
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	users, err := s.UserRepository.GetUsersByUsernames(ctx, usernames)
+	if err != nil {
+		return errors.New("error resolving mentions")
+	}
+
+	var audience map[int]bool
+	if source.Audience != nil {
+		audience = make(map[int]bool, len(source.Audience))
+		for _, id := range source.Audience {
+			audience[id] = true
+		}
+	}
+
+	for _, user := range users {
+		if user.ID == source.ActorID {
+			continue
+		}
+		if audience != nil && !audience[user.ID] {
+			continue
+		}
+
+		blocked, err := s.UserRepository.IsBlocked(ctx, source.ActorID, user.ID)
+		if err != nil {
+			return errors.New("error checking user block")
+		}
+		if blocked {
+			continue
+		}
+
+		if source.CanSee != nil {
+			visible, err := source.CanSee(ctx, user.ID)
+			if err != nil {
+				return errors.New("error checking mention audience")
+			}
+			if !visible {
+				continue
+			}
+		}
+
+		created, err := s.MentionRepository.CreateMention(ctx, &model.Mention{
+			SourceType:      source.Type,
+			SourceID:        source.ID,
+			MentionedUserID: user.ID,
+			ActorID:         source.ActorID,
+		})
+		if err != nil {
+			return errors.New("error creating mention")
+		}
+		if !created {
+			continue
+		}
+
+		notification := model.Notification{
+			UserID:    user.ID,
+			ActorID:   source.ActorID,
+			PostID:    source.PostID,
+			CommentID: source.CommentID,
+			Type:      constant.NotificationTypeMention,
+			Message:   mentionNotificationMessage(source.Type),
+			Read:      false,
+		}
+		if err := s.NotificationRepository.CreateNotification(ctx, &notification); err != nil {
+			log.Printf("failed to create mention notification for user %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *MentionServiceImpl) AutocompleteMentions(ctx context.Context, viewerID int, query string, limit int) ([]repository.MentionCandidate, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if query == "" {
+		return []repository.MentionCandidate{}, nil
+	}
+
+	if limit <= 0 {
+		limit = defaultMentionAutocompleteLimit
+	}
+	if limit > maxMentionAutocompleteLimit {
+		limit = maxMentionAutocompleteLimit
+	}
+
+	candidates, err := s.MentionRepository.GetMentionCandidates(ctx, viewerID, query, limit)
+	if err != nil {
+		return nil, errors.New("error retrieving mention candidates")
+	}
+
+	return candidates, nil
+}
+
+func mentionNotificationMessage(sourceType string) string {
+	switch sourceType {
+	case constant.MentionSourceComment:
+		return "You were mentioned in a comment"
+	case constant.MentionSourceMessage:
+		return "You were mentioned in a message"
+	default:
+		return "You were mentioned in a post"
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/dto"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+)
+
+type PollService interface {
+	GetPostPoll(ctx context.Context, postID, viewerID int) (*dto.PollResponse, error)
+	Vote(ctx context.Context, pollID int, req dto.VotePollRequest) (*dto.PollResponse, error)
+	GetPollVoters(ctx context.Context, pollID, viewerID int) ([]dto.PollVotersResponse, error)
+}
+
+type PollServiceImpl struct {
+	PollRepository repository.PollRepository
+	PostRepository repository.PostRepository
+}
+
+func NewPollService(pollRepo repository.PollRepository, postRepo repository.PostRepository) PollService {
+	return &PollServiceImpl{
+		PollRepository: pollRepo,
+		PostRepository: postRepo,
+	}
+}
+
+// newPollFromRequest validates a poll attachment and builds the model to be stored with its post.
+func newPollFromRequest(req *dto.CreatePollRequest) (*model.Poll, error) {
+	if len(req.Options) < constant.PollMinOptions || len(req.Options) > constant.PollMaxOptions {
+		return nil, fmt.Errorf("poll must have between %d and %d options", constant.PollMinOptions, constant.PollMaxOptions)
+	}
+
+	visibility := req.ResultsVisibility
+	switch visibility {
+	case "":
+		visibility = constant.PollResultsVisibilityAlways
+	case constant.PollResultsVisibilityAlways, constant.PollResultsVisibilityAfterVote, constant.PollResultsVisibilityAfterClose:
+	default:
+		return nil, errors.New("invalid poll results visibility")
+	}
+
+	if visibility == constant.PollResultsVisibilityAfterClose && req.ClosesAt == nil {
+		return nil, errors.New("closes_at is required when results are hidden until close")
+	}
+	if req.ClosesAt != nil && !req.ClosesAt.After(time.Now()) {
+		return nil, errors.New("closes_at must be in the future")
+	}
+
+	poll := model.Poll{
+		MultipleChoice:    req.MultipleChoice,
+		Anonymous:         req.Anonymous,
+		ResultsVisibility: visibility,
+		ClosesAt:          req.ClosesAt,
+	}
+
+	for i, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, errors.New("poll options cannot be empty")
+		}
+		poll.Options = append(poll.Options, model.PollOption{Text: text, Position: i})
+	}
+
+	return &poll, nil
+}
+
+func (s *PollServiceImpl) GetPostPoll(ctx context.Context, postID, viewerID int) (*dto.PollResponse, error) {
+	poll, err := s.PollRepository.GetPollByPostID(ctx, postID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	if err := s.checkPollVisible(ctx, poll, viewerID); err != nil {
+		return nil, err
+	}
+	return s.buildPollResponse(ctx, poll, viewerID)
+}
+
+func (s *PollServiceImpl) Vote(ctx context.Context, pollID int, req dto.VotePollRequest) (*dto.PollResponse, error) {
+	poll, err := s.PollRepository.GetPollByID(ctx, pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	if err := s.checkPollOpen(ctx, poll, req.UserID); err != nil {
+		return nil, err
+	}
+	if poll.IsClosed(time.Now()) {
+		return nil, errors.New("poll is closed")
+	}
+
+	if len(req.OptionIDs) == 0 {
+		return nil, errors.New("at least one option must be selected")
+	}
+	if !poll.MultipleChoice && len(req.OptionIDs) > 1 {
+		return nil, errors.New("poll only allows a single choice")
+	}
+
+	validOptions := make(map[int]bool, len(poll.Options))
+	for _, o := range poll.Options {
+		validOptions[o.ID] = true
+	}
+	seen := make(map[int]bool, len(req.OptionIDs))
+	for _, id := range req.OptionIDs {
+		if !validOptions[id] {
+			return nil, errors.New("invalid poll option")
+		}
+		if seen[id] {
+			return nil, errors.New("duplicate poll option")
+		}
+		seen[id] = true
+	}
+
+	ballot := model.PollBallot{
+		PollID: poll.ID,
+		UserID: req.UserID,
+	}
+
+	if err := s.PollRepository.CastBallot(ctx, &ballot, req.OptionIDs); err != nil {
+		if errors.Is(err, repository.ErrDuplicateRecord) {
+			return nil, errors.New("user has already voted in this poll")
+		}
+		return nil, errors.New("error casting vote")
+	}
+
+	poll, err = s.PollRepository.GetPollByID(ctx, pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	return s.buildPollResponse(ctx, poll, req.UserID)
+}
+
+func (s *PollServiceImpl) GetPollVoters(ctx context.Context, pollID, viewerID int) ([]dto.PollVotersResponse, error) {
+	poll, err := s.PollRepository.GetPollByID(ctx, pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	if err := s.checkPollVisible(ctx, poll, viewerID); err != nil {
+		return nil, err
+	}
+	if poll.Anonymous {
+		return nil, errors.New("voters of an anonymous poll are not visible")
+	}
+
+	resp, err := s.buildPollResponse(ctx, poll, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ResultsHidden {
+		return nil, errors.New("poll results are not visible yet")
+	}
+
+	ballots, err := s.PollRepository.GetBallotsByPollID(ctx, pollID)
+	if err != nil {
+		return nil, errors.New("error retrieving poll voters")
+	}
+
+	votersByOption := make(map[int][]int)
+	for _, b := range ballots {
+		for _, v := range b.Votes {
+			votersByOption[v.OptionID] = append(votersByOption[v.OptionID], b.UserID)
+		}
+	}
+
+	voters := make([]dto.PollVotersResponse, 0, len(poll.Options))
+	for _, o := range poll.Options {
+		voters = append(voters, dto.PollVotersResponse{
+			OptionID: o.ID,
+			UserIDs:  votersByOption[o.ID],
+		})
+	}
+
+	return voters, nil
+}
+
+// checkPollVisible hides polls attached to posts the viewer is not allowed to read.
+func (s *PollServiceImpl) checkPollVisible(ctx context.Context, poll *model.Poll, viewerID int) error {
+	visible, err := s.PostRepository.IsPostVisibleTo(ctx, poll.PostID, viewerID)
+	if err != nil {
+		return errors.New("error checking post visibility")
+	}
+	if !visible {
+		return errors.New("poll not found")
+	}
+	return nil
+}
+
+// checkPollOpen also refuses votes on polls of drafts and scheduled posts.
+func (s *PollServiceImpl) checkPollOpen(ctx context.Context, poll *model.Poll, userID int) error {
+	open, err := s.PostRepository.CanInteractWithPost(ctx, poll.PostID, userID)
+	if err != nil {
+		return errors.New("error checking post visibility")
+	}
+	if !open {
+		return errors.New("poll not found")
+	}
+	return nil
+}
+
+// buildPollResponse shapes a poll for viewerID, stripping tallies when the author chose to hide
+// results until the viewer has voted or the poll has closed. The post author always sees them.
+func (s *PollServiceImpl) buildPollResponse(ctx context.Context, poll *model.Poll, viewerID int) (*dto.PollResponse, error) {
+	ballot, err := s.PollRepository.GetBallot(ctx, poll.ID, viewerID)
+	if err != nil {
+		return nil, errors.New("error retrieving poll ballot")
+	}
+
+	closed := poll.IsClosed(time.Now())
+
+	myOptionIDs := []int{}
+	if ballot != nil {
+		for _, v := range ballot.Votes {
+			myOptionIDs = append(myOptionIDs, v.OptionID)
+		}
+	}
+
+	isAuthor := false
+	if post, err := s.PostRepository.GetPostDetailByID(ctx, poll.PostID); err == nil {
+		isAuthor = post.UserID == viewerID
+	}
+
+	hidden := false
+	switch poll.ResultsVisibility {
+	case constant.PollResultsVisibilityAfterVote:
+		hidden = ballot == nil && !closed
+	case constant.PollResultsVisibilityAfterClose:
+		hidden = !closed
+	}
+	if isAuthor {
+		hidden = false
+	}
+
+	resp := dto.PollResponse{
+		ID:                poll.ID,
+		PostID:            poll.PostID,
+		MultipleChoice:    poll.MultipleChoice,
+		Anonymous:         poll.Anonymous,
+		ResultsVisibility: poll.ResultsVisibility,
+		ClosesAt:          poll.ClosesAt,
+		Closed:            closed,
+		ResultsHidden:     hidden,
+		MyOptionIDs:       myOptionIDs,
+	}
+	if !hidden {
+		votersCount := poll.VotersCount
+		resp.VotersCount = &votersCount
+	}
+
+	for _, o := range poll.Options {
+		option := dto.PollOptionResponse{
+			ID:       o.ID,
+			Text:     o.Text,
+			Position: o.Position,
+		}
+		if !hidden {
+			votesCount := o.VotesCount
+			option.VotesCount = &votesCount
+		}
+		resp.Options = append(resp.Options, option)
+	}
+
+	return &resp, nil
+}
@@ -4,33 +4,72 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/dto"
 	"github.com/temuka-api-service/internal/model"
 	"github.com/temuka-api-service/internal/publisher"
 	"github.com/temuka-api-service/internal/repository"
 	"github.com/temuka-api-service/util/key_value_store"
+	"github.com/temuka-api-service/util/markdown"
+	"github.com/temuka-api-service/util/text"
 	"gorm.io/gorm"
 )
 
 type PostService interface {
 	CreatePost(ctx context.Context, req *dto.CreatePostRequest) (*model.Post, error)
-	GetPostDetail(ctx context.Context, postID int) (*model.Post, error)
-	GetUserPosts(ctx context.Context, userID int) ([]model.Post, error)
+	GetPostDetail(ctx context.Context, postID, viewerID int) (*model.Post, error)
+	GetUserPosts(ctx context.Context, userID, viewerID int) ([]model.Post, error)
 	UpdatePost(ctx context.Context, postID int, req *dto.UpdatePostRequest) (*model.Post, error)
 	DeletePost(ctx context.Context, postID int) error
 	GetTimelinePosts(ctx context.Context, userID int) ([]model.Post, error)
-	LikePost(ctx context.Context, postID, userID int) error
+	LikePost(ctx context.Context, postID, userID int) (bool, error)
+	GetUnpublishedPosts(ctx context.Context, userID int) ([]model.Post, error)
+	PublishPost(ctx context.Context, postID, userID int) (*model.Post, error)
+	PublishDuePosts(ctx context.Context) error
+	RepostPost(ctx context.Context, postID int, req *dto.RepostRequest) ([]model.Post, error)
+	LockPost(ctx context.Context, postID int, req *dto.LockPostRequest) error
+	UnlockPost(ctx context.Context, postID int, req *dto.LockPostRequest) error
+	UpdateCommentSettings(ctx context.Context, postID int, req *dto.CommentSettingsRequest) (*model.Post, error)
+	SetPostFlair(ctx context.Context, postID int, req *dto.SetPostFlairRequest) (*model.Post, error)
+	PinPost(ctx context.Context, postID int, req *dto.PinPostRequest) error
+	UnpinPost(ctx context.Context, postID int, req *dto.PinPostRequest) error
+	ReorderPinnedPosts(ctx context.Context, communityID int, req *dto.ReorderPinnedPostsRequest) error
+	CreateAnnouncement(ctx context.Context, communityID int, req *dto.CreateAnnouncementRequest) (*model.Post, error)
+	DeliverAnnouncements(ctx context.Context) error
+	GetPostModerationLogs(ctx context.Context, postID, userID int) ([]model.PostModerationLog, error)
 }
 
+const (
+	scheduledPostsLockKey   = "lock_scheduled_posts_publisher"
+	scheduledPostsLockTTL   = 50 * time.Second
+	scheduledPostsBatchSize = 100
+
+	announcementsLockKey = "lock_community_announcements_deliverer"
+	announcementsLockTTL = 50 * time.Second
+	// Each run delivers at most announcementsPerRun announcements, each for at most
+	// announcementBatchesPerRun batches, and picks up the rest on the next run.
+	announcementsPerRun       = 10
+	announcementBatchesPerRun = 20
+	announcementBatchSize     = 500
+)
+
 type PostServiceImpl struct {
 	postRepo             repository.PostRepository
 	userRepo             repository.UserRepository
 	commentRepo          repository.CommentRepository
 	notificationRepo     repository.NotificationRepository
 	communityRepo        repository.CommunityRepository
+	tagRepo              repository.TagRepository
+	bookmarkRepo         repository.BookmarkRepository
+	moderatorRepo        repository.ModeratorRepository
+	flairRepo            repository.CommunityFlairRepository
+	announcementRepo     repository.CommunityAnnouncementRepository
+	mentionService       MentionService
+	linkPreviewService   LinkPreviewService
 	redis                key_value_store.RedisWrapper
 	searchIndexPublisher publisher.SearchIndexPublisher
 }
@@ -41,6 +80,13 @@ func NewPostService(
 	commentRepo repository.CommentRepository,
 	notificationRepo repository.NotificationRepository,
 	communityRepo repository.CommunityRepository,
+	tagRepo repository.TagRepository,
+	bookmarkRepo repository.BookmarkRepository,
+	moderatorRepo repository.ModeratorRepository,
+	flairRepo repository.CommunityFlairRepository,
+	announcementRepo repository.CommunityAnnouncementRepository,
+	mentionService MentionService,
+	linkPreviewService LinkPreviewService,
 	redis key_value_store.RedisWrapper,
 	searchIndexPublisher publisher.SearchIndexPublisher,
 ) PostService {
@@ -50,59 +96,540 @@ func NewPostService(
 		commentRepo:          commentRepo,
 		notificationRepo:     notificationRepo,
 		communityRepo:        communityRepo,
+		tagRepo:              tagRepo,
+		bookmarkRepo:         bookmarkRepo,
+		moderatorRepo:        moderatorRepo,
+		flairRepo:            flairRepo,
+		announcementRepo:     announcementRepo,
+		mentionService:       mentionService,
+		linkPreviewService:   linkPreviewService,
 		redis:                redis,
 		searchIndexPublisher: searchIndexPublisher,
 	}
 }
 
 func (s *PostServiceImpl) CreatePost(ctx context.Context, req *dto.CreatePostRequest) (*model.Post, error) {
+	return s.createPost(ctx, req, constant.PostTypeRegular)
+}
+
+func (s *PostServiceImpl) createPost(ctx context.Context, req *dto.CreatePostRequest, postType string) (*model.Post, error) {
+	status, err := resolvePostStatus(req.Status, req.ScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	visibility, err := resolvePostVisibility(req.Visibility, req.CommunityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CommunityID != 0 {
+		if err := checkCommunityParticipation(ctx, s.communityRepo, req.CommunityID, req.UserID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkPostFlair(ctx, s.communityRepo, s.flairRepo, req.CommunityID, req.FlairID); err != nil {
+		return nil, err
+	}
+
+	var poll *model.Poll
+	if req.Poll != nil {
+		if poll, err = newPollFromRequest(req.Poll); err != nil {
+			return nil, err
+		}
+	}
+
 	newPost := model.Post{
-		Title:       req.Title,
-		Description: req.Description,
-		UserID:      req.UserID,
+		Title:           req.Title,
+		Description:     req.Description,
+		DescriptionHTML: markdown.Render(req.Description),
+		UserID:          req.UserID,
+		CommunityID:     req.CommunityID,
+		FlairID:         req.FlairID,
+		Type:            postType,
+		Status:          status,
+		Visibility:      visibility,
+		ScheduledAt:     req.ScheduledAt,
+	}
+	if status == constant.PostStatusPublished {
+		now := time.Now()
+		newPost.ScheduledAt = nil
+		newPost.PublishedAt = &now
 	}
 
-	if err := s.postRepo.CreatePost(ctx, &newPost); err != nil {
+	if poll != nil {
+		if err := s.postRepo.CreatePostWithPoll(ctx, &newPost, poll); err != nil {
+			return nil, errors.New("error creating post")
+		}
+	} else if err := s.postRepo.CreatePost(ctx, &newPost); err != nil {
 		return nil, errors.New("error creating post")
 	}
 
-	if err := s.communityRepo.UpdateCommunityPostsCount(ctx, req.CommunityID); err != nil {
-		return nil, errors.New("error updating community posts count")
+	if err := s.tagRepo.SyncPostTags(ctx, newPost.ID, text.ExtractHashtags(newPost.Title, newPost.Description)); err != nil {
+		return nil, errors.New("error saving post tags")
 	}
 
-	go s.searchIndexPublisher.PublishSyncEvent(constant.EventOperationCreate, constant.EventEntityTypePost, fmt.Sprintf("%d", newPost.ID), map[string]interface{}{
-		"title":       newPost.Title,
-		"description": newPost.Description,
-		"user_id":     newPost.UserID,
-	})
+	if status == constant.PostStatusPublished {
+		if err := s.onPostPublished(ctx, &newPost); err != nil {
+			return nil, err
+		}
+	}
 
 	return &newPost, nil
 }
 
-func (s *PostServiceImpl) GetPostDetail(ctx context.Context, postID int) (*model.Post, error) {
-	return s.postRepo.GetPostDetailByID(ctx, postID)
+func resolvePostStatus(status string, scheduledAt *time.Time) (string, error) {
+	switch status {
+	case "", constant.PostStatusPublished:
+		return constant.PostStatusPublished, nil
+	case constant.PostStatusDraft:
+		return constant.PostStatusDraft, nil
+	case constant.PostStatusScheduled:
+		if scheduledAt == nil {
+			return "", errors.New("scheduled_at is required for scheduled posts")
+		}
+		if !scheduledAt.After(time.Now()) {
+			return "", errors.New("scheduled_at must be in the future")
+		}
+		return constant.PostStatusScheduled, nil
+	default:
+		return "", errors.New("invalid post status")
+	}
+}
+
+func resolvePostVisibility(visibility string, communityID int) (string, error) {
+	switch visibility {
+	case "", constant.PostVisibilityPublic:
+		return constant.PostVisibilityPublic, nil
+	case constant.PostVisibilityFollowers, constant.PostVisibilityUniversityVerified:
+		return visibility, nil
+	case constant.PostVisibilityCommunity:
+		if communityID == 0 {
+			return "", errors.New("community visibility requires a community post")
+		}
+		return visibility, nil
+	default:
+		return "", errors.New("invalid post visibility")
+	}
+}
+
+// onPostPublished runs the side effects every post goes through once it becomes visible,
+// whether it was published directly, from a draft or by the scheduler.
+func (s *PostServiceImpl) onPostPublished(ctx context.Context, post *model.Post) error {
+	if post.CommunityID != 0 {
+		if err := s.communityRepo.UpdateCommunityPostsCount(ctx, post.CommunityID, 1); err != nil {
+			return errors.New("error updating community posts count")
+		}
+	}
+
+	s.syncPostSearch(ctx, post, "")
+
+	s.invalidateTimelines(ctx, post.UserID)
+
+	s.processPostMentions(ctx, post)
+
+	if err := s.linkPreviewService.QueuePostPreview(ctx, post); err != nil {
+		log.Printf("Failed to queue link preview for post %d: %v", post.ID, err)
+	}
+
+	if post.Type == constant.PostTypeAnnouncement {
+		announcement := model.CommunityAnnouncement{
+			PostID:      post.ID,
+			CommunityID: post.CommunityID,
+			AuthorID:    post.UserID,
+			Status:      constant.CommunityAnnouncementStatusPending,
+		}
+		if err := s.announcementRepo.CreateAnnouncement(ctx, &announcement); err != nil {
+			return errors.New("error queueing announcement")
+		}
+	}
+
+	return nil
+}
+
+func (s *PostServiceImpl) processPostMentions(ctx context.Context, post *model.Post) {
+	source := MentionSource{
+		Type:    constant.MentionSourcePost,
+		ID:      post.ID,
+		ActorID: post.UserID,
+		PostID:  post.ID,
+	}
+	if post.Visibility != constant.PostVisibilityPublic {
+		source.CanSee = func(ctx context.Context, userID int) (bool, error) {
+			return s.postRepo.IsPostVisibleTo(ctx, post.ID, userID)
+		}
+	}
+	if err := s.mentionService.ProcessMentions(ctx, source, post.Title, post.Description); err != nil {
+		log.Printf("Failed to process mentions for post %d: %v", post.ID, err)
+	}
+}
+
+// syncPostSearch keeps the search index limited to public posts outside private communities.
+// previousVisibility is the visibility the index last saw, or "" for a post that was never
+// indexed.
+func (s *PostServiceImpl) syncPostSearch(ctx context.Context, post *model.Post, previousVisibility string) {
+	id := fmt.Sprintf("%d", post.ID)
+	indexed := previousVisibility == constant.PostVisibilityPublic
+	public := post.Visibility == constant.PostVisibilityPublic && !s.inPrivateCommunity(ctx, post)
+
+	switch {
+	case public && indexed:
+		go s.searchIndexPublisher.PublishSyncEvent(constant.EventOperationUpdate, constant.EventEntityTypePost, id, postSearchPayload(post))
+	case public:
+		go s.searchIndexPublisher.PublishSyncEvent(constant.EventOperationCreate, constant.EventEntityTypePost, id, postSearchPayload(post))
+	case indexed:
+		go s.searchIndexPublisher.PublishSyncEvent(constant.EventOperationDelete, constant.EventEntityTypePost, id, nil)
+	}
+}
+
+// inPrivateCommunity reports whether post belongs to a private community. When the community
+// cannot be loaded the post is treated as private, so nothing leaks into the index.
+func (s *PostServiceImpl) inPrivateCommunity(ctx context.Context, post *model.Post) bool {
+	if post.CommunityID == 0 {
+		return false
+	}
+	community, err := s.communityRepo.GetCommunityDetailByID(ctx, post.CommunityID)
+	if err != nil {
+		log.Printf("Failed to get community %d of post %d: %v", post.CommunityID, post.ID, err)
+		return true
+	}
+	return community.Visibility == constant.CommunityVisibilityPrivate
+}
+
+func postSearchPayload(post *model.Post) map[string]interface{} {
+	payload := map[string]interface{}{
+		"title":        post.Title,
+		"description":  markdown.PlainText(post.Description),
+		"user_id":      post.UserID,
+		"community_id": post.CommunityID,
+		"visibility":   post.Visibility,
+		"tags":         text.ExtractHashtags(post.Title, post.Description),
+	}
+	if post.RepostOfID != nil {
+		payload["repost_of_id"] = *post.RepostOfID
+	}
+	return payload
 }
 
-func (s *PostServiceImpl) GetUserPosts(ctx context.Context, userID int) ([]model.Post, error) {
-	return s.postRepo.GetPostsByUserID(ctx, userID)
+// invalidateTimelines drops the cached timeline of the author and of everyone following them
+// so the new post shows up on their next read.
+func (s *PostServiceImpl) invalidateTimelines(ctx context.Context, authorID int) {
+	_ = s.redis.Delete(fmt.Sprintf("timeline_posts_user_%d", authorID))
+
+	followers, err := s.userRepo.GetFollowersByFollowingID(ctx, authorID)
+	if err != nil {
+		log.Printf("Failed to fan out timeline for user %d: %v", authorID, err)
+		return
+	}
+
+	for _, f := range followers {
+		_ = s.redis.Delete(fmt.Sprintf("timeline_posts_user_%d", f.FollowerID))
+	}
+}
+
+// GetPostDetail reports a post the viewer may not see exactly like a missing one,
+// so its existence does not leak either.
+func (s *PostServiceImpl) GetPostDetail(ctx context.Context, postID, viewerID int) (*model.Post, error) {
+	visible, err := s.postRepo.IsPostVisibleTo(ctx, postID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, errors.New("post not found")
+	}
+
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.RepostOfID != nil {
+		if visible, err := s.postRepo.IsPostVisibleTo(ctx, *post.RepostOfID, viewerID); err == nil && visible {
+			if original, err := s.postRepo.GetPostDetailByID(ctx, *post.RepostOfID); err == nil {
+				post.RepostOf = original
+			}
+		}
+	}
+
+	return post, nil
+}
+
+func (s *PostServiceImpl) GetUserPosts(ctx context.Context, userID, viewerID int) ([]model.Post, error) {
+	return s.postRepo.GetPostsByUserID(ctx, userID, viewerID)
 }
 
 func (s *PostServiceImpl) UpdatePost(ctx context.Context, postID int, req *dto.UpdatePostRequest) (*model.Post, error) {
+	existing, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	if existing.UserID != req.UserID {
+		allowed, err := s.canModeratePost(ctx, existing, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("only the author or a moderator can update this post")
+		}
+	}
+
 	updated := model.Post{
-		UserID:      req.UserID,
 		Title:       req.Title,
 		Description: req.Description,
 	}
+	if req.Description != "" {
+		updated.DescriptionHTML = markdown.Render(req.Description)
+	}
+
+	// Published posts cannot go back to draft; drafts and scheduled posts can be rescheduled freely.
+	if req.Status != "" && existing.Status != constant.PostStatusPublished {
+		status, err := resolvePostStatus(req.Status, req.ScheduledAt)
+		if err != nil {
+			return nil, err
+		}
+		if status == constant.PostStatusPublished {
+			return nil, errors.New("use the publish endpoint to publish a post")
+		}
+
+		// Drafts keep no schedule, so moving a scheduled post back to draft clears it.
+		var scheduledAt *time.Time
+		if status == constant.PostStatusScheduled {
+			scheduledAt = req.ScheduledAt
+		}
+		rescheduled, err := s.postRepo.ReschedulePost(ctx, postID, status, scheduledAt)
+		if err != nil {
+			return nil, errors.New("error updating post")
+		}
+		if !rescheduled {
+			return nil, errors.New("post has already been published")
+		}
+	}
+
+	previousVisibility := existing.Visibility
+	if req.Visibility != "" {
+		visibility, err := resolvePostVisibility(req.Visibility, existing.CommunityID)
+		if err != nil {
+			return nil, err
+		}
+		updated.Visibility = visibility
+		existing.Visibility = visibility
+	}
 
 	if err := s.postRepo.UpdatePost(ctx, postID, &updated); err != nil {
 		return nil, errors.New("error updating post")
 	}
 
+	contentChanged := req.Title != "" || req.Description != ""
+	visibilityChanged := existing.Visibility != previousVisibility
+
+	if contentChanged {
+		if req.Title != "" {
+			existing.Title = req.Title
+		}
+		if req.Description != "" {
+			existing.Description = req.Description
+			existing.DescriptionHTML = updated.DescriptionHTML
+		}
+
+		if err := s.tagRepo.SyncPostTags(ctx, postID, text.ExtractHashtags(existing.Title, existing.Description)); err != nil {
+			return nil, errors.New("error saving post tags")
+		}
+	}
+
+	if existing.Status == constant.PostStatusPublished && (contentChanged || visibilityChanged) {
+		s.syncPostSearch(ctx, existing, previousVisibility)
+	}
+
+	if visibilityChanged {
+		s.invalidateTimelines(ctx, existing.UserID)
+	}
+
+	if contentChanged {
+		if existing.Status == constant.PostStatusPublished {
+			s.processPostMentions(ctx, existing)
+
+			if err := s.linkPreviewService.QueuePostPreview(ctx, existing); err != nil {
+				log.Printf("Failed to queue link preview for post %d: %v", postID, err)
+			}
+		}
+	}
+
 	return &updated, nil
 }
 
 func (s *PostServiceImpl) DeletePost(ctx context.Context, postID int) error {
-	return s.postRepo.DeletePost(ctx, postID)
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return errors.New("post not found")
+	}
+
+	if err := s.postRepo.DeletePost(ctx, postID); err != nil {
+		return err
+	}
+
+	if post.RepostOfID != nil {
+		if err := s.postRepo.UpdatePostSharesCount(ctx, *post.RepostOfID, -1); err != nil {
+			log.Printf("Failed to decrement shares count of post %d: %v", *post.RepostOfID, err)
+		}
+	}
+
+	// Reposts only make sense while the original exists, so they go away with it.
+	reposts, err := s.postRepo.GetRepostsByOriginalID(ctx, postID)
+	if err != nil {
+		log.Printf("Failed to get reposts of post %d: %v", postID, err)
+	} else if len(reposts) > 0 {
+		if err := s.postRepo.DeleteRepostsByOriginalID(ctx, postID); err != nil {
+			log.Printf("Failed to delete reposts of post %d: %v", postID, err)
+		}
+		for _, repost := range reposts {
+			s.onPostRemoved(ctx, &repost)
+		}
+	}
+
+	s.onPostRemoved(ctx, post)
+
+	return nil
+}
+
+// onPostRemoved cleans up what still points at a deleted post.
+func (s *PostServiceImpl) onPostRemoved(ctx context.Context, post *model.Post) {
+	if post.CommunityID != 0 && post.Status == constant.PostStatusPublished {
+		if err := s.communityRepo.UpdateCommunityPostsCount(ctx, post.CommunityID, -1); err != nil {
+			log.Printf("Failed to decrement posts count of community %d: %v", post.CommunityID, err)
+		}
+	}
+
+	if post.PinnedPosition != nil {
+		if _, err := s.postRepo.UnpinPost(ctx, post.CommunityID, post.ID, nil); err != nil {
+			log.Printf("Failed to unpin post %d: %v", post.ID, err)
+		}
+	}
+
+	if err := s.linkPreviewService.RemovePostPreview(ctx, post.ID); err != nil {
+		log.Printf("Failed to delete link preview of post %d: %v", post.ID, err)
+	}
+
+	if err := s.bookmarkRepo.TombstoneBookmarksByTarget(ctx, constant.BookmarkTargetPost, post.ID); err != nil {
+		log.Printf("Failed to tombstone bookmarks of post %d: %v", post.ID, err)
+	}
+
+	// The daily viewer estimates expire on their own; the lifetime one has to go with the post.
+	_ = s.redis.Delete(postViewersKey(post.ID))
+
+	go s.searchIndexPublisher.PublishSyncEvent(constant.EventOperationDelete, constant.EventEntityTypePost, fmt.Sprintf("%d", post.ID), nil)
+
+	s.invalidateTimelines(ctx, post.UserID)
+}
+
+// RepostPost shares a post with the reposter's followers, or into each of req.CommunityIDs.
+// A non-empty commentary turns it into a quote post. Reposting a repost shares the original.
+func (s *PostServiceImpl) RepostPost(ctx context.Context, postID int, req *dto.RepostRequest) ([]model.Post, error) {
+	original, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	if original.RepostOfID != nil {
+		if original, err = s.postRepo.GetPostDetailByID(ctx, *original.RepostOfID); err != nil {
+			return nil, errors.New("post not found")
+		}
+	}
+	if original.Status != constant.PostStatusPublished {
+		return nil, errors.New("only published posts can be reposted")
+	}
+	// Reposts reach the reposter's whole audience, which a restricted post must not.
+	if original.Visibility != constant.PostVisibilityPublic {
+		return nil, errors.New("only public posts can be reposted")
+	}
+
+	reposter, err := s.userRepo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	communityIDs := req.CommunityIDs
+	if len(communityIDs) == 0 {
+		communityIDs = []int{0}
+	}
+	for _, communityID := range communityIDs {
+		if communityID == 0 {
+			continue
+		}
+		if err := checkCommunityParticipation(ctx, s.communityRepo, communityID, req.UserID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	var reposts []model.Post
+	for _, communityID := range communityIDs {
+		if req.Commentary == "" {
+			existing, err := s.postRepo.FindRepost(ctx, req.UserID, original.ID, communityID)
+			if err != nil {
+				return nil, errors.New("error checking existing repost")
+			}
+			if existing != nil {
+				continue
+			}
+		}
+
+		now := time.Now()
+		// The original's content is not copied: it is attached through RepostOf on read,
+		// where its visibility is checked again should it be narrowed later.
+		repost := model.Post{
+			UserID:          req.UserID,
+			Description:     req.Commentary,
+			DescriptionHTML: markdown.Render(req.Commentary),
+			CommunityID:     communityID,
+			Status:          constant.PostStatusPublished,
+			PublishedAt:     &now,
+			RepostOfID:      &original.ID,
+		}
+
+		if err := s.postRepo.CreatePost(ctx, &repost); err != nil {
+			// A concurrent request made the same plain repost between the check and here.
+			if errors.Is(err, repository.ErrDuplicateRecord) {
+				continue
+			}
+			return nil, errors.New("error creating repost")
+		}
+
+		if req.Commentary != "" {
+			if err := s.tagRepo.SyncPostTags(ctx, repost.ID, text.ExtractHashtags(req.Commentary)); err != nil {
+				return nil, errors.New("error saving post tags")
+			}
+		}
+
+		if err := s.postRepo.UpdatePostSharesCount(ctx, original.ID, 1); err != nil {
+			return nil, errors.New("error updating shares count")
+		}
+
+		if err := s.onPostPublished(ctx, &repost); err != nil {
+			return nil, err
+		}
+
+		repost.RepostOf = original
+		reposts = append(reposts, repost)
+	}
+
+	if len(reposts) == 0 {
+		return nil, errors.New("post has already been reposted")
+	}
+
+	if original.UserID != req.UserID {
+		notification := model.Notification{
+			UserID:  original.UserID,
+			ActorID: req.UserID,
+			PostID:  original.ID,
+			Type:    constant.NotificationTypeRepost,
+			Message: reposter.Username + " reposted your post: " + original.Title,
+			Read:    false,
+		}
+		if err := s.notificationRepo.CreateNotification(ctx, &notification); err != nil {
+			return nil, errors.New("error creating notification")
+		}
+	}
+
+	return reposts, nil
 }
 
 func (s *PostServiceImpl) GetTimelinePosts(ctx context.Context, userID int) ([]model.Post, error) {
@@ -112,11 +639,11 @@ func (s *PostServiceImpl) GetTimelinePosts(ctx context.Context, userID int) ([]m
 		Data []model.Post `json:"data"`
 	}
 
-	if err := s.redis.Get(cacheKey, cached); err == nil {
+	if err := s.redis.Get(cacheKey, &cached); err == nil {
 		return cached.Data, nil
 	}
 
-	userPosts, err := s.postRepo.GetPostsByUserID(ctx, userID)
+	userPosts, err := s.postRepo.GetPostsByUserID(ctx, userID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +655,7 @@ func (s *PostServiceImpl) GetTimelinePosts(ctx context.Context, userID int) ([]m
 
 	var followerPosts []model.Post
 	for _, f := range followers {
-		if posts, err := s.postRepo.GetPostsByUserID(ctx, f.FollowingID); err == nil {
+		if posts, err := s.postRepo.GetPostsByUserID(ctx, f.FollowingID, userID); err == nil {
 			followerPosts = append(followerPosts, posts...)
 		}
 	}
@@ -141,29 +668,36 @@ func (s *PostServiceImpl) GetTimelinePosts(ctx context.Context, userID int) ([]m
 	return allPosts, nil
 }
 
-func (s *PostServiceImpl) LikePost(ctx context.Context, postID, userID int) error {
-	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+// LikePost likes the post on behalf of userID. It reports whether a like was created, so
+// liking a post twice is accepted without being counted again.
+func (s *PostServiceImpl) LikePost(ctx context.Context, postID, userID int) (bool, error) {
+	open, err := s.postRepo.CanInteractWithPost(ctx, postID, userID)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.New("post not found")
-		}
-		return err
+		return false, err
+	}
+	if !open {
+		return false, errors.New("post not found")
 	}
 
-	for _, u := range post.Likes {
-		if u.ID == userID {
-			return nil // already liked
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, errors.New("post not found")
 		}
+		return false, err
 	}
 
 	liker, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
-		return errors.New("user not found")
+		return false, errors.New("user not found")
 	}
 
-	post.Likes = append(post.Likes, &model.User{ID: userID})
-	if err := s.postRepo.UpdatePost(ctx, postID, post); err != nil {
-		return err
+	liked, err := s.postRepo.AddPostLike(ctx, postID, userID)
+	if err != nil {
+		return false, err
+	}
+	if !liked {
+		return false, nil // already liked
 	}
 
 	notification := model.Notification{
@@ -174,5 +708,456 @@ func (s *PostServiceImpl) LikePost(ctx context.Context, postID, userID int) erro
 		Message: liker.Username + " liked your post: " + post.Title,
 		Read:    false,
 	}
-	return s.notificationRepo.CreateNotification(ctx, &notification)
+	if err := s.notificationRepo.CreateNotification(ctx, &notification); err != nil {
+		log.Printf("Failed to notify user %d of a like on post %d: %v", post.UserID, post.ID, err)
+	}
+	return true, nil
+}
+
+func (s *PostServiceImpl) GetUnpublishedPosts(ctx context.Context, userID int) ([]model.Post, error) {
+	posts, err := s.postRepo.GetUnpublishedPostsByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("error retrieving unpublished posts")
+	}
+	return posts, nil
+}
+
+func (s *PostServiceImpl) PublishPost(ctx context.Context, postID, userID int) (*model.Post, error) {
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	if post.UserID != userID {
+		return nil, errors.New("only the author can publish this post")
+	}
+	if post.Status == constant.PostStatusPublished {
+		return nil, errors.New("post is already published")
+	}
+	if post.CommunityID != 0 {
+		if err := checkCommunityParticipation(ctx, s.communityRepo, post.CommunityID, post.UserID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	published, err := s.postRepo.MarkPostPublished(ctx, postID, post.Status, now)
+	if err != nil {
+		return nil, errors.New("error publishing post")
+	}
+	if !published {
+		return nil, errors.New("post is already published")
+	}
+
+	post.Status = constant.PostStatusPublished
+	post.PublishedAt = &now
+	if err := s.onPostPublished(ctx, post); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// PublishDuePosts publishes every scheduled post whose time has come. Only one replica scans at a
+// time thanks to the Redis lock, and MarkPostPublished guarantees each post is published once
+// even if the lock expires mid-run.
+func (s *PostServiceImpl) PublishDuePosts(ctx context.Context) error {
+	lockValue := uuid.NewString()
+	acquired, err := s.redis.AcquireLock(scheduledPostsLockKey, lockValue, scheduledPostsLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire scheduled posts lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer s.redis.ReleaseLock(scheduledPostsLockKey, lockValue)
+
+	posts, err := s.postRepo.GetDueScheduledPosts(ctx, time.Now(), scheduledPostsBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		now := time.Now()
+		published, err := s.postRepo.MarkPostPublished(ctx, posts[i].ID, constant.PostStatusScheduled, now)
+		if err != nil {
+			log.Printf("Failed to publish scheduled post %d: %v", posts[i].ID, err)
+			continue
+		}
+		if !published {
+			continue
+		}
+
+		posts[i].Status = constant.PostStatusPublished
+		posts[i].PublishedAt = &now
+		if err := s.onPostPublished(ctx, &posts[i]); err != nil {
+			log.Printf("Failed to run publish side effects for post %d: %v", posts[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+// LockPost stops new comments on a post. Only moderators of its community and admins may
+// lock or unlock a post, and every change is recorded in the post's moderation log.
+func (s *PostServiceImpl) LockPost(ctx context.Context, postID int, req *dto.LockPostRequest) error {
+	return s.setPostLocked(ctx, postID, req, true)
+}
+
+func (s *PostServiceImpl) UnlockPost(ctx context.Context, postID int, req *dto.LockPostRequest) error {
+	return s.setPostLocked(ctx, postID, req, false)
+}
+
+func (s *PostServiceImpl) setPostLocked(ctx context.Context, postID int, req *dto.LockPostRequest, locked bool) error {
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return errors.New("post not found")
+	}
+
+	allowed, err := s.canModeratePost(ctx, post, req.UserID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("only moderators can lock or unlock this post")
+	}
+
+	entry := model.PostModerationLog{
+		PostID:      post.ID,
+		ModeratorID: req.UserID,
+		Action:      constant.PostModerationActionUnlock,
+		Reason:      req.Reason,
+	}
+	if locked {
+		entry.Action = constant.PostModerationActionLock
+	}
+
+	if err := s.postRepo.SetPostLocked(ctx, post.ID, locked, &entry); err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			if locked {
+				return errors.New("post is already locked")
+			}
+			return errors.New("post is not locked")
+		}
+		return errors.New("error updating post lock")
+	}
+
+	return nil
+}
+
+// UpdateCommentSettings changes who may comment on a post and its slow mode interval.
+// The author, moderators of its community and admins may change them.
+func (s *PostServiceImpl) UpdateCommentSettings(ctx context.Context, postID int, req *dto.CommentSettingsRequest) (*model.Post, error) {
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+
+	if post.UserID != req.UserID {
+		allowed, err := s.canModeratePost(ctx, post, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("not allowed to change the comment settings of this post")
+		}
+	}
+
+	updates := map[string]interface{}{}
+	if req.CommentRestriction != "" {
+		switch req.CommentRestriction {
+		case constant.PostCommentRestrictionEveryone, constant.PostCommentRestrictionFollowers:
+		case constant.PostCommentRestrictionMembers:
+			if post.CommunityID == 0 {
+				return nil, errors.New("members only comments require a community post")
+			}
+		default:
+			return nil, errors.New("invalid comment restriction")
+		}
+		updates["comment_restriction"] = req.CommentRestriction
+		post.CommentRestriction = req.CommentRestriction
+	}
+	if req.SlowModeSeconds != nil {
+		if *req.SlowModeSeconds < 0 || *req.SlowModeSeconds > constant.PostSlowModeMaxSeconds {
+			return nil, fmt.Errorf("slow mode must be between 0 and %d seconds", constant.PostSlowModeMaxSeconds)
+		}
+		updates["slow_mode_seconds"] = *req.SlowModeSeconds
+		post.SlowModeSeconds = *req.SlowModeSeconds
+	}
+	if len(updates) == 0 {
+		return nil, errors.New("no comment settings to update")
+	}
+
+	entry := model.PostModerationLog{
+		PostID:      post.ID,
+		ModeratorID: req.UserID,
+		Action:      constant.PostModerationActionCommentSettings,
+		Reason:      req.Reason,
+		Details:     fmt.Sprintf("comment_restriction=%s slow_mode_seconds=%d", post.CommentRestriction, post.SlowModeSeconds),
+	}
+
+	if err := s.postRepo.UpdateCommentSettings(ctx, post.ID, updates, &entry); err != nil {
+		return nil, errors.New("error updating comment settings")
+	}
+
+	return post, nil
+}
+
+// SetPostFlair changes the flair of a community post. The author, moderators of its
+// community and admins may change it.
+func (s *PostServiceImpl) SetPostFlair(ctx context.Context, postID int, req *dto.SetPostFlairRequest) (*model.Post, error) {
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+
+	if post.UserID != req.UserID {
+		allowed, err := s.canModeratePost(ctx, post, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("not allowed to change the flair of this post")
+		}
+	}
+
+	if err := checkPostFlair(ctx, s.communityRepo, s.flairRepo, post.CommunityID, req.FlairID); err != nil {
+		return nil, err
+	}
+
+	if err := s.flairRepo.SetPostFlair(ctx, post.ID, req.FlairID); err != nil {
+		return nil, errors.New("error updating post flair")
+	}
+
+	// Reload so the response carries the new flair itself, not just its ID.
+	updated, err := s.postRepo.GetPostDetailByID(ctx, post.ID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	return updated, nil
+}
+
+// PinPost pins a community post to the top of the community feed. Only moderators of the
+// community and admins may pin, and at most constant.CommunityMaxPinnedPosts at a time.
+func (s *PostServiceImpl) PinPost(ctx context.Context, postID int, req *dto.PinPostRequest) error {
+	post, err := s.getModeratedCommunityPost(ctx, postID, req.UserID, "pin")
+	if err != nil {
+		return err
+	}
+
+	entry := model.PostModerationLog{
+		PostID:      post.ID,
+		ModeratorID: req.UserID,
+		Action:      constant.PostModerationActionPin,
+		Reason:      req.Reason,
+	}
+
+	if err := s.postRepo.PinPost(ctx, post.CommunityID, post.ID, constant.CommunityMaxPinnedPosts, &entry); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicateRecord):
+			return errors.New("post is already pinned")
+		case errors.Is(err, repository.ErrLimitReached):
+			return fmt.Errorf("a community can pin at most %d posts", constant.CommunityMaxPinnedPosts)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return errors.New("only published posts can be pinned")
+		}
+		return errors.New("error pinning post")
+	}
+
+	return nil
+}
+
+func (s *PostServiceImpl) UnpinPost(ctx context.Context, postID int, req *dto.PinPostRequest) error {
+	post, err := s.getModeratedCommunityPost(ctx, postID, req.UserID, "unpin")
+	if err != nil {
+		return err
+	}
+
+	entry := model.PostModerationLog{
+		PostID:      post.ID,
+		ModeratorID: req.UserID,
+		Action:      constant.PostModerationActionUnpin,
+		Reason:      req.Reason,
+	}
+
+	unpinned, err := s.postRepo.UnpinPost(ctx, post.CommunityID, post.ID, &entry)
+	if err != nil {
+		return errors.New("error unpinning post")
+	}
+	if !unpinned {
+		return errors.New("post is not pinned")
+	}
+
+	return nil
+}
+
+// ReorderPinnedPosts changes the order of the pinned posts of a community.
+func (s *PostServiceImpl) ReorderPinnedPosts(ctx context.Context, communityID int, req *dto.ReorderPinnedPostsRequest) error {
+	allowed, err := canModerateCommunity(ctx, s.userRepo, s.moderatorRepo, communityID, req.UserID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("only moderators can reorder the pinned posts of this community")
+	}
+
+	if err := s.postRepo.ReorderPinnedPosts(ctx, communityID, req.PostIDs); err != nil {
+		if errors.Is(err, repository.ErrStaleRecord) {
+			return errors.New("post_ids must list every pinned post of the community exactly once")
+		}
+		return errors.New("error reordering pinned posts")
+	}
+
+	return nil
+}
+
+// getModeratedCommunityPost loads a community post that userID moderates, for the action
+// named in the error returned otherwise.
+func (s *PostServiceImpl) getModeratedCommunityPost(ctx context.Context, postID, userID int, action string) (*model.Post, error) {
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	if post.CommunityID == 0 {
+		return nil, errors.New("only community posts can be pinned")
+	}
+
+	allowed, err := s.canModeratePost(ctx, post, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("only moderators can %s posts in this community", action)
+	}
+
+	return post, nil
+}
+
+// CreateAnnouncement posts an announcement in a community on behalf of one of its
+// moderators. Once it is published, DeliverAnnouncements notifies every member in the
+// background, so the request does not wait on the size of the community.
+func (s *PostServiceImpl) CreateAnnouncement(ctx context.Context, communityID int, req *dto.CreateAnnouncementRequest) (*model.Post, error) {
+	allowed, err := canModerateCommunity(ctx, s.userRepo, s.moderatorRepo, communityID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("only moderators can post announcements in this community")
+	}
+
+	status := constant.PostStatusPublished
+	if req.ScheduledAt != nil {
+		status = constant.PostStatusScheduled
+	}
+
+	return s.createPost(ctx, &dto.CreatePostRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		UserID:      req.UserID,
+		CommunityID: communityID,
+		FlairID:     req.FlairID,
+		Status:      status,
+		ScheduledAt: req.ScheduledAt,
+	}, constant.PostTypeAnnouncement)
+}
+
+// DeliverAnnouncements notifies the members of communities with pending announcements,
+// one batch at a time. It runs periodically; a Redis lock keeps instances from delivering
+// the same batches concurrently.
+func (s *PostServiceImpl) DeliverAnnouncements(ctx context.Context) error {
+	lockValue := uuid.NewString()
+	acquired, err := s.redis.AcquireLock(announcementsLockKey, lockValue, announcementsLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire announcements lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer s.redis.ReleaseLock(announcementsLockKey, lockValue)
+
+	announcements, err := s.announcementRepo.GetPendingAnnouncements(ctx, announcementsPerRun)
+	if err != nil {
+		return err
+	}
+
+	for i := range announcements {
+		s.deliverAnnouncement(ctx, &announcements[i])
+	}
+
+	return nil
+}
+
+func (s *PostServiceImpl) deliverAnnouncement(ctx context.Context, announcement *model.CommunityAnnouncement) {
+	post, err := s.postRepo.GetPostDetailByID(ctx, announcement.PostID)
+	if err != nil {
+		log.Printf("Failed to get post of announcement %d: %v", announcement.ID, err)
+		return
+	}
+	community, err := s.communityRepo.GetCommunityDetailByID(ctx, announcement.CommunityID)
+	if err != nil {
+		log.Printf("Failed to get community of announcement %d: %v", announcement.ID, err)
+		return
+	}
+
+	template := model.Notification{
+		ActorID: announcement.AuthorID,
+		PostID:  post.ID,
+		Type:    constant.NotificationTypeCommunityAnnouncement,
+		Message: "New announcement in " + community.Name + ": " + post.Title,
+		Read:    false,
+	}
+
+	for i := 0; i < announcementBatchesPerRun; i++ {
+		done, err := s.announcementRepo.DeliverAnnouncementBatch(ctx, announcement.ID, template, announcementBatchSize)
+		if err != nil {
+			log.Printf("Failed to deliver announcement %d: %v", announcement.ID, err)
+			return
+		}
+		if done {
+			return
+		}
+	}
+}
+
+// GetPostModerationLogs lists the lock and comment settings changes of a post, newest first.
+func (s *PostServiceImpl) GetPostModerationLogs(ctx context.Context, postID, userID int) ([]model.PostModerationLog, error) {
+	post, err := s.postRepo.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+
+	if post.UserID != userID {
+		allowed, err := s.canModeratePost(ctx, post, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errors.New("not allowed to view the moderation log of this post")
+		}
+	}
+
+	logs, err := s.postRepo.GetPostModerationLogs(ctx, post.ID)
+	if err != nil {
+		return nil, errors.New("error retrieving moderation log")
+	}
+	return logs, nil
+}
+
+// canModeratePost reports whether userID is an admin or a moderator of the post's community.
+func (s *PostServiceImpl) canModeratePost(ctx context.Context, post *model.Post, userID int) (bool, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, errors.New("user not found")
+	}
+	if user.Role == constant.UserRoleAdmin {
+		return true, nil
+	}
+	if post.CommunityID == 0 {
+		return false, nil
+	}
+
+	isModerator, err := s.moderatorRepo.IsCommunityModerator(ctx, post.CommunityID, userID)
+	if err != nil {
+		return false, errors.New("error checking moderator")
+	}
+	return isModerator, nil
 }
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/publisher"
+	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/key_value_store"
+)
+
+const (
+	postStatsDirtyKey  = "post_stats_dirty"
+	postStatsLockKey   = "lock_post_stats_flusher"
+	postStatsLockTTL   = 50 * time.Second
+	postStatsBatchSize = 500
+)
+
+type PostStats struct {
+	PostID int                         `json:"post_id"`
+	From   string                      `json:"from"`
+	To     string                      `json:"to"`
+	Totals *repository.PostStatsTotals `json:"totals"`
+	Daily  []model.PostDailyStat       `json:"daily"`
+}
+
+type PostStatsService interface {
+	RecordView(ctx context.Context, post *model.Post, viewerID int, viewerKey string)
+	RecordImpressions(ctx context.Context, posts []model.Post, viewerID int)
+	RecordEngagement(ctx context.Context, postID int, metric string)
+	FlushStats(ctx context.Context) error
+	GetPostStats(ctx context.Context, postID, requesterID int, from, to string) (*PostStats, error)
+}
+
+// PostStatsServiceImpl buffers counters per post and UTC day in a Redis hash and moves
+// them to Postgres in FlushStats. Unique viewers are estimated with one HyperLogLog per
+// day and one for the lifetime of the post. Authors looking at their own posts are not
+// counted. Tracking is best effort and never fails the request.
+type PostStatsServiceImpl struct {
+	PostStatsRepository repository.PostStatsRepository
+	PostRepository      repository.PostRepository
+	ModeratorRepository repository.ModeratorRepository
+	UserRepository      repository.UserRepository
+	Redis               key_value_store.RedisWrapper
+	AnalyticsPublisher  publisher.AnalyticsPublisher
+}
+
+func NewPostStatsService(
+	postStatsRepo repository.PostStatsRepository,
+	postRepo repository.PostRepository,
+	moderatorRepo repository.ModeratorRepository,
+	userRepo repository.UserRepository,
+	redis key_value_store.RedisWrapper,
+	analyticsPublisher publisher.AnalyticsPublisher,
+) PostStatsService {
+	return &PostStatsServiceImpl{
+		PostStatsRepository: postStatsRepo,
+		PostRepository:      postRepo,
+		ModeratorRepository: moderatorRepo,
+		UserRepository:      userRepo,
+		Redis:               redis,
+		AnalyticsPublisher:  analyticsPublisher,
+	}
+}
+
+func postStatsBufferKey(postID int, day string) string {
+	return fmt.Sprintf("post_stats_buffer_%d_%s", postID, day)
+}
+
+func postDailyViewersKey(postID int, day string) string {
+	return fmt.Sprintf("post_unique_viewers_%d_%s", postID, day)
+}
+
+func postViewersKey(postID int) string {
+	return fmt.Sprintf("post_unique_viewers_%d", postID)
+}
+
+func (s *PostStatsServiceImpl) RecordView(ctx context.Context, post *model.Post, viewerID int, viewerKey string) {
+	if viewerID != 0 && viewerID == post.UserID {
+		return
+	}
+
+	postID := post.ID
+	day := time.Now().UTC().Format(constant.PostStatsDateLayout)
+
+	s.increment(postID, day, constant.PostStatViews)
+
+	if viewerKey == "" {
+		return
+	}
+	dailyKey := postDailyViewersKey(postID, day)
+	if err := s.Redis.AddToHyperLogLog(dailyKey, viewerKey); err != nil {
+		log.Printf("Failed to record viewer of post %d: %v", postID, err)
+		return
+	}
+	_ = s.Redis.Expire(dailyKey, constant.PostStatsDailyTTL)
+	_ = s.Redis.AddToHyperLogLog(postViewersKey(postID), viewerKey)
+}
+
+func (s *PostStatsServiceImpl) RecordImpressions(ctx context.Context, posts []model.Post, viewerID int) {
+	day := time.Now().UTC().Format(constant.PostStatsDateLayout)
+
+	for _, post := range posts {
+		if viewerID != 0 && viewerID == post.UserID {
+			continue
+		}
+		s.increment(post.ID, day, constant.PostStatImpressions)
+	}
+}
+
+func (s *PostStatsServiceImpl) RecordEngagement(ctx context.Context, postID int, metric string) {
+	s.increment(postID, time.Now().UTC().Format(constant.PostStatsDateLayout), metric)
+}
+
+func (s *PostStatsServiceImpl) increment(postID int, day, metric string) {
+	if err := s.Redis.IncrementHashField(postStatsBufferKey(postID, day), metric, 1); err != nil {
+		log.Printf("Failed to buffer %s of post %d: %v", metric, postID, err)
+		return
+	}
+	if err := s.Redis.AddToSet(postStatsDirtyKey, fmt.Sprintf("%d:%s", postID, day)); err != nil {
+		log.Printf("Failed to mark stats of post %d dirty: %v", postID, err)
+	}
+}
+
+// FlushStats moves buffered counters to Postgres and publishes them on the analytics exchange.
+// Buffers whose write fails are put back so the next run retries them.
+func (s *PostStatsServiceImpl) FlushStats(ctx context.Context) error {
+	lockValue := uuid.NewString()
+
+	acquired, err := s.Redis.AcquireLock(postStatsLockKey, lockValue, postStatsLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire post stats lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := s.Redis.ReleaseLock(postStatsLockKey, lockValue); err != nil {
+			log.Printf("Failed to release post stats lock: %v", err)
+		}
+	}()
+
+	members, err := s.Redis.PopFromSet(postStatsDirtyKey, postStatsBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to read dirty post stats: %w", err)
+	}
+
+	for _, member := range members {
+		if err := s.flushPostDay(ctx, member); err != nil {
+			log.Printf("Failed to flush post stats %s: %v", member, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *PostStatsServiceImpl) flushPostDay(ctx context.Context, member string) error {
+	idPart, day, found := strings.Cut(member, ":")
+	postID, err := strconv.Atoi(idPart)
+	if !found || err != nil {
+		return errors.New("malformed post stats entry")
+	}
+	date, err := time.Parse(constant.PostStatsDateLayout, day)
+	if err != nil {
+		return errors.New("malformed post stats date")
+	}
+
+	values, err := s.Redis.TakeHash(postStatsBufferKey(postID, day))
+	if err != nil {
+		return err
+	}
+
+	counters := make(map[string]int64, len(values))
+	for field, raw := range values {
+		n, _ := strconv.ParseInt(raw, 10, 64)
+		counters[field] = n
+	}
+
+	dailyUnique, _ := s.Redis.CountHyperLogLog(postDailyViewersKey(postID, day))
+	totalUnique, _ := s.Redis.CountHyperLogLog(postViewersKey(postID))
+
+	stat := model.PostDailyStat{
+		PostID:        postID,
+		Date:          date,
+		Views:         counters[constant.PostStatViews],
+		UniqueViewers: dailyUnique,
+		Impressions:   counters[constant.PostStatImpressions],
+		Likes:         counters[constant.PostStatLikes],
+		Comments:      counters[constant.PostStatComments],
+	}
+
+	if err := s.PostStatsRepository.AddDailyStat(ctx, &stat); err != nil {
+		s.restore(postID, day, counters)
+		return err
+	}
+
+	if err := s.PostStatsRepository.IncrementPostCounters(ctx, postID, stat.Views, stat.Impressions, totalUnique); err != nil {
+		log.Printf("Failed to update counters of post %d: %v", postID, err)
+	}
+
+	go s.AnalyticsPublisher.PublishEvent(constant.AnalyticsEventPostStats, constant.EventEntityTypePost, idPart, map[string]interface{}{
+		"date":                 day,
+		"views":                stat.Views,
+		"unique_viewers":       stat.UniqueViewers,
+		"impressions":          stat.Impressions,
+		"likes":                stat.Likes,
+		"comments":             stat.Comments,
+		"total_unique_viewers": totalUnique,
+	})
+
+	return nil
+}
+
+func (s *PostStatsServiceImpl) restore(postID int, day string, counters map[string]int64) {
+	key := postStatsBufferKey(postID, day)
+	for field, n := range counters {
+		_ = s.Redis.IncrementHashField(key, field, n)
+	}
+	_ = s.Redis.AddToSet(postStatsDirtyKey, fmt.Sprintf("%d:%s", postID, day))
+}
+
+// GetPostStats is only available to the author of the post, to moderators of its community
+// and to admins.
+// from and to are inclusive YYYY-MM-DD dates and default to the last 30 days.
+func (s *PostStatsServiceImpl) GetPostStats(ctx context.Context, postID, requesterID int, from, to string) (*PostStats, error) {
+	post, err := s.PostRepository.GetPostDetailByID(ctx, postID)
+	if err != nil {
+		return nil, newError(constant.PostStatsErrorPostNotFound, "post not found")
+	}
+
+	if post.UserID != requesterID {
+		requester, err := s.UserRepository.GetUserByID(ctx, requesterID)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		allowed := requester.Role == constant.UserRoleAdmin
+		if !allowed && post.CommunityID != 0 {
+			if allowed, err = s.ModeratorRepository.IsCommunityModerator(ctx, post.CommunityID, requesterID); err != nil {
+				return nil, errors.New("error checking moderator")
+			}
+		}
+		if !allowed {
+			return nil, newError(constant.PostStatsErrorForbidden, "not allowed to view post stats")
+		}
+	}
+
+	toDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		if toDate, err = time.Parse(constant.PostStatsDateLayout, to); err != nil {
+			return nil, newError(constant.PostStatsErrorInvalidRange, "invalid to date")
+		}
+	}
+	fromDate := toDate.AddDate(0, 0, -29)
+	if from != "" {
+		if fromDate, err = time.Parse(constant.PostStatsDateLayout, from); err != nil {
+			return nil, newError(constant.PostStatsErrorInvalidRange, "invalid from date")
+		}
+	}
+	if fromDate.After(toDate) {
+		return nil, newError(constant.PostStatsErrorInvalidRange, "from date must not be after to date")
+	}
+	if toDate.Sub(fromDate) > constant.PostStatsMaxRangeDay*24*time.Hour {
+		return nil, newError(constant.PostStatsErrorInvalidRange, "date range is too large")
+	}
+
+	daily, err := s.PostStatsRepository.GetDailyStats(ctx, postID, fromDate, toDate)
+	if err != nil {
+		return nil, errors.New("error retrieving post stats")
+	}
+
+	totals, err := s.PostStatsRepository.GetPostStatsTotals(ctx, postID)
+	if err != nil {
+		return nil, errors.New("error retrieving post stats")
+	}
+
+	return &PostStats{
+		PostID: postID,
+		From:   fromDate.Format(constant.PostStatsDateLayout),
+		To:     toDate.Format(constant.PostStatsDateLayout),
+		Totals: totals,
+		Daily:  daily,
+	}, nil
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/model"
 	"github.com/temuka-api-service/internal/repository"
 )
@@ -13,16 +15,54 @@ type ReportService interface {
 }
 
 type ReportServiceImpl struct {
-	ReportRepository repository.ReportRepository
+	ReportRepository        repository.ReportRepository
+	PostRepository          repository.PostRepository
+	CommentRepository       repository.CommentRepository
+	CommunityRuleRepository repository.CommunityRuleRepository
 }
 
-func NewReportService(reportRepo repository.ReportRepository) ReportService {
+func NewReportService(
+	reportRepo repository.ReportRepository,
+	postRepo repository.PostRepository,
+	commentRepo repository.CommentRepository,
+	ruleRepo repository.CommunityRuleRepository,
+) ReportService {
 	return &ReportServiceImpl{
-		ReportRepository: reportRepo,
+		ReportRepository:        reportRepo,
+		PostRepository:          postRepo,
+		CommentRepository:       commentRepo,
+		CommunityRuleRepository: ruleRepo,
 	}
 }
 
+// CreateReport files a report against a post or a comment. A report that cites a rule
+// must cite one of the community the content was posted in that covers that content.
 func (s *ReportServiceImpl) CreateReport(ctx context.Context, report *model.Report) error {
+	if report.PostID == nil && report.CommentID == nil {
+		return errors.New("a report needs a post or a comment")
+	}
+
+	if report.RuleID != nil {
+		postID, target := 0, constant.CommunityRuleAppliesToPosts
+		if report.CommentID != nil {
+			comment, err := s.CommentRepository.GetCommentDetailByID(ctx, *report.CommentID)
+			if err != nil {
+				return errors.New("comment not found")
+			}
+			postID, target = comment.PostID, constant.CommunityRuleAppliesToComments
+		} else {
+			postID = *report.PostID
+		}
+
+		post, err := s.PostRepository.GetPostDetailByID(ctx, postID)
+		if err != nil {
+			return errors.New("post not found")
+		}
+		if _, err := citedRule(ctx, s.CommunityRuleRepository, post.CommunityID, *report.RuleID, target); err != nil {
+			return err
+		}
+	}
+
 	return s.ReportRepository.CreateReport(ctx, report)
 }
 
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/text"
+)
+
+// slugAttempts bounds how often a slug is regenerated when another entity takes it between
+// the availability check and the write.
+const slugAttempts = 3
+
+const slugSuffixAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// SlugMovedError is returned when an entity is looked up by a slug it no longer has. Slug is
+// its current one, which the client should be redirected to.
+type SlugMovedError struct {
+	Slug string
+}
+
+func (e *SlugMovedError) Error() string {
+	return "moved to " + e.Slug
+}
+
+// SlugService builds the URL slugs of communities and universities. Slugs are unique per
+// entity type, never reserved words, and retired slugs keep pointing to their entity.
+type SlugService interface {
+	CreateWithSlug(ctx context.Context, entityType, name string, create func(slug string) error) error
+	ChangeSlug(ctx context.Context, entityType string, entityID int, name string) (string, error)
+	GetRedirect(ctx context.Context, entityType, slug string) (int, error)
+}
+
+type SlugServiceImpl struct {
+	SlugRepository repository.SlugRepository
+}
+
+func NewSlugService(slugRepo repository.SlugRepository) SlugService {
+	return &SlugServiceImpl{SlugRepository: slugRepo}
+}
+
+// CreateWithSlug calls create with a free slug built from name, trying again with a fresh
+// one while create fails with repository.ErrDuplicateRecord.
+func (s *SlugServiceImpl) CreateWithSlug(ctx context.Context, entityType, name string, create func(slug string) error) error {
+	var err error
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		var slug string
+		if slug, err = s.generateSlug(ctx, entityType, name, 0); err != nil {
+			return err
+		}
+		if err = create(slug); !errors.Is(err, repository.ErrDuplicateRecord) {
+			return err
+		}
+	}
+	return err
+}
+
+// ChangeSlug gives an entity a slug built from its new name and returns it. The previous
+// slug goes to the slug history so old links keep working.
+func (s *SlugServiceImpl) ChangeSlug(ctx context.Context, entityType string, entityID int, name string) (string, error) {
+	var err error
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		var slug string
+		if slug, err = s.generateSlug(ctx, entityType, name, entityID); err != nil {
+			return "", err
+		}
+		if err = s.SlugRepository.ChangeSlug(ctx, entityType, entityID, slug); err == nil {
+			return slug, nil
+		}
+		if !errors.Is(err, repository.ErrDuplicateRecord) {
+			return "", err
+		}
+	}
+	return "", err
+}
+
+// GetRedirect returns the ID of the entity that used to have slug.
+func (s *SlugServiceImpl) GetRedirect(ctx context.Context, entityType, slug string) (int, error) {
+	history, err := s.SlugRepository.GetSlugRedirect(ctx, entityType, slug)
+	if err != nil {
+		return 0, err
+	}
+	return history.EntityID, nil
+}
+
+// generateSlug returns the first free slug among the slug of name and its numbered variants
+// ("name", "name-2", "name-3", ...), falling back to a random suffix.
+func (s *SlugServiceImpl) generateSlug(ctx context.Context, entityType, name string, entityID int) (string, error) {
+	base := text.Slugify(name)
+	if base == "" {
+		base = entityType
+	}
+	if constant.ReservedSlugs[base] {
+		base += "-" + entityType
+	}
+
+	for n := 1; n <= constant.SlugMaxSuffix; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = withSlugSuffix(base, fmt.Sprintf("%d", n))
+		}
+		available, err := s.SlugRepository.IsSlugAvailable(ctx, entityType, candidate, entityID)
+		if err != nil {
+			return "", err
+		}
+		if available {
+			return candidate, nil
+		}
+	}
+
+	suffix, err := randomSlugSuffix()
+	if err != nil {
+		return "", err
+	}
+	return withSlugSuffix(base, suffix), nil
+}
+
+// withSlugSuffix appends "-suffix" to base, shortening base so the result still fits in
+// text.MaxSlugLength.
+func withSlugSuffix(base, suffix string) string {
+	runes := []rune(base)
+	if max := text.MaxSlugLength - len(suffix) - 1; len(runes) > max {
+		runes = runes[:max]
+	}
+	return strings.TrimRight(string(runes), "-") + "-" + suffix
+}
+
+func randomSlugSuffix() (string, error) {
+	suffix := make([]byte, 6)
+	max := big.NewInt(int64(len(slugSuffixAlphabet)))
+	for i := range suffix {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		suffix[i] = slugSuffixAlphabet[n.Int64()]
+	}
+	return string(suffix), nil
+}
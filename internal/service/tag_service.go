@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/temuka-api-service/internal/constant"
+	"github.com/temuka-api-service/internal/model"
+	"github.com/temuka-api-service/internal/repository"
+	"github.com/temuka-api-service/util/key_value_store"
+	"github.com/temuka-api-service/util/text"
+)
+
+type TagService interface {
+	GetTagPosts(ctx context.Context, name string, viewerID, page, limit int) ([]model.Post, int64, error)
+	GetTrendingTags(ctx context.Context, window string, limit int) ([]repository.TrendingTag, error)
+}
+
+type TagServiceImpl struct {
+	TagRepository repository.TagRepository
+	Redis         key_value_store.RedisWrapper
+}
+
+func NewTagService(tagRepo repository.TagRepository, redis key_value_store.RedisWrapper) TagService {
+	return &TagServiceImpl{
+		TagRepository: tagRepo,
+		Redis:         redis,
+	}
+}
+
+func (s *TagServiceImpl) GetTagPosts(ctx context.Context, name string, viewerID, page, limit int) ([]model.Post, int64, error) {
+	normalized := text.NormalizeHashtag(name)
+	if normalized == "" {
+		return nil, 0, errors.New("invalid tag")
+	}
+
+	tag, err := s.TagRepository.GetTagByName(ctx, normalized)
+	if err != nil {
+		return nil, 0, errors.New("tag not found")
+	}
+
+	posts, total, err := s.TagRepository.GetPostsByTagID(ctx, tag.ID, viewerID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, errors.New("error retrieving tag posts")
+	}
+
+	return posts, total, nil
+}
+
+func (s *TagServiceImpl) GetTrendingTags(ctx context.Context, window string, limit int) ([]repository.TrendingTag, error) {
+	if window == "" {
+		window = constant.DefaultTrendingTagWindow
+	}
+	duration, ok := constant.TrendingTagWindows[window]
+	if !ok {
+		return nil, errors.New("invalid trending window")
+	}
+
+	cacheKey := fmt.Sprintf("trending_tags_%s_%d", window, limit)
+
+	var cached []repository.TrendingTag
+	if err := s.Redis.Get(cacheKey, &cached); err == nil {
+		return cached, nil
+	}
+
+	trending, err := s.TagRepository.GetTrendingTags(ctx, time.Now().Add(-duration), limit)
+	if err != nil {
+		return nil, errors.New("error retrieving trending tags")
+	}
+
+	_ = s.Redis.Set(cacheKey, trending, 5*time.Minute)
+
+	return trending, nil
+}
@@ -3,8 +3,9 @@ package service
 import (
 	"context"
 	"errors"
-	"strings"
+	"time"
 
+	"github.com/temuka-api-service/internal/constant"
 	"github.com/temuka-api-service/internal/dto"
 	"github.com/temuka-api-service/internal/model"
 	"github.com/temuka-api-service/internal/repository"
@@ -13,28 +14,35 @@ import (
 type UniversityService interface {
 	AddUniversity(ctx context.Context, req dto.AddUniversityRequest) (*model.University, error)
 	UpdateUniversity(ctx context.Context, id int, req dto.UpdateUniversityRequest) (*model.University, error)
-	GetUniversityDetail(ctx context.Context, slug string) (*model.University, error)
+	GetUniversityDetail(ctx context.Context, slug string, page, limit int) (*dto.UniversityDetailResponse, error)
 	GetUniversities(ctx context.Context) ([]model.University, error)
 	AddReview(ctx context.Context, req dto.AddReviewRequest) (*model.Review, error)
 	GetUniversityReviews(ctx context.Context, universityID int) ([]model.Review, error)
+	VerifyUniversityMember(ctx context.Context, universityID, adminID, userID int) error
+	RevokeUniversityVerification(ctx context.Context, universityID, adminID, userID int) error
 }
 
 type UniversityServiceImpl struct {
 	UniversityRepository repository.UniversityRepository
 	ReviewRepository     repository.ReviewRepository
+	CommunityRepository  repository.CommunityRepository
+	UserRepository       repository.UserRepository
+	SlugService          SlugService
 }
 
-func NewUniversityService(universityRepo repository.UniversityRepository, reviewRepo repository.ReviewRepository) UniversityService {
+func NewUniversityService(universityRepo repository.UniversityRepository, reviewRepo repository.ReviewRepository, communityRepo repository.CommunityRepository, userRepo repository.UserRepository, slugService SlugService) UniversityService {
 	return &UniversityServiceImpl{
 		UniversityRepository: universityRepo,
 		ReviewRepository:     reviewRepo,
+		CommunityRepository:  communityRepo,
+		UserRepository:       userRepo,
+		SlugService:          slugService,
 	}
 }
 
 func (s *UniversityServiceImpl) AddUniversity(ctx context.Context, req dto.AddUniversityRequest) (*model.University, error) {
 	university := model.University{
 		Name:          req.Name,
-		Slug:          strings.ReplaceAll(strings.ToLower(req.Name), " ", "_"),
 		Summary:       req.Summary,
 		LocationID:    req.LocationID,
 		Website:       req.Website,
@@ -47,7 +55,11 @@ func (s *UniversityServiceImpl) AddUniversity(ctx context.Context, req dto.AddUn
 		Accreditation: req.Accreditation,
 	}
 
-	if err := s.UniversityRepository.CreateUniversity(ctx, &university); err != nil {
+	err := s.SlugService.CreateWithSlug(ctx, constant.SlugEntityUniversity, req.Name, func(slug string) error {
+		university.Slug = slug
+		return s.UniversityRepository.CreateUniversity(ctx, &university)
+	})
+	if err != nil {
 		return nil, errors.New("failed to create university")
 	}
 
@@ -60,8 +72,9 @@ func (s *UniversityServiceImpl) UpdateUniversity(ctx context.Context, id int, re
 		return nil, errors.New("university not found")
 	}
 
+	renamed := req.Name != "" && req.Name != existing.Name
+
 	existing.Name = req.Name
-	existing.Slug = strings.ReplaceAll(strings.ToLower(req.Name), " ", "_")
 	existing.Summary = req.Summary
 	existing.LocationID = req.LocationID
 	existing.Website = req.Website
@@ -77,15 +90,45 @@ func (s *UniversityServiceImpl) UpdateUniversity(ctx context.Context, id int, re
 		return nil, errors.New("failed to update university")
 	}
 
+	if renamed {
+		if existing.Slug, err = s.SlugService.ChangeSlug(ctx, constant.SlugEntityUniversity, id, req.Name); err != nil {
+			return nil, errors.New("failed to update university slug")
+		}
+	}
+
 	return existing, nil
 }
 
-func (s *UniversityServiceImpl) GetUniversityDetail(ctx context.Context, slug string) (*model.University, error) {
+// GetUniversityDetail returns a university with a page of the communities linked to it,
+// largest first. A slug the university used to have yields a SlugMovedError.
+func (s *UniversityServiceImpl) GetUniversityDetail(ctx context.Context, slug string, page, limit int) (*dto.UniversityDetailResponse, error) {
 	university, err := s.UniversityRepository.GetUniversityBySlug(ctx, slug)
 	if err != nil {
-		return nil, errors.New("university not found")
+		id, redirectErr := s.SlugService.GetRedirect(ctx, constant.SlugEntityUniversity, slug)
+		if redirectErr != nil {
+			return nil, errors.New("university not found")
+		}
+		if university, err = s.UniversityRepository.GetUniversityByID(ctx, id); err != nil {
+			return nil, errors.New("university not found")
+		}
+		return nil, &SlugMovedError{Slug: university.Slug}
+	}
+
+	filter := repository.CommunityFilter{UniversityID: university.ID}
+	communities, total, err := s.CommunityRepository.SearchCommunities(ctx, filter, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.New("error retrieving university communities")
 	}
-	return university, nil
+
+	return &dto.UniversityDetailResponse{
+		University: university,
+		Communities: dto.CommunityPage{
+			Data:  communities,
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	}, nil
 }
 
 func (s *UniversityServiceImpl) GetUniversities(ctx context.Context) ([]model.University, error) {
@@ -135,3 +178,55 @@ func (s *UniversityServiceImpl) AddReview(ctx context.Context, req dto.AddReview
 func (s *UniversityServiceImpl) GetUniversityReviews(ctx context.Context, universityID int) ([]model.Review, error) {
 	return s.ReviewRepository.GetReviewsByUniversityID(ctx, universityID)
 }
+
+// VerifyUniversityMember marks userID as a verified member of the university, which lets
+// them read university-verified posts. Only admins verify members; a user is verified at
+// one university at a time, so verifying them elsewhere replaces the previous one.
+func (s *UniversityServiceImpl) VerifyUniversityMember(ctx context.Context, universityID, adminID, userID int) error {
+	if err := s.checkAdmin(ctx, adminID); err != nil {
+		return err
+	}
+	if _, err := s.UniversityRepository.GetUniversityByID(ctx, universityID); err != nil {
+		return errors.New("university not found")
+	}
+	if _, err := s.UserRepository.GetUserByID(ctx, userID); err != nil {
+		return errors.New("user not found")
+	}
+
+	now := time.Now()
+	if err := s.UserRepository.SetUniversityVerification(ctx, userID, &universityID, &now); err != nil {
+		return errors.New("failed to verify university member")
+	}
+	return nil
+}
+
+// RevokeUniversityVerification removes the verification VerifyUniversityMember recorded
+// for userID at the university.
+func (s *UniversityServiceImpl) RevokeUniversityVerification(ctx context.Context, universityID, adminID, userID int) error {
+	if err := s.checkAdmin(ctx, adminID); err != nil {
+		return err
+	}
+	user, err := s.UserRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.UniversityID == nil || *user.UniversityID != universityID || user.UniversityVerifiedAt == nil {
+		return errors.New("user is not verified at this university")
+	}
+
+	if err := s.UserRepository.SetUniversityVerification(ctx, userID, nil, nil); err != nil {
+		return errors.New("failed to revoke university verification")
+	}
+	return nil
+}
+
+func (s *UniversityServiceImpl) checkAdmin(ctx context.Context, userID int) error {
+	user, err := s.UserRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.Role != constant.UserRoleAdmin {
+		return newError(constant.UserErrorAdminOnly, "only admins can verify university members")
+	}
+	return nil
+}
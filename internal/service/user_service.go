@@ -17,6 +17,8 @@ type UserService interface {
 	UpdateUser(ctx context.Context, data dto.UpdateUserDTO) error
 	FollowUser(ctx context.Context, data dto.FollowUserDTO) error
 	GetFollowers(ctx context.Context, data dto.GetFollowersDTO) ([]model.UserFollow, error)
+	BlockUser(ctx context.Context, data dto.BlockUserDTO) error
+	UnblockUser(ctx context.Context, data dto.BlockUserDTO) error
 }
 
 type UserServiceImpl struct {
@@ -115,3 +117,32 @@ func (s *UserServiceImpl) GetFollowers(ctx context.Context, data dto.GetFollower
 
 	return followers, nil
 }
+
+func (s *UserServiceImpl) BlockUser(ctx context.Context, data dto.BlockUserDTO) error {
+	if data.TargetID == data.CurrentUserID {
+		return errors.New("cannot block yourself")
+	}
+
+	if _, err := s.UserRepository.GetUserByID(ctx, data.TargetID); err != nil {
+		return errors.New("target user not found")
+	}
+
+	newBlock := model.UserBlock{
+		BlockerID: data.CurrentUserID,
+		BlockedID: data.TargetID,
+	}
+
+	if err := s.UserRepository.CreateUserBlock(ctx, &newBlock); err != nil {
+		return errors.New("error blocking user")
+	}
+
+	return nil
+}
+
+func (s *UserServiceImpl) UnblockUser(ctx context.Context, data dto.BlockUserDTO) error {
+	if err := s.UserRepository.DeleteUserBlock(ctx, data.CurrentUserID, data.TargetID); err != nil {
+		return errors.New("error unblocking user")
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"strings"
@@ -8,6 +9,10 @@ import (
 	"github.com/dgrijalva/jwt-go"
 )
 
+type contextKey string
+
+const userIDKey contextKey = "user_id"
+
 func CheckAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -18,7 +23,7 @@ func CheckAuth(next http.Handler) http.Handler {
 
 		tokenString := strings.Split(authHeader, " ")[1]
 
-		claims := &jwt.StandardClaims{}
+		claims := jwt.MapClaims{}
 
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 			return []byte(os.Getenv("JWT_SECRET_KEY")), nil
@@ -29,6 +34,20 @@ func CheckAuth(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		// Login signs the user's ID into the "id" claim; JSON numbers decode as float64.
+		userID, ok := claims["id"].(float64)
+		if !ok || userID <= 0 {
+			http.Error(w, "Token not valid", http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDKey, int(userID))
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// UserIDFromContext returns the ID of the user whose token CheckAuth accepted.
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(userIDKey).(int)
+	return userID, ok
+}
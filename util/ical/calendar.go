@@ -0,0 +1,122 @@
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type calendar feeds are served with.
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	productID     = "-//Temuka//Community Events//EN"
+	maxLineOctets = 75
+	timeLayout    = "20060102T150405Z"
+)
+
+// Calendar is an iCalendar (RFC 5545) feed of events.
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Event is a single VEVENT. Times are written in UTC, which every calendar client converts
+// to the zone of its user; TimeZone is only passed along as a hint.
+type Event struct {
+	UID          string
+	Sequence     int
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Start        time.Time
+	End          time.Time
+	TimeZone     string
+	Cancelled    bool
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Encode returns the calendar in iCalendar format, with CRLF line endings and long lines
+// folded as the format requires.
+func (c Calendar) Encode() []byte {
+	var b strings.Builder
+	now := time.Now()
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+productID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+escapeText(e.UID))
+		writeLine(&b, "DTSTAMP:"+formatTime(now))
+		writeLine(&b, "SEQUENCE:"+strconv.Itoa(e.Sequence))
+		writeLine(&b, "DTSTART:"+formatTime(e.Start))
+		writeLine(&b, "DTEND:"+formatTime(e.End))
+		writeLine(&b, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Location != "" {
+			writeLine(&b, "LOCATION:"+escapeText(e.Location))
+		}
+		if e.URL != "" {
+			writeLine(&b, "URL:"+e.URL)
+		}
+		if e.TimeZone != "" {
+			writeLine(&b, "X-TEMUKA-TIMEZONE:"+escapeText(e.TimeZone))
+		}
+		if e.Cancelled {
+			writeLine(&b, "STATUS:CANCELLED")
+		} else {
+			writeLine(&b, "STATUS:CONFIRMED")
+		}
+		if !e.Created.IsZero() {
+			writeLine(&b, "CREATED:"+formatTime(e.Created))
+		}
+		if !e.LastModified.IsZero() {
+			writeLine(&b, "LAST-MODIFIED:"+formatTime(e.LastModified))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeLine writes a content line, folding it into continuation lines that start with a
+// space so that no line is longer than 75 octets. Lines are never split inside a rune.
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space.
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -3,8 +3,10 @@ package key_value_store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -72,3 +74,61 @@ func (r *RedisWrapper) AddToSet(key string, value string) error {
 func (r *RedisWrapper) RemoveFromSet(key string, value string) error {
 	return r.Client.SRem(r.Ctx, key, value).Err()
 }
+
+// AcquireLock sets key only if it does not exist yet and reports whether the caller now holds it.
+func (r *RedisWrapper) AcquireLock(key string, value string, ttl time.Duration) (bool, error) {
+	return r.Client.SetNX(r.Ctx, key, value, ttl).Result()
+}
+
+// ReleaseLock deletes key only if it still holds value, so an expired lock taken over by another holder is left alone.
+func (r *RedisWrapper) ReleaseLock(key string, value string) error {
+	script := redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		end
+		return 0
+	`)
+	return script.Run(r.Ctx, r.Client, []string{key}, value).Err()
+}
+
+func (r *RedisWrapper) IncrementHashField(key string, field string, delta int64) error {
+	return r.Client.HIncrBy(r.Ctx, key, field, delta).Err()
+}
+
+// TakeHash atomically moves the hash at key out of the way and returns its fields,
+// so increments that land while the caller processes it start a fresh hash.
+func (r *RedisWrapper) TakeHash(key string) (map[string]string, error) {
+	tmpKey := key + ":taken"
+
+	if err := r.Client.Rename(r.Ctx, key, tmpKey).Err(); err != nil {
+		// RENAME of a missing key is a server error reply, not redis.Nil.
+		var redisErr redis.Error
+		if errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "ERR no such key") {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	values, err := r.Client.HGetAll(r.Ctx, tmpKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return values, r.Client.Del(r.Ctx, tmpKey).Err()
+}
+
+func (r *RedisWrapper) PopFromSet(key string, count int64) ([]string, error) {
+	return r.Client.SPopN(r.Ctx, key, count).Result()
+}
+
+func (r *RedisWrapper) AddToHyperLogLog(key string, values ...string) error {
+	elements := make([]interface{}, len(values))
+	for i, v := range values {
+		elements[i] = v
+	}
+	return r.Client.PFAdd(r.Ctx, key, elements...).Err()
+}
+
+func (r *RedisWrapper) CountHyperLogLog(key string) (int64, error) {
+	return r.Client.PFCount(r.Ctx, key).Result()
+}
@@ -0,0 +1,197 @@
+package link_preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultTimeout      = 5 * time.Second
+	DefaultMaxBodyBytes = 1 << 20
+	DefaultMaxRedirects = 3
+	DefaultUserAgent    = "TemukaLinkPreview/1.0"
+)
+
+var (
+	ErrBlockedAddress     = errors.New("link preview: address is not allowed")
+	ErrUnsupportedScheme  = errors.New("link preview: only http and https urls are supported")
+	ErrUnsupportedContent = errors.New("link preview: response is not an html document")
+	ErrTooManyRedirects   = errors.New("link preview: too many redirects")
+)
+
+type Config struct {
+	Timeout      time.Duration
+	MaxBodyBytes int64
+	MaxRedirects int
+	UserAgent    string
+	// AllowPrivateNetworks disables the SSRF guard. It exists for tests that
+	// run against a local HTTP server and must never be set in production.
+	AllowPrivateNetworks bool
+}
+
+type Fetcher struct {
+	client       *http.Client
+	maxBodyBytes int64
+	userAgent    string
+}
+
+// NewFetcher builds a Fetcher whose HTTP client refuses to connect to private,
+// loopback and link-local addresses. The check runs on the resolved address of
+// every connection, redirects included, so DNS tricks cannot bypass it.
+func NewFetcher(cfg Config) *Fetcher {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if cfg.MaxRedirects <= 0 {
+		cfg.MaxRedirects = DefaultMaxRedirects
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return ErrBlockedAddress
+			}
+			if ip := net.ParseIP(host); ip == nil || IsBlockedIP(ip) {
+				return ErrBlockedAddress
+			}
+			return nil
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   cfg.Timeout,
+		ResponseHeaderTimeout: cfg.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	maxRedirects := cfg.MaxRedirects
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return ErrTooManyRedirects
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return ErrUnsupportedScheme
+			}
+			return nil
+		},
+	}
+
+	return &Fetcher{
+		client:       client,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		userAgent:    cfg.UserAgent,
+	}
+}
+
+// Fetch downloads at most MaxBodyBytes of the page at rawURL and extracts its
+// preview metadata. Pages larger than the limit are parsed from their prefix,
+// which is where the <head> metadata lives.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("link preview: invalid url: %w", err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, ErrUnsupportedScheme
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("link preview: failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return nil, ErrBlockedAddress
+		}
+		return nil, fmt.Errorf("link preview: failed to fetch url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("link preview: unexpected status %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrUnsupportedContent
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("link preview: failed to read body: %w", err)
+	}
+
+	return ParseMetadata(resp.Request.URL, body), nil
+}
+
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+	"2001:db8::/32",
+)
+
+// IsBlockedIP reports whether ip points at a network a preview fetch must never reach.
+func IsBlockedIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
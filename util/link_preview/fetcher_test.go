@@ -0,0 +1,243 @@
+package link_preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveHTML(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchOpenGraph(t *testing.T) {
+	srv := serveHTML(t, `<html><head>
+		<title>Fallback title</title>
+		<meta property="og:title" content="OpenGraph &amp; title">
+		<meta property="og:description" content="OpenGraph description">
+		<meta property="og:image" content="/images/cover.png">
+		<meta property="og:site_name" content="Example">
+		<meta name="twitter:title" content="Twitter title">
+		<meta name="twitter:description" content="Twitter description">
+	</head></html>`)
+
+	meta, err := NewFetcher(Config{AllowPrivateNetworks: true}).Fetch(context.Background(), srv.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	want := Metadata{
+		URL:         srv.URL + "/article",
+		Title:       "OpenGraph & title",
+		Description: "OpenGraph description",
+		ImageURL:    srv.URL + "/images/cover.png",
+		SiteName:    "Example",
+	}
+	if *meta != want {
+		t.Errorf("Fetch() = %+v, want %+v", *meta, want)
+	}
+}
+
+func TestFetchTwitterCard(t *testing.T) {
+	srv := serveHTML(t, `<html><head>
+		<title>Fallback title</title>
+		<meta name="twitter:card" content="summary">
+		<meta name="twitter:title" content="Twitter title">
+		<meta name="twitter:description" content="Twitter description">
+		<meta name="twitter:image" content="https://cdn.example.com/card.png">
+		<meta name="twitter:site" content="@example">
+	</head></html>`)
+
+	meta, err := NewFetcher(Config{AllowPrivateNetworks: true}).Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	want := Metadata{
+		URL:         srv.URL,
+		Title:       "Twitter title",
+		Description: "Twitter description",
+		ImageURL:    "https://cdn.example.com/card.png",
+		SiteName:    "@example",
+	}
+	if *meta != want {
+		t.Errorf("Fetch() = %+v, want %+v", *meta, want)
+	}
+}
+
+func TestFetchRejectsNonHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewFetcher(Config{AllowPrivateNetworks: true}).Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrUnsupportedContent) {
+		t.Errorf("Fetch() error = %v, want %v", err, ErrUnsupportedContent)
+	}
+}
+
+func TestFetchBodyLimit(t *testing.T) {
+	const limit = 1024
+	srv := serveHTML(t, `<meta property="og:title" content="Inside the limit">`+
+		strings.Repeat(" ", 4*limit)+
+		`<meta property="og:description" content="Past the limit">`)
+
+	meta, err := NewFetcher(Config{MaxBodyBytes: limit, AllowPrivateNetworks: true}).Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if meta.Title != "Inside the limit" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Inside the limit")
+	}
+	if meta.Description != "" {
+		t.Errorf("Description = %q, want it cut off by the body limit", meta.Description)
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := NewFetcher(Config{Timeout: 100 * time.Millisecond, AllowPrivateNetworks: true}).Fetch(context.Background(), srv.URL)
+	if err == nil {
+		t.Fatal("Fetch() error = nil, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Fetch() took %v, want it to give up after the timeout", elapsed)
+	}
+}
+
+// redirectServer answers /hops/n with a redirect to /hops/n-1 and serves a page at /hops/0.
+func redirectServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hops, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hops/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if hops > 0 {
+			http.Redirect(w, r, "/hops/"+strconv.Itoa(hops-1), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<title>Landed</title>`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchRedirectLimit(t *testing.T) {
+	srv := redirectServer(t)
+	fetcher := NewFetcher(Config{MaxRedirects: 2, AllowPrivateNetworks: true})
+
+	meta, err := fetcher.Fetch(context.Background(), srv.URL+"/hops/2")
+	if err != nil {
+		t.Fatalf("Fetch() with 2 redirects error = %v", err)
+	}
+	if meta.URL != srv.URL+"/hops/0" || meta.Title != "Landed" {
+		t.Errorf("Fetch() = %+v, want the page at /hops/0", *meta)
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), srv.URL+"/hops/3"); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("Fetch() with 3 redirects error = %v, want %v", err, ErrTooManyRedirects)
+	}
+}
+
+func TestFetchBlocksPrivateNetworks(t *testing.T) {
+	srv := serveHTML(t, `<title>Internal</title>`)
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+
+	fetcher := NewFetcher(Config{Timeout: time.Second})
+	urls := []string{
+		srv.URL,
+		"http://localhost:" + port,
+		"http://10.0.0.1/",
+		"http://172.16.0.1/",
+		"http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://100.64.0.1/",
+		"http://0.0.0.0:" + port,
+	}
+	for _, u := range urls {
+		if _, err := fetcher.Fetch(context.Background(), u); !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("Fetch(%q) error = %v, want %v", u, err, ErrBlockedAddress)
+		}
+	}
+}
+
+func TestFetchBlocksRedirectToPrivateNetwork(t *testing.T) {
+	internal := serveHTML(t, `<title>Internal</title>`)
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(internal.URL, "http://"))
+	public := httptest.NewServer(http.RedirectHandler("http://localhost:"+port, http.StatusFound))
+	defer public.Close()
+
+	// public.test stands in for an external site: only its connection skips the guard,
+	// so the redirect to a loopback address is what must be refused.
+	fetcher := NewFetcher(Config{Timeout: time.Second})
+	transport := fetcher.client.Transport.(*http.Transport)
+	guarded := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if address == "public.test:80" {
+			return (&net.Dialer{}).DialContext(ctx, network, public.Listener.Addr().String())
+		}
+		return guarded(ctx, network, address)
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), "http://public.test/"); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Fetch() error = %v, want %v", err, ErrBlockedAddress)
+	}
+}
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.31.255.255", true},
+		{"192.168.0.10", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"224.0.0.1", true},
+		{"::1", true},
+		{"fc00::1", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"93.184.216.34", false},
+		{"8.8.8.8", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsBlockedIP(net.ParseIP(tt.ip)); got != tt.blocked {
+			t.Errorf("IsBlockedIP(%s) = %v, want %v", tt.ip, got, tt.blocked)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package link_preview
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxSiteNameLength    = 100
+)
+
+type Metadata struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	SiteName    string `json:"site_name"`
+}
+
+// Empty reports whether nothing worth rendering was found on the page.
+func (m *Metadata) Empty() bool {
+	return m.Title == "" && m.Description == "" && m.ImageURL == ""
+}
+
+var (
+	metaTagPattern   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attributePattern = regexp.MustCompile(`(?is)([a-z_:.-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titlePattern     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// ParseMetadata extracts OpenGraph and Twitter card metadata from an HTML document,
+// falling back to the standard <title> and description tags. OpenGraph wins over
+// Twitter cards when both are present. Relative image URLs are resolved against pageURL.
+func ParseMetadata(pageURL *url.URL, body []byte) *Metadata {
+	tags := make(map[string]string)
+
+	for _, tag := range metaTagPattern.FindAll(body, -1) {
+		attrs := parseAttributes(tag)
+
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		content := strings.TrimSpace(html.UnescapeString(attrs["content"]))
+		if key == "" || content == "" {
+			continue
+		}
+		if _, exists := tags[key]; !exists {
+			tags[key] = content
+		}
+	}
+
+	meta := &Metadata{
+		URL:         pageURL.String(),
+		Title:       firstNonEmpty(tags["og:title"], tags["twitter:title"]),
+		Description: firstNonEmpty(tags["og:description"], tags["twitter:description"], tags["description"]),
+		ImageURL:    firstNonEmpty(tags["og:image:secure_url"], tags["og:image"], tags["twitter:image"], tags["twitter:image:src"]),
+		SiteName:    firstNonEmpty(tags["og:site_name"], tags["twitter:site"]),
+	}
+
+	if meta.Title == "" {
+		if match := titlePattern.FindSubmatch(body); match != nil {
+			meta.Title = strings.TrimSpace(html.UnescapeString(string(match[1])))
+		}
+	}
+
+	if meta.ImageURL != "" {
+		meta.ImageURL = resolveImageURL(pageURL, meta.ImageURL)
+	}
+
+	meta.Title = truncate(collapseSpaces(meta.Title), maxTitleLength)
+	meta.Description = truncate(collapseSpaces(meta.Description), maxDescriptionLength)
+	meta.SiteName = truncate(collapseSpaces(meta.SiteName), maxSiteNameLength)
+
+	return meta
+}
+
+func parseAttributes(tag []byte) map[string]string {
+	attrs := make(map[string]string)
+
+	for _, match := range attributePattern.FindAllSubmatch(tag, -1) {
+		name := strings.ToLower(string(match[1]))
+		value := string(match[2])
+		if value == "" {
+			value = string(match[3])
+		}
+		if value == "" {
+			value = string(match[4])
+		}
+		attrs[name] = value
+	}
+
+	return attrs
+}
+
+// resolveImageURL only keeps http(s) images so a preview never embeds e.g. a javascript: URL.
+func resolveImageURL(pageURL *url.URL, raw string) string {
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	resolved := pageURL.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+
+	return resolved.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
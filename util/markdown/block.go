@@ -0,0 +1,508 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Deeper blockquotes and lists are kept as paragraph text so hostile input cannot
+// make parsing and rendering recurse without bound.
+const maxNestingDepth = 16
+
+// Parse builds the document tree of a CommonMark source. Raw HTML and link reference
+// definitions are not supported and are kept as text.
+func Parse(source string) *Node {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\x00", "\uFFFD")
+	if !utf8.ValidString(source) {
+		source = strings.ToValidUTF8(source, "\uFFFD")
+	}
+
+	lines := strings.Split(source, "\n")
+	for i := range lines {
+		lines[i] = expandLeadingTabs(lines[i])
+	}
+
+	doc := &Node{Type: NodeDocument}
+	parseBlocks(doc, lines, 0)
+	return doc
+}
+
+// parseBlocks appends the blocks found in lines to parent and reports whether two of
+// them were separated by a blank line, which makes a list item loose.
+func parseBlocks(parent *Node, lines []string, depth int) bool {
+	var para []string
+	sawBlank := false
+	loose := false
+
+	add := func(n *Node) {
+		if sawBlank && parent.lastChild != nil {
+			loose = true
+		}
+		parent.appendChild(n)
+		sawBlank = false
+	}
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		p := &Node{Type: NodeParagraph}
+		parseInlines(p, strings.TrimRight(strings.Join(para, "\n"), " \t"))
+		para = nil
+		add(p)
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		if isBlank(line) {
+			flush()
+			sawBlank = true
+			i++
+			continue
+		}
+
+		if indentOf(line) >= 4 {
+			if len(para) > 0 {
+				para = append(para, strings.TrimLeft(line, " "))
+				i++
+				continue
+			}
+			var code []string
+			for i < len(lines) && (isBlank(lines[i]) || indentOf(lines[i]) >= 4) {
+				code = append(code, removeIndent(lines[i], 4))
+				i++
+			}
+			trailingBlank := false
+			for len(code) > 0 && isBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+				trailingBlank = true
+			}
+			add(&Node{Type: NodeCodeBlock, Literal: strings.Join(code, "\n") + "\n"})
+			sawBlank = trailingBlank
+			continue
+		}
+
+		if len(para) > 0 {
+			if level := setextLevel(line); level > 0 {
+				h := &Node{Type: NodeHeading, Level: level}
+				parseInlines(h, strings.TrimSpace(strings.Join(para, "\n")))
+				para = nil
+				add(h)
+				i++
+				continue
+			}
+		}
+
+		if f, ok := openFence(line); ok {
+			flush()
+			var code *Node
+			code, i = parseFencedCode(lines, i, f)
+			add(code)
+			continue
+		}
+
+		if level, content, ok := atxHeading(line); ok {
+			flush()
+			h := &Node{Type: NodeHeading, Level: level}
+			parseInlines(h, content)
+			add(h)
+			i++
+			continue
+		}
+
+		if isThematicBreak(line) {
+			flush()
+			add(&Node{Type: NodeThematicBreak})
+			i++
+			continue
+		}
+
+		if depth < maxNestingDepth {
+			if isBlockQuoteStart(line) {
+				flush()
+				var quote *Node
+				quote, i = parseBlockQuote(lines, i, depth)
+				add(quote)
+				continue
+			}
+
+			if m, ok := listMarkerAt(line); ok && (len(para) == 0 || m.canInterruptParagraph()) {
+				flush()
+				var list *Node
+				var endedBlank bool
+				list, i, endedBlank = parseList(lines, i, depth)
+				add(list)
+				sawBlank = endedBlank
+				continue
+			}
+		}
+
+		para = append(para, strings.TrimLeft(line, " "))
+		i++
+	}
+	flush()
+
+	return loose
+}
+
+type fence struct {
+	char   byte
+	length int
+	indent int
+	info   string
+}
+
+func openFence(line string) (fence, bool) {
+	indent := indentOf(line)
+	if indent > 3 {
+		return fence{}, false
+	}
+	rest := line[indent:]
+	if rest == "" || (rest[0] != '`' && rest[0] != '~') {
+		return fence{}, false
+	}
+
+	n := runLength(rest, rest[0])
+	if n < 3 {
+		return fence{}, false
+	}
+	info := strings.TrimSpace(rest[n:])
+	if rest[0] == '`' && strings.Contains(info, "`") {
+		return fence{}, false
+	}
+
+	return fence{char: rest[0], length: n, indent: indent, info: unescapeString(info)}, true
+}
+
+// parseFencedCode returns the code block opened at lines[i] and the index of the first
+// line after it. An unclosed fence runs to the end of the document.
+func parseFencedCode(lines []string, i int, f fence) (*Node, int) {
+	var code []string
+
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if indentOf(line) <= 3 {
+			rest := strings.TrimLeft(line, " ")
+			if n := runLength(rest, f.char); n >= f.length && isBlank(rest[n:]) {
+				i++
+				break
+			}
+		}
+		code = append(code, removeIndent(line, f.indent))
+	}
+
+	literal := strings.Join(code, "\n")
+	if len(code) > 0 {
+		literal += "\n"
+	}
+
+	info := ""
+	if fields := strings.Fields(f.info); len(fields) > 0 {
+		info = fields[0]
+	}
+
+	return &Node{Type: NodeCodeBlock, Literal: literal, Info: info}, i
+}
+
+func atxHeading(line string) (int, string, bool) {
+	indent := indentOf(line)
+	if indent > 3 {
+		return 0, "", false
+	}
+	rest := line[indent:]
+
+	level := runLength(rest, '#')
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	if level < len(rest) && rest[level] != ' ' && rest[level] != '\t' {
+		return 0, "", false
+	}
+
+	content := strings.TrimSpace(rest[level:])
+	stripped := strings.TrimRight(content, "#")
+	if stripped == "" {
+		content = ""
+	} else if len(stripped) < len(content) && (stripped[len(stripped)-1] == ' ' || stripped[len(stripped)-1] == '\t') {
+		content = strings.TrimSpace(stripped)
+	}
+
+	return level, content, true
+}
+
+func setextLevel(line string) int {
+	if indentOf(line) > 3 {
+		return 0
+	}
+	rest := strings.TrimSpace(line)
+	switch {
+	case rest == "":
+		return 0
+	case runLength(rest, '=') == len(rest):
+		return 1
+	case runLength(rest, '-') == len(rest):
+		return 2
+	}
+	return 0
+}
+
+func isThematicBreak(line string) bool {
+	if indentOf(line) > 3 {
+		return false
+	}
+	rest := strings.TrimSpace(line)
+	if rest == "" || (rest[0] != '*' && rest[0] != '-' && rest[0] != '_') {
+		return false
+	}
+
+	count := 0
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case rest[0]:
+			count++
+		case ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+func isBlockQuoteStart(line string) bool {
+	indent := indentOf(line)
+	return indent <= 3 && indent < len(line) && line[indent] == '>'
+}
+
+func parseBlockQuote(lines []string, i, depth int) (*Node, int) {
+	var inner []string
+
+	for i < len(lines) {
+		line := lines[i]
+		if isBlockQuoteStart(line) {
+			rest := line[indentOf(line)+1:]
+			rest = strings.TrimPrefix(rest, " ")
+			inner = append(inner, rest)
+			i++
+			continue
+		}
+		// A paragraph inside the quote may continue on a line without the marker.
+		if len(inner) > 0 && !isBlank(inner[len(inner)-1]) && !interruptsParagraph(line) {
+			inner = append(inner, line)
+			i++
+			continue
+		}
+		break
+	}
+
+	quote := &Node{Type: NodeBlockQuote}
+	parseBlocks(quote, inner, depth+1)
+	return quote, i
+}
+
+type listMarker struct {
+	ordered bool
+	char    byte
+	start   int
+	width   int
+	empty   bool
+}
+
+// An empty item or an ordered list not starting at 1 cannot interrupt a paragraph,
+// so a wrapped line such as "2020. was a good year" continues the paragraph above it.
+func (m listMarker) canInterruptParagraph() bool {
+	return !m.empty && (!m.ordered || m.start == 1)
+}
+
+func listMarkerAt(line string) (listMarker, bool) {
+	indent := indentOf(line)
+	if indent > 3 {
+		return listMarker{}, false
+	}
+	rest := line[indent:]
+	if rest == "" {
+		return listMarker{}, false
+	}
+
+	var m listMarker
+	markerLen := 0
+	switch rest[0] {
+	case '-', '+', '*':
+		m.char = rest[0]
+		markerLen = 1
+	default:
+		digits := 0
+		for digits < len(rest) && digits < 10 && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 || digits > 9 || digits >= len(rest) || (rest[digits] != '.' && rest[digits] != ')') {
+			return listMarker{}, false
+		}
+		m.ordered = true
+		m.start, _ = strconv.Atoi(rest[:digits])
+		m.char = rest[digits]
+		markerLen = digits + 1
+	}
+
+	after := rest[markerLen:]
+	if isBlank(after) {
+		m.empty = true
+		m.width = indent + markerLen + 1
+		return m, true
+	}
+	if after[0] != ' ' {
+		return listMarker{}, false
+	}
+
+	spaces := indentOf(after)
+	if spaces > 4 {
+		// The content is an indented code block; only one space belongs to the marker.
+		spaces = 1
+	}
+	m.width = indent + markerLen + spaces
+	return m, true
+}
+
+// parseList consumes consecutive items of the same list type starting at lines[i]. It
+// also reports whether the list ended on blank lines.
+func parseList(lines []string, i, depth int) (*Node, int, bool) {
+	first, _ := listMarkerAt(lines[i])
+	list := &Node{Type: NodeList, Ordered: first.ordered, Start: first.start, Tight: true}
+	endedBlank := false
+
+	for i < len(lines) {
+		m, ok := listMarkerAt(lines[i])
+		if !ok || m.ordered != first.ordered || m.char != first.char || isThematicBreak(lines[i]) {
+			break
+		}
+		if endedBlank {
+			list.Tight = false
+		}
+
+		var itemLines []string
+		itemLines, i, endedBlank = collectListItem(lines, i, m)
+
+		item := &Node{Type: NodeItem}
+		if parseBlocks(item, itemLines, depth+1) {
+			list.Tight = false
+		}
+		list.appendChild(item)
+	}
+
+	return list, i, endedBlank
+}
+
+func collectListItem(lines []string, i int, m listMarker) ([]string, int, bool) {
+	first := lines[i]
+	content := ""
+	if !m.empty {
+		content = first[m.width:]
+	}
+	item := []string{content}
+
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			// An item can start with at most one blank line.
+			if m.empty && len(item) == 1 {
+				break
+			}
+			item = append(item, "")
+			continue
+		}
+		if indentOf(line) >= m.width {
+			item = append(item, line[m.width:])
+			continue
+		}
+		if _, ok := listMarkerAt(line); ok {
+			break
+		}
+		if !isBlank(item[len(item)-1]) && !interruptsParagraph(line) {
+			item = append(item, line)
+			continue
+		}
+		break
+	}
+
+	trailingBlank := false
+	for len(item) > 0 && isBlank(item[len(item)-1]) {
+		item = item[:len(item)-1]
+		trailingBlank = true
+	}
+
+	return item, i, trailingBlank
+}
+
+// interruptsParagraph reports whether line starts a new block instead of lazily
+// continuing the paragraph above it.
+func interruptsParagraph(line string) bool {
+	if isBlank(line) {
+		return true
+	}
+	if indentOf(line) >= 4 {
+		return false
+	}
+	if _, ok := openFence(line); ok {
+		return true
+	}
+	if _, _, ok := atxHeading(line); ok {
+		return true
+	}
+	if isThematicBreak(line) || isBlockQuoteStart(line) {
+		return true
+	}
+	if m, ok := listMarkerAt(line); ok && m.canInterruptParagraph() {
+		return true
+	}
+	return false
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return runLength(line, ' ')
+}
+
+func runLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func removeIndent(line string, n int) string {
+	for i := 0; i < n && len(line) > 0 && line[0] == ' '; i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// expandLeadingTabs replaces tabs in the indentation of line with spaces up to the
+// next multiple of four columns.
+func expandLeadingTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var b strings.Builder
+	col := 0
+	i := 0
+	for ; i < len(line) && (line[i] == ' ' || line[i] == '\t'); i++ {
+		if line[i] == ' ' {
+			b.WriteByte(' ')
+			col++
+			continue
+		}
+		for w := 4 - col%4; w > 0; w-- {
+			b.WriteByte(' ')
+			col++
+		}
+	}
+	b.WriteString(line[i:])
+	return b.String()
+}
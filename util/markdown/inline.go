@@ -0,0 +1,538 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	entityPattern       = regexp.MustCompile(`^&(?:#[xX][0-9a-fA-F]{1,6}|#[0-9]{1,7}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	autolinkURIPattern  = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.\-]{1,31}:[^<>\x00-\x20]*)>`)
+	autolinkMailPattern = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+)
+
+const maxLinkParenDepth = 32
+
+// A delimiter is a run of * or _ that may open or close emphasis.
+type delimiter struct {
+	node      *Node
+	char      byte
+	count     int
+	origCount int
+	canOpen   bool
+	canClose  bool
+	prev      *delimiter
+	next      *delimiter
+}
+
+// A bracket is a [ or ![ that may start a link or an image.
+type bracket struct {
+	node      *Node
+	image     bool
+	active    bool
+	prevDelim *delimiter
+	prev      *bracket
+}
+
+// inlineParser follows the delimiter stack strategy of the CommonMark spec, without
+// reference links and raw HTML.
+type inlineParser struct {
+	src      string
+	pos      int
+	parent   *Node
+	delims   *delimiter
+	brackets *bracket
+	// noCloser remembers backtick run lengths with no closing run left in src,
+	// which keeps unbalanced code spans linear.
+	noCloser map[int]bool
+}
+
+func parseInlines(parent *Node, src string) {
+	p := &inlineParser{src: src, parent: parent, noCloser: make(map[int]bool)}
+
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; c {
+		case '\n':
+			p.parseNewline()
+		case '\\':
+			p.parseBackslash()
+		case '`':
+			p.parseCodeSpan()
+		case '*', '_':
+			p.parseDelimiterRun(c)
+		case '[':
+			p.pos++
+			p.pushBracket(p.text("["), false)
+		case '!':
+			if p.pos+1 < len(p.src) && p.src[p.pos+1] == '[' {
+				p.pos += 2
+				p.pushBracket(p.text("!["), true)
+			} else {
+				p.pos++
+				p.text("!")
+			}
+		case ']':
+			p.parseCloseBracket()
+		case '<':
+			p.parseAutolink()
+		case '&':
+			p.parseEntity()
+		default:
+			p.parseString()
+		}
+	}
+
+	p.processEmphasis(nil)
+}
+
+func (p *inlineParser) text(s string) *Node {
+	n := &Node{Type: NodeText, Literal: s}
+	p.parent.appendChild(n)
+	return n
+}
+
+func (p *inlineParser) parseString() {
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune("\n\\`*_[]!<&", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	p.text(p.src[start:p.pos])
+}
+
+// parseNewline turns a line ending into a hard break when the line ends with two or
+// more spaces and into a soft break otherwise.
+func (p *inlineParser) parseNewline() {
+	p.pos++
+
+	hard := false
+	if last := p.parent.lastChild; last != nil && last.Type == NodeText {
+		trimmed := strings.TrimRight(last.Literal, " ")
+		hard = len(last.Literal)-len(trimmed) >= 2
+		last.Literal = trimmed
+	}
+
+	if hard {
+		p.parent.appendChild(&Node{Type: NodeHardBreak})
+	} else {
+		p.parent.appendChild(&Node{Type: NodeSoftBreak})
+	}
+	p.skipSpaces()
+}
+
+func (p *inlineParser) parseBackslash() {
+	p.pos++
+	if p.pos < len(p.src) && p.src[p.pos] == '\n' {
+		p.pos++
+		p.parent.appendChild(&Node{Type: NodeHardBreak})
+		p.skipSpaces()
+		return
+	}
+	if p.pos < len(p.src) && isASCIIPunct(p.src[p.pos]) {
+		p.text(p.src[p.pos : p.pos+1])
+		p.pos++
+		return
+	}
+	p.text("\\")
+}
+
+func (p *inlineParser) parseCodeSpan() {
+	start := p.pos
+	n := runLength(p.src[p.pos:], '`')
+	p.pos += n
+
+	if !p.noCloser[n] {
+		for i := p.pos; i < len(p.src); {
+			if p.src[i] != '`' {
+				i++
+				continue
+			}
+			run := runLength(p.src[i:], '`')
+			if run == n {
+				content := strings.ReplaceAll(p.src[p.pos:i], "\n", " ")
+				if len(content) >= 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.Trim(content, " ") != "" {
+					content = content[1 : len(content)-1]
+				}
+				p.parent.appendChild(&Node{Type: NodeCode, Literal: content})
+				p.pos = i + run
+				return
+			}
+			i += run
+		}
+		p.noCloser[n] = true
+	}
+
+	p.text(p.src[start:p.pos])
+}
+
+func (p *inlineParser) parseDelimiterRun(c byte) {
+	start := p.pos
+	p.pos += runLength(p.src[p.pos:], c)
+
+	before := ' '
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.src[:start])
+	}
+	after := ' '
+	if p.pos < len(p.src) {
+		after, _ = utf8.DecodeRuneInString(p.src[p.pos:])
+	}
+
+	leftFlanking := !unicode.IsSpace(after) &&
+		(!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	rightFlanking := !unicode.IsSpace(before) &&
+		(!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	canOpen, canClose := leftFlanking, rightFlanking
+	if c == '_' {
+		canOpen = leftFlanking && (!rightFlanking || isPunct(before))
+		canClose = rightFlanking && (!leftFlanking || isPunct(after))
+	}
+
+	n := p.pos - start
+	d := &delimiter{
+		node:      p.text(p.src[start:p.pos]),
+		char:      c,
+		count:     n,
+		origCount: n,
+		canOpen:   canOpen,
+		canClose:  canClose,
+		prev:      p.delims,
+	}
+	if p.delims != nil {
+		p.delims.next = d
+	}
+	p.delims = d
+}
+
+func (p *inlineParser) pushBracket(node *Node, image bool) {
+	p.brackets = &bracket{
+		node:      node,
+		image:     image,
+		active:    true,
+		prevDelim: p.delims,
+		prev:      p.brackets,
+	}
+}
+
+func (p *inlineParser) parseCloseBracket() {
+	p.pos++
+
+	opener := p.brackets
+	if opener == nil {
+		p.text("]")
+		return
+	}
+	if !opener.active {
+		p.brackets = opener.prev
+		p.text("]")
+		return
+	}
+
+	dest, title, end, ok := parseLinkTail(p.src, p.pos)
+	if !ok {
+		p.brackets = opener.prev
+		p.text("]")
+		return
+	}
+	p.pos = end
+
+	link := &Node{Type: NodeLink, Destination: dest, Title: title}
+	if opener.image {
+		link.Type = NodeImage
+	}
+	for n := opener.node.next; n != nil; {
+		next := n.next
+		link.appendChild(n)
+		n = next
+	}
+	p.parent.appendChild(link)
+
+	p.processEmphasis(opener.prevDelim)
+	opener.node.unlink()
+	p.brackets = opener.prev
+
+	// Links may not contain other links.
+	if !opener.image {
+		for b := p.brackets; b != nil; b = b.prev {
+			if !b.image {
+				b.active = false
+			}
+		}
+	}
+}
+
+// parseLinkTail parses the (destination "title") part of an inline link starting at pos.
+func parseLinkTail(src string, pos int) (string, string, int, bool) {
+	if pos >= len(src) || src[pos] != '(' {
+		return "", "", 0, false
+	}
+	i := skipWhitespace(src, pos+1)
+
+	var rawDest string
+	if i < len(src) && src[i] == '<' {
+		j := i + 1
+		for j < len(src) && src[j] != '>' && src[j] != '\n' && src[j] != '<' {
+			if src[j] == '\\' && j+1 < len(src) {
+				j++
+			}
+			j++
+		}
+		if j >= len(src) || src[j] != '>' {
+			return "", "", 0, false
+		}
+		rawDest = src[i+1 : j]
+		i = j + 1
+	} else {
+		j := i
+		depth := 0
+	scan:
+		for j < len(src) {
+			switch c := src[j]; {
+			case c == '\\' && j+1 < len(src) && isASCIIPunct(src[j+1]):
+				j += 2
+				continue
+			case c <= ' ' || c == 0x7f:
+				break scan
+			case c == '(':
+				depth++
+				if depth > maxLinkParenDepth {
+					return "", "", 0, false
+				}
+			case c == ')':
+				if depth == 0 {
+					break scan
+				}
+				depth--
+			}
+			j++
+		}
+		if depth != 0 {
+			return "", "", 0, false
+		}
+		rawDest = src[i:j]
+		i = j
+	}
+
+	title := ""
+	k := skipWhitespace(src, i)
+	if k > i && k < len(src) && (src[k] == '"' || src[k] == '\'' || src[k] == '(') {
+		closing := src[k]
+		if closing == '(' {
+			closing = ')'
+		}
+		m := k + 1
+		for m < len(src) && src[m] != closing {
+			if src[m] == '\\' && m+1 < len(src) {
+				m++
+			} else if closing == ')' && src[m] == '(' {
+				return "", "", 0, false
+			}
+			m++
+		}
+		if m >= len(src) {
+			return "", "", 0, false
+		}
+		title = unescapeString(src[k+1 : m])
+		k = skipWhitespace(src, m+1)
+	}
+
+	if k >= len(src) || src[k] != ')' {
+		return "", "", 0, false
+	}
+
+	return unescapeString(rawDest), title, k + 1, true
+}
+
+func (p *inlineParser) parseAutolink() {
+	rest := p.src[p.pos:]
+
+	if m := autolinkURIPattern.FindStringSubmatch(rest); m != nil {
+		p.appendAutolink(m[1], m[1])
+		p.pos += len(m[0])
+		return
+	}
+	if m := autolinkMailPattern.FindStringSubmatch(rest); m != nil {
+		p.appendAutolink("mailto:"+m[1], m[1])
+		p.pos += len(m[0])
+		return
+	}
+
+	p.pos++
+	p.text("<")
+}
+
+func (p *inlineParser) appendAutolink(dest, label string) {
+	link := &Node{Type: NodeLink, Destination: dest}
+	link.appendChild(&Node{Type: NodeText, Literal: label})
+	p.parent.appendChild(link)
+}
+
+func (p *inlineParser) parseEntity() {
+	if m := entityPattern.FindString(p.src[p.pos:]); m != "" {
+		p.pos += len(m)
+		p.text(html.UnescapeString(m))
+		return
+	}
+	p.pos++
+	p.text("&")
+}
+
+// processEmphasis matches the delimiters above bottom into emphasis and strong nodes
+// and removes them from the stack.
+func (p *inlineParser) processEmphasis(bottom *delimiter) {
+	openersBottom := make(map[int]*delimiter)
+
+	closer := p.delims
+	for closer != nil && closer != bottom && closer.prev != bottom {
+		closer = closer.prev
+	}
+	if closer == bottom {
+		closer = nil
+	}
+
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+
+		key := int(closer.char)<<3 | closer.origCount%3
+		if closer.canOpen {
+			key |= 4
+		}
+
+		opener := closer.prev
+		found := false
+		for opener != nil && opener != bottom && opener != openersBottom[key] {
+			if opener.char == closer.char && opener.canOpen && !oddMatch(opener, closer) {
+				found = true
+				break
+			}
+			opener = opener.prev
+		}
+
+		if !found {
+			openersBottom[key] = closer.prev
+			next := closer.next
+			if !closer.canOpen {
+				p.removeDelimiter(closer)
+			}
+			closer = next
+			continue
+		}
+
+		use := 1
+		if opener.count >= 2 && closer.count >= 2 {
+			use = 2
+		}
+		opener.count -= use
+		closer.count -= use
+		opener.node.Literal = opener.node.Literal[:opener.count]
+		closer.node.Literal = closer.node.Literal[:closer.count]
+
+		emph := &Node{Type: NodeEmph}
+		if use == 2 {
+			emph.Type = NodeStrong
+		}
+		for n := opener.node.next; n != nil && n != closer.node; {
+			next := n.next
+			emph.appendChild(n)
+			n = next
+		}
+		opener.node.insertAfter(emph)
+
+		for d := closer.prev; d != nil && d != opener; {
+			prev := d.prev
+			p.removeDelimiter(d)
+			d = prev
+		}
+
+		if opener.count == 0 {
+			opener.node.unlink()
+			p.removeDelimiter(opener)
+		}
+		if closer.count == 0 {
+			next := closer.next
+			closer.node.unlink()
+			p.removeDelimiter(closer)
+			closer = next
+		}
+	}
+
+	for p.delims != nil && p.delims != bottom {
+		p.removeDelimiter(p.delims)
+	}
+}
+
+// oddMatch implements the "rule of three": a run that can both open and close only
+// matches another run when the sum of their lengths is not a multiple of three.
+func oddMatch(opener, closer *delimiter) bool {
+	return (opener.canClose || closer.canOpen) &&
+		(opener.origCount+closer.origCount)%3 == 0 &&
+		!(opener.origCount%3 == 0 && closer.origCount%3 == 0)
+}
+
+func (p *inlineParser) removeDelimiter(d *delimiter) {
+	if d.prev != nil {
+		d.prev.next = d.next
+	}
+	if d.next != nil {
+		d.next.prev = d.prev
+	} else {
+		p.delims = d.prev
+	}
+	d.prev, d.next = nil, nil
+}
+
+func (p *inlineParser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func skipWhitespace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// unescapeString resolves backslash escapes and entity references in link
+// destinations, titles and code fence info strings.
+func unescapeString(s string) string {
+	if !strings.ContainsAny(s, "\\&") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			b.WriteByte(s[i+1])
+			i += 2
+		case s[i] == '&':
+			if m := entityPattern.FindString(s[i:]); m != "" {
+				b.WriteString(html.UnescapeString(m))
+				i += len(m)
+				continue
+			}
+			b.WriteByte('&')
+			i++
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
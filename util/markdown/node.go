@@ -0,0 +1,84 @@
+package markdown
+
+type NodeType int
+
+const (
+	NodeDocument NodeType = iota
+	NodeParagraph
+	NodeHeading
+	NodeThematicBreak
+	NodeCodeBlock
+	NodeBlockQuote
+	NodeList
+	NodeItem
+	NodeText
+	NodeCode
+	NodeEmph
+	NodeStrong
+	NodeLink
+	NodeImage
+	NodeSoftBreak
+	NodeHardBreak
+)
+
+// Node is an element of the parsed document. Literal holds the text of text, code and
+// code block nodes; Destination and Title are only set on links and images.
+type Node struct {
+	Type        NodeType
+	Literal     string
+	Level       int
+	Info        string
+	Ordered     bool
+	Start       int
+	Tight       bool
+	Destination string
+	Title       string
+
+	parent     *Node
+	firstChild *Node
+	lastChild  *Node
+	prev       *Node
+	next       *Node
+}
+
+func (n *Node) FirstChild() *Node { return n.firstChild }
+func (n *Node) Next() *Node       { return n.next }
+
+func (n *Node) appendChild(child *Node) {
+	child.unlink()
+	child.parent = n
+	if n.lastChild != nil {
+		n.lastChild.next = child
+		child.prev = n.lastChild
+	} else {
+		n.firstChild = child
+	}
+	n.lastChild = child
+}
+
+func (n *Node) insertAfter(sibling *Node) {
+	sibling.unlink()
+	sibling.parent = n.parent
+	sibling.prev = n
+	sibling.next = n.next
+	if n.next != nil {
+		n.next.prev = sibling
+	} else if n.parent != nil {
+		n.parent.lastChild = sibling
+	}
+	n.next = sibling
+}
+
+func (n *Node) unlink() {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else if n.parent != nil {
+		n.parent.firstChild = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else if n.parent != nil {
+		n.parent.lastChild = n.prev
+	}
+	n.parent, n.prev, n.next = nil, nil, nil
+}
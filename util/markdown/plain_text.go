@@ -0,0 +1,55 @@
+package markdown
+
+import "strings"
+
+// PlainText returns the readable text of a CommonMark source with all markup removed,
+// one line per block. It is what notifications and search payloads carry.
+func PlainText(source string) string {
+	var lines []string
+	collectText(Parse(source), &lines)
+	return strings.Join(lines, "\n")
+}
+
+// Excerpt returns the plain text of source collapsed to a single line and cut to at most
+// maxRunes characters, e.g. for a notification message.
+func Excerpt(source string, maxRunes int) string {
+	plain := strings.Join(strings.Fields(PlainText(source)), " ")
+
+	runes := []rune(plain)
+	if maxRunes <= 0 || len(runes) <= maxRunes {
+		return plain
+	}
+	return strings.TrimSpace(string(runes[:maxRunes-1])) + "…"
+}
+
+func collectText(n *Node, lines *[]string) {
+	for c := n.firstChild; c != nil; c = c.next {
+		switch c.Type {
+		case NodeParagraph, NodeHeading:
+			var b strings.Builder
+			writeInlineText(&b, c)
+			if line := strings.TrimSpace(b.String()); line != "" {
+				*lines = append(*lines, line)
+			}
+		case NodeCodeBlock:
+			if code := strings.TrimSpace(c.Literal); code != "" {
+				*lines = append(*lines, code)
+			}
+		case NodeBlockQuote, NodeList, NodeItem:
+			collectText(c, lines)
+		}
+	}
+}
+
+func writeInlineText(b *strings.Builder, n *Node) {
+	for c := n.firstChild; c != nil; c = c.next {
+		switch c.Type {
+		case NodeText, NodeCode:
+			b.WriteString(c.Literal)
+		case NodeSoftBreak, NodeHardBreak:
+			b.WriteString(" ")
+		default:
+			writeInlineText(b, c)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LinkRel is set on every rendered link so user content passes no ranking to the
+// sites it links to and cannot reach back into the opening page.
+const LinkRel = "nofollow noopener noreferrer ugc"
+
+var (
+	allowedLinkSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+	codeLanguage       = regexp.MustCompile(`^[A-Za-z0-9_+#.-]{1,32}$`)
+)
+
+// Render converts CommonMark source to HTML. Sanitisation happens by construction:
+// the renderer only emits the fixed set of elements below, every piece of text and
+// every attribute value is escaped, and raw HTML in the source is rendered as text.
+//
+// Allowed elements: p, h1-h6, hr, pre, code, blockquote, ul, ol, li, em, strong, br, a.
+// Allowed attributes: a[href, title, rel], ol[start], code[class="language-*"].
+func Render(source string) string {
+	var b strings.Builder
+	renderChildren(&b, Parse(source), false)
+	return b.String()
+}
+
+// SafeURL returns the destination a link may point to, or false when its scheme is not
+// allowed. Relative URLs are kept; javascript:, data: and friends are dropped.
+func SafeURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	for _, r := range raw {
+		if r < 0x20 || r == 0x7f {
+			return "", false
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme != "" && !allowedLinkSchemes[strings.ToLower(u.Scheme)] {
+		return "", false
+	}
+	// Without a scheme, a colon in the first path segment would be read as one by browsers.
+	if u.Scheme == "" && strings.Contains(strings.SplitN(u.Path, "/", 2)[0], ":") {
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+func renderChildren(b *strings.Builder, n *Node, tight bool) {
+	for c := n.firstChild; c != nil; c = c.next {
+		renderBlock(b, c, tight)
+	}
+}
+
+func renderBlock(b *strings.Builder, n *Node, tight bool) {
+	switch n.Type {
+	case NodeParagraph:
+		if tight {
+			renderInlines(b, n, false)
+			if n.next != nil {
+				b.WriteString("\n")
+			}
+			return
+		}
+		b.WriteString("<p>")
+		renderInlines(b, n, false)
+		b.WriteString("</p>\n")
+
+	case NodeHeading:
+		level := strconv.Itoa(n.Level)
+		b.WriteString("<h" + level + ">")
+		renderInlines(b, n, false)
+		b.WriteString("</h" + level + ">\n")
+
+	case NodeThematicBreak:
+		b.WriteString("<hr>\n")
+
+	case NodeCodeBlock:
+		b.WriteString("<pre><code")
+		if codeLanguage.MatchString(n.Info) {
+			b.WriteString(` class="language-` + html.EscapeString(n.Info) + `"`)
+		}
+		b.WriteString(">")
+		b.WriteString(html.EscapeString(n.Literal))
+		b.WriteString("</code></pre>\n")
+
+	case NodeBlockQuote:
+		b.WriteString("<blockquote>\n")
+		renderChildren(b, n, false)
+		b.WriteString("</blockquote>\n")
+
+	case NodeList:
+		tag := "ul"
+		if n.Ordered {
+			tag = "ol"
+		}
+		b.WriteString("<" + tag)
+		if n.Ordered && n.Start != 1 {
+			b.WriteString(` start="` + strconv.Itoa(n.Start) + `"`)
+		}
+		b.WriteString(">\n")
+		for item := n.firstChild; item != nil; item = item.next {
+			b.WriteString("<li>")
+			if item.firstChild != nil && (!n.Tight || item.firstChild.Type != NodeParagraph) {
+				b.WriteString("\n")
+			}
+			renderChildren(b, item, n.Tight)
+			b.WriteString("</li>\n")
+		}
+		b.WriteString("</" + tag + ">\n")
+	}
+}
+
+func renderInlines(b *strings.Builder, n *Node, inLink bool) {
+	for c := n.firstChild; c != nil; c = c.next {
+		switch c.Type {
+		case NodeText:
+			b.WriteString(html.EscapeString(c.Literal))
+
+		case NodeCode:
+			b.WriteString("<code>" + html.EscapeString(c.Literal) + "</code>")
+
+		case NodeEmph:
+			b.WriteString("<em>")
+			renderInlines(b, c, inLink)
+			b.WriteString("</em>")
+
+		case NodeStrong:
+			b.WriteString("<strong>")
+			renderInlines(b, c, inLink)
+			b.WriteString("</strong>")
+
+		case NodeSoftBreak:
+			b.WriteString("\n")
+
+		case NodeHardBreak:
+			b.WriteString("<br>\n")
+
+		// Images are not embedded; they become a link to the image labelled with its alt text.
+		case NodeLink, NodeImage:
+			href, ok := SafeURL(c.Destination)
+			if !ok || inLink {
+				renderInlines(b, c, inLink)
+				continue
+			}
+			b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="` + LinkRel + `"`)
+			if c.Title != "" {
+				b.WriteString(` title="` + html.EscapeString(c.Title) + `"`)
+			}
+			b.WriteString(">")
+			renderInlines(b, c, true)
+			b.WriteString("</a>")
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	outputTag     = regexp.MustCompile(`^<(/?)([a-z][a-z0-9]*)((?:\s+[a-z-]+="[^"<>]*")*)\s*>`)
+	tagAttribute  = regexp.MustCompile(`([a-z-]+)="([^"]*)"`)
+	urlScheme     = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*):`)
+	urlWhitespace = strings.NewReplacer("\t", "", "\n", "", "\r", "")
+)
+
+var allowedOutputAttributes = map[string]map[string]bool{
+	"p": {}, "h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"hr": {}, "pre": {}, "blockquote": {}, "ul": {}, "li": {},
+	"em": {}, "strong": {}, "br": {},
+	"code": {"class": true},
+	"ol":   {"start": true},
+	"a":    {"href": true, "title": true, "rel": true},
+}
+
+// xssSeeds are well-known ways of smuggling script through a Markdown renderer.
+var xssSeeds = []string{
+	`<script>alert(1)</script>`,
+	`<img src=x onerror=alert(1)>`,
+	`<a href="javascript:alert(1)">x</a>`,
+	`<svg/onload=alert(1)>`,
+	"<iframe src=\"javascript:alert(1)\"></iframe>",
+	`[x](javascript:alert(1))`,
+	`[x](JaVaScRiPt:alert(1))`,
+	`[x]( javascript:alert(1))`,
+	`[x](<javascript:alert(1)>)`,
+	`[x](&#106;avascript:alert(1))`,
+	`[x](java&#x09;script:alert(1))`,
+	`[x](java%0ascript:alert(1))`,
+	`[x](vbscript:msgbox(1))`,
+	`[x](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)`,
+	`![x](javascript:alert(1))`,
+	`<javascript:alert(1)>`,
+	`[x](https://example.com "\" onmouseover=\"alert(1))`,
+	`[x](https://example.com" onmouseover="alert(1))`,
+	`[x](https://example.com 'a" onclick="alert(1)')`,
+	"[x][ref]\n\n[ref]: javascript:alert(1)",
+	"```\" onclick=\"alert(1)\ncode\n```",
+	"```js><script>alert(1)</script>\ncode\n```",
+	"* <b onmouseover=alert(1)>x</b>",
+	"> <style>*{}</style>",
+	"`<script>alert(1)</script>`",
+	`**<script>**alert(1)</script>`,
+	`[<img src=x onerror=alert(1)>](https://example.com)`,
+	`[x](https://example.com)<script>alert(1)</script>`,
+	"[x](foo:bar)",
+	"[x](mailto:someone@example.com)",
+}
+
+func FuzzRender(f *testing.F) {
+	for _, seed := range xssSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, source string) {
+		out := Render(source)
+		if err := checkRenderedHTML(out); err != "" {
+			t.Fatalf("Render(%q) = %q: %s", source, out, err)
+		}
+	})
+}
+
+// checkRenderedHTML returns why out falls outside the Render allowlist, or "" when it does not.
+// Every '<' in the output must open or close an allowed tag because all text is escaped.
+func checkRenderedHTML(out string) string {
+	for i := strings.IndexByte(out, '<'); i >= 0; i = strings.IndexByte(out, '<') {
+		out = out[i:]
+		m := outputTag.FindStringSubmatch(out)
+		if m == nil {
+			return "unescaped '<' outside an allowed tag"
+		}
+		out = out[len(m[0]):]
+
+		closing, tag, attrs := m[1] == "/", m[2], m[3]
+		allowed, ok := allowedOutputAttributes[tag]
+		if !ok {
+			return "disallowed tag <" + tag + ">"
+		}
+		if closing && attrs != "" {
+			return "attributes on closing tag </" + tag + ">"
+		}
+
+		for _, a := range tagAttribute.FindAllStringSubmatch(attrs, -1) {
+			name, value := a[1], html.UnescapeString(a[2])
+			if strings.HasPrefix(name, "on") {
+				return "event handler attribute " + name
+			}
+			if !allowed[name] {
+				return "disallowed attribute " + name + " on <" + tag + ">"
+			}
+			if tag == "code" && !strings.HasPrefix(value, "language-") {
+				return "code class " + value
+			}
+			if tag == "a" && name == "href" {
+				if err := checkHref(value); err != "" {
+					return err
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// checkHref reads href the way a browser would: leading whitespace and control
+// characters are ignored and tabs or newlines inside the scheme do not break it up.
+func checkHref(href string) string {
+	href = urlWhitespace.Replace(strings.TrimLeft(href, "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x0b\x0c\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f "))
+	if m := urlScheme.FindStringSubmatch(href); m != nil && !allowedLinkSchemes[strings.ToLower(m[1])] {
+		return "link scheme " + m[1]
+	}
+	return ""
+}
+
+func TestRenderDropsUnsafeLinks(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`[x](javascript:alert(1))`, "<p>x</p>\n"},
+		{`[x](data:text/html,hi)`, "<p>x</p>\n"},
+		{`<script>alert(1)</script>`, "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{`[x](https://example.com)`, `<p><a href="https://example.com" rel="` + LinkRel + `">x</a></p>` + "\n"},
+		{`[x](mailto:a@example.com)`, `<p><a href="mailto:a@example.com" rel="` + LinkRel + `">x</a></p>` + "\n"},
+	}
+
+	for _, tt := range tests {
+		if got := Render(tt.source); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const MaxHashtagLength = 50
+
+// A hashtag must start the text or follow a character that cannot be part of a word,
+// so "C#" or "a#b" are not picked up.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#/])#([\p{L}\p{N}_]+)`)
+
+// ExtractHashtags returns the normalised, de-duplicated hashtags found in texts in order of appearance.
+func ExtractHashtags(texts ...string) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+
+	for _, t := range texts {
+		for _, match := range hashtagPattern.FindAllStringSubmatch(t, -1) {
+			tag := NormalizeHashtag(match[1])
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// NormalizeHashtag lowercases a tag and drops the leading '#'. Tags that are too long
+// or made only of digits (e.g. "#1") are rejected with an empty string.
+func NormalizeHashtag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" || len([]rune(tag)) > MaxHashtagLength {
+		return ""
+	}
+
+	for _, r := range tag {
+		if !unicode.IsDigit(r) {
+			return tag
+		}
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+)
+
+const MaxMentionsPerText = 10
+
+// A mention must start the text or follow a non-word character, so e-mail addresses are skipped.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@.])@([A-Za-z0-9_.]{1,30})`)
+
+// ExtractMentions returns the lowercased, de-duplicated usernames mentioned in texts,
+// capped at MaxMentionsPerText so a single post cannot notify half the platform.
+func ExtractMentions(texts ...string) []string {
+	seen := make(map[string]bool)
+	usernames := []string{}
+
+	for _, t := range texts {
+		for _, match := range mentionPattern.FindAllStringSubmatch(t, -1) {
+			username := strings.ToLower(strings.TrimRight(match[1], "."))
+			if username == "" || seen[username] {
+				continue
+			}
+			seen[username] = true
+			usernames = append(usernames, username)
+			if len(usernames) == MaxMentionsPerText {
+				return usernames
+			}
+		}
+	}
+
+	return usernames
+}
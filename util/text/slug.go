@@ -0,0 +1,82 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const MaxSlugLength = 64
+
+// slugTransliterations covers the letters that do not decompose into an ASCII base letter
+// and a combining mark.
+var slugTransliterations = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'ø': "o",
+	'đ': "d",
+	'ð': "d",
+	'ł': "l",
+	'þ': "th",
+	'ı': "i",
+}
+
+// Slugify turns a name into a URL slug: lowercase letters and digits separated by single
+// hyphens, e.g. "Universitas Gadjah Mada (UGM)" becomes "universitas-gadjah-mada-ugm".
+// Accented Latin letters are transliterated ("Señor Café" becomes "senor-cafe"), apostrophes
+// are dropped and letters of other scripts are kept as they are, marks included. Slugs are
+// cut to MaxSlugLength runes; an empty result means name had nothing to build one from.
+func Slugify(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	keepMarks := false
+
+	for _, r := range norm.NFKD.String(strings.ToLower(name)) {
+		// Accents on Latin letters are dropped, but in other scripts the marks are part of
+		// the letter, like the dakuten that makes フ into ブ, so those stay.
+		if unicode.Is(unicode.M, r) {
+			if keepMarks {
+				b.WriteRune(r)
+			}
+			continue
+		}
+		if r == '\'' || r == '’' {
+			continue
+		}
+
+		letters := string(r)
+		if t, ok := slugTransliterations[r]; ok {
+			letters = t
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingHyphen = b.Len() > 0
+			keepMarks = false
+			continue
+		}
+
+		if pendingHyphen {
+			b.WriteByte('-')
+			pendingHyphen = false
+		}
+		b.WriteString(letters)
+		keepMarks = unicode.IsLetter(r) && !unicode.Is(unicode.Latin, r)
+	}
+
+	return truncateSlug(norm.NFC.String(b.String()))
+}
+
+// truncateSlug cuts slug to MaxSlugLength runes without separating a letter from its marks
+// or leaving a trailing hyphen.
+func truncateSlug(slug string) string {
+	runes := []rune(slug)
+	if len(runes) <= MaxSlugLength {
+		return slug
+	}
+
+	cut := MaxSlugLength
+	for cut > 0 && unicode.Is(unicode.M, runes[cut]) {
+		cut--
+	}
+	return strings.TrimRight(string(runes[:cut]), "-")
+}
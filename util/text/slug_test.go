@@ -0,0 +1,38 @@
+package text
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Universitas Gadjah Mada (UGM)", "universitas-gadjah-mada-ugm"},
+		{"Institut Teknologi Sepuluh Nopember", "institut-teknologi-sepuluh-nopember"},
+		{"Komunitas Pecinta Kopi & Teh", "komunitas-pecinta-kopi-teh"},
+		{"Jum'at Berkah", "jumat-berkah"},
+		{"Señor Café", "senor-cafe"},
+		{"Université de Montréal", "universite-de-montreal"},
+		{"Straße", "strasse"},
+		{"Ærø Øst", "aero-ost"},
+		{"Łódź", "lodz"},
+		{"ＵＧＭ ２０２４", "ugm-2024"},
+		{"日本語 クラブ", "日本語-クラブ"},
+		{"東京大学 サークル", "東京大学-サークル"},
+		{"北京大学", "北京大学"},
+		{"서울대학교 동아리", "서울대학교-동아리"},
+		{"हिन्दी क्लब", "हिन्दी-क्लब"},
+		{"  --  ", ""},
+		{strings.Repeat("a", MaxSlugLength+10), strings.Repeat("a", MaxSlugLength)},
+		{strings.Repeat("a", MaxSlugLength) + " b", strings.Repeat("a", MaxSlugLength)},
+	}
+
+	for _, tt := range tests {
+		if got := Slugify(tt.name); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package text
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// ExtractURLs returns the de-duplicated http(s) URLs found in texts in order of appearance.
+// Trailing punctuation that usually ends a sentence rather than the URL is dropped.
+func ExtractURLs(texts ...string) []string {
+	seen := make(map[string]bool)
+	urls := []string{}
+
+	for _, t := range texts {
+		for _, match := range urlPattern.FindAllString(t, -1) {
+			match = strings.TrimRight(match, ".,;:!?)]}")
+
+			parsed, err := url.Parse(match)
+			if err != nil || parsed.Host == "" || seen[match] {
+				continue
+			}
+			seen[match] = true
+			urls = append(urls, match)
+		}
+	}
+
+	return urls
+}
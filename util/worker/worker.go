@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a background task that RunPeriodically calls every Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// RunPeriodically calls fn every interval until ctx is cancelled.
+func RunPeriodically(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Worker %s started with interval %s", name, interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Worker %s stopped", name)
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Printf("Worker %s failed: %v", name, err)
+			}
+		}
+	}
+}